@@ -2,7 +2,9 @@ package global
 
 import (
 	"base_go_be/pkg/logger"
+	"base_go_be/pkg/mailer"
 	"base_go_be/pkg/setting"
+	"base_go_be/pkg/storage"
 
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -15,8 +17,10 @@ var (
 	Mysql     *gorm.DB
 	Postgres  *gorm.DB
 	WsManager setting.WebSocketManager
+	Mailer    mailer.Mailer
+	Storage   storage.Storage
 )
 
 /*
-Config: Redis, Mysql, Postgres, WebSocket Manager, ...
+Config: Redis, Mysql, Postgres, WebSocket Manager, Mailer, Storage, ...
 */
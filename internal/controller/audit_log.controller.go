@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/service"
+	"base_go_be/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogController struct {
+	auditLogService service.IAuditLogService
+}
+
+func NewAuditLogController(auditLogService service.IAuditLogService) *AuditLogController {
+	return &AuditLogController{
+		auditLogService: auditLogService,
+	}
+}
+
+// GetListAuditLog godoc
+// @Summary Get the audit log (Admin only)
+// @Description Returns recorded sensitive actions, most recent first. Requires the audit_log:read permission
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param skip query int false "Skip" default(0)
+// @Param limit query int false "Limit" default(20)
+// @Param actor_user_id query string false "Public ID of the user who performed the action"
+// @Param target_user_id query string false "Public ID of the user the action was performed on"
+// @Param action query string false "Action, e.g. impersonation.start"
+// @Success 200 {object} response.Response{data=dto.AuditLogListResponseDto} "Paginated audit log"
+// @Failure 400 {object} response.Response "Invalid query parameters"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Router /admin/audit_logs [get]
+func (ac *AuditLogController) GetListAuditLog(c *gin.Context) {
+	var req dto.AuditLogListRequestDto
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Logger.Error("Failed to bind query parameters: " + err.Error())
+		response.ErrorResponse(c, 400, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	result := ac.auditLogService.GetListAuditLog(req)
+	response.HandleServiceResult(c, result)
+}
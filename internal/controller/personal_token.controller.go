@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/internal/service"
+	"base_go_be/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PersonalTokenController struct {
+	personalTokenService service.IPersonalTokenService
+}
+
+func NewPersonalTokenController(personalTokenService service.IPersonalTokenService) *PersonalTokenController {
+	return &PersonalTokenController{
+		personalTokenService: personalTokenService,
+	}
+}
+
+// GetListPersonalToken godoc
+// @Summary List my personal access tokens
+// @Description List the personal access tokens of the current user. The tokens themselves are never shown again
+// @Tags personal token
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]dto.PersonalTokenResponseDto} "Personal access tokens"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /user/tokens [get]
+func (pc *PersonalTokenController) GetListPersonalToken(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	result := pc.personalTokenService.GetListPersonalToken(userID.(uint))
+	response.HandleServiceResult(c, result)
+}
+
+// CreatePersonalToken godoc
+// @Summary Create a personal access token
+// @Description Create a long-lived token for scripts, sent as "Authorization: Bearer kado_pat_...". It only grants the scopes listed, which must be permissions of the user's role. The token is only shown in this response
+// @Tags personal token
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body dto.PersonalTokenRequestDto true "Token Name, Scopes and Expiry"
+// @Success 200 {object} response.Response{data=dto.PersonalTokenCreatedResponseDto} "Personal access token created"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 422 {object} response.Response "Scope not granted to the user"
+// @Router /user/tokens [post]
+func (pc *PersonalTokenController) CreatePersonalToken(c *gin.Context) {
+	var tokenRequest dto.PersonalTokenRequestDto
+	if err := c.ShouldBindJSON(&tokenRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := pc.personalTokenService.CreatePersonalToken(currentActor(c), tokenRequest)
+	response.HandleServiceResult(c, result)
+}
+
+// DeletePersonalToken godoc
+// @Summary Revoke a personal access token
+// @Description Delete one of the current user's personal access tokens, it stops working immediately
+// @Tags personal token
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Token ID"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Personal access token revoked"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Personal access token not found"
+// @Failure 422 {object} response.Response "Invalid token ID"
+// @Router /user/tokens/{id} [delete]
+func (pc *PersonalTokenController) DeletePersonalToken(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	idParam := c.Param("id")
+	idUint64, err := strconv.ParseUint(idParam, 10, 0)
+	id := uint(idUint64)
+	if err != nil {
+		response.DataDetailResponse(c, 422, response.ErrCodeInvalidParams, nil)
+		return
+	}
+
+	result := pc.personalTokenService.DeletePersonalToken(userID.(uint), id)
+	response.HandleServiceResult(c, result)
+}
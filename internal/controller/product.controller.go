@@ -4,7 +4,6 @@ import (
 	"base_go_be/internal/dto"
 	"base_go_be/internal/service"
 	"base_go_be/pkg/response"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,18 +25,15 @@ func NewProductController(productService service.IProductService) *ProductContro
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Param id path int true "Product ID"
+// @Param id path string true "Product public ID"
 // @Success 200 {object} response.Response{data=dto.ProductDetailDto}
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 404 {object} response.Response "Product not found"
 // @Failure 422 {object} response.Response "Invalid product ID"
 // @Router /product/detail/{id} [get]
 func (pc *ProductController) GetProductByID(c *gin.Context) {
-	idParam := c.Param("id")
-	idUint64, err := strconv.ParseUint(idParam, 10, 0)
-	id := uint(idUint64)
-	if err != nil {
-		response.DataDetailResponse(c, 422, response.ErrCodeInvalidParams, nil)
+	id, ok := publicIDParam(c)
+	if !ok {
 		return
 	}
 
@@ -71,13 +67,13 @@ func (pc *ProductController) GetListProduct(c *gin.Context) {
 
 // CreateProduct godoc
 // @Summary Create a new product
-// @Description Create a new product with name, description, and user ID
+// @Description Create a new product with name and description, owned by the current user
 // @Tags product
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param product body dto.ProductRequestDto true "Product Request"
-// @Success 200 {object} response.Response{data=map[string]uint}
+// @Success 200 {object} response.Response{data=map[string]string}
 // @Failure 400 {object} response.Response "Invalid request payload"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 405 {object} response.Response "Method not allowed"
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/service"
+	"base_go_be/pkg/response"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is the room left in an avatar upload for the multipart framing
+const multipartOverhead = 16 * 1024
+
+type ProfileController struct {
+	profileService service.IProfileService
+}
+
+func NewProfileController(profileService service.IProfileService) *ProfileController {
+	return &ProfileController{
+		profileService: profileService,
+	}
+}
+
+// GetProfile godoc
+// @Summary Get my profile
+// @Description Get the profile of the current user, private fields such as locale, timezone and notification preferences included
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=dto.ProfileResponseDto} "Profile"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /user/me/profile [get]
+func (pc *ProfileController) GetProfile(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	result := pc.profileService.GetProfile(userID.(uint))
+	response.HandleServiceResult(c, result)
+}
+
+// UpdateProfile godoc
+// @Summary Update my profile
+// @Description Change the fields sent and keep the others. An empty string clears a text field. The locale is a BCP 47 tag and the timezone an IANA name
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body dto.ProfileUpdateRequestDto true "Profile fields to change"
+// @Success 200 {object} response.Response{data=dto.ProfileResponseDto} "Profile updated"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 422 {object} response.Response "Invalid locale or timezone"
+// @Router /user/me/profile [patch]
+func (pc *ProfileController) UpdateProfile(c *gin.Context) {
+	var profileRequest dto.ProfileUpdateRequestDto
+	if err := c.ShouldBindJSON(&profileRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	actor := currentActor(c)
+	if actor == nil {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	result := pc.profileService.UpdateProfile(actor, profileRequest)
+	response.HandleServiceResult(c, result)
+}
+
+// UploadAvatar godoc
+// @Summary Upload my avatar
+// @Description Replace the avatar with a JPEG, PNG or GIF of at most STORAGE_AVATAR_MAX_SIZE KiB. It is cropped to a square and resized to 256x256. Every upload gets a new URL
+// @Tags profile
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param avatar formData file true "Avatar image"
+// @Success 200 {object} response.Response{data=dto.ProfileResponseDto} "Avatar updated"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 413 {object} response.Response "Image too large"
+// @Failure 422 {object} response.Response "Not a supported image"
+// @Router /user/me/avatar [put]
+func (pc *ProfileController) UploadAvatar(c *gin.Context) {
+	maxSize := int64(global.Config.Storage.AvatarMaxSize) * 1024
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.ErrorResponse(c, 413, "Image too large")
+			return
+		}
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+	if header.Size > maxSize {
+		response.ErrorResponse(c, 413, "Image too large")
+		return
+	}
+
+	actor := currentActor(c)
+	if actor == nil {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+	defer file.Close()
+
+	result := pc.profileService.UploadAvatar(actor, file)
+	response.HandleServiceResult(c, result)
+}
+
+// DeleteAvatar godoc
+// @Summary Remove my avatar
+// @Description Remove the avatar of the current user
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=dto.ProfileResponseDto} "Avatar removed"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /user/me/avatar [delete]
+func (pc *ProfileController) DeleteAvatar(c *gin.Context) {
+	actor := currentActor(c)
+	if actor == nil {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	result := pc.profileService.DeleteAvatar(actor)
+	response.HandleServiceResult(c, result)
+}
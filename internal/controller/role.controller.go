@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/internal/service"
+	"base_go_be/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleController struct {
+	roleService service.IRoleService
+}
+
+func NewRoleController(roleService service.IRoleService) *RoleController {
+	return &RoleController{
+		roleService: roleService,
+	}
+}
+
+// GetListRole godoc
+// @Summary Get all roles (Admin only)
+// @Description Returns every role with its permissions
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]dto.RoleResponseDto} "List of roles"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Router /admin/list_role [get]
+func (rc *RoleController) GetListRole(c *gin.Context) {
+	result := rc.roleService.GetListRole()
+	response.HandleServiceResult(c, result)
+}
+
+// GetListPermission godoc
+// @Summary Get all permissions (Admin only)
+// @Description Returns every permission that can be granted to a role
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]dto.PermissionResponseDto} "List of permissions"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Router /admin/list_permission [get]
+func (rc *RoleController) GetListPermission(c *gin.Context) {
+	result := rc.roleService.GetListPermission()
+	response.HandleServiceResult(c, result)
+}
+
+// CreateRole godoc
+// @Summary Create a role (Admin only)
+// @Description Creates a role with the given permissions
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param role body dto.RoleRequestDto true "Role Information"
+// @Success 200 {object} response.Response{data=dto.RoleResponseDto} "Role created"
+// @Failure 400 {object} response.Response "Invalid request payload"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 409 {object} response.Response "Role already exists"
+// @Failure 422 {object} response.Response "Permission not found"
+// @Router /admin/create_role [post]
+func (rc *RoleController) CreateRole(c *gin.Context) {
+	var roleRequest dto.RoleRequestDto
+	if err := c.ShouldBindJSON(&roleRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request payload")
+		return
+	}
+
+	result := rc.roleService.CreateRole(roleRequest)
+	response.HandleServiceResult(c, result)
+}
+
+// UpdateRolePermissions godoc
+// @Summary Update role permissions (Admin only)
+// @Description Replaces the permissions granted to a role
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "Role Name"
+// @Param permissions body dto.RolePermissionsRequestDto true "Permissions"
+// @Success 200 {object} response.Response{data=dto.RoleResponseDto} "Role updated"
+// @Failure 400 {object} response.Response "Invalid request payload"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Role not found"
+// @Failure 422 {object} response.Response "Permission not found"
+// @Router /admin/update_role_permissions/{name} [put]
+func (rc *RoleController) UpdateRolePermissions(c *gin.Context) {
+	var permissionsRequest dto.RolePermissionsRequestDto
+	if err := c.ShouldBindJSON(&permissionsRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request payload")
+		return
+	}
+
+	result := rc.roleService.UpdateRolePermissions(c.Param("name"), permissionsRequest)
+	response.HandleServiceResult(c, result)
+}
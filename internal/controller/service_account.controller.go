@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/internal/service"
+	"base_go_be/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ServiceAccountController struct {
+	serviceAccountService service.IServiceAccountService
+}
+
+func NewServiceAccountController(serviceAccountService service.IServiceAccountService) *ServiceAccountController {
+	return &ServiceAccountController{
+		serviceAccountService: serviceAccountService,
+	}
+}
+
+// GetListServiceAccount godoc
+// @Summary Get all service accounts (Admin only)
+// @Description Returns every service account with its API keys and their request counts. Key secrets are never shown again
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]dto.ServiceAccountResponseDto} "List of service accounts"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Router /admin/service_accounts [get]
+func (sc *ServiceAccountController) GetListServiceAccount(c *gin.Context) {
+	result := sc.serviceAccountService.GetListServiceAccount()
+	response.HandleServiceResult(c, result)
+}
+
+// CreateServiceAccount godoc
+// @Summary Create a service account (Admin only)
+// @Description Creates a non-human principal for a partner backend. It gets the permissions of its role, which can't exceed the admin's, and what it creates belongs to the admin
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body dto.ServiceAccountRequestDto true "Service Account Information"
+// @Success 200 {object} response.Response{data=dto.ServiceAccountResponseDto} "Service account created"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Role not found"
+// @Failure 409 {object} response.Response "Service account already exists"
+// @Router /admin/service_accounts [post]
+func (sc *ServiceAccountController) CreateServiceAccount(c *gin.Context) {
+	var accountRequest dto.ServiceAccountRequestDto
+	if err := c.ShouldBindJSON(&accountRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := sc.serviceAccountService.CreateServiceAccount(currentActor(c), accountRequest)
+	response.HandleServiceResult(c, result)
+}
+
+// UpdateServiceAccount godoc
+// @Summary Update a service account (Admin only)
+// @Description Changes the description or role of a service account, or deactivates it so its keys stop working
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Service Account ID"
+// @Param body body dto.ServiceAccountUpdateRequestDto true "Fields to change"
+// @Success 200 {object} response.Response{data=dto.ServiceAccountResponseDto} "Service account updated"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Service account or role not found"
+// @Failure 422 {object} response.Response "Invalid service account ID"
+// @Router /admin/service_accounts/{id} [put]
+func (sc *ServiceAccountController) UpdateServiceAccount(c *gin.Context) {
+	idParam := c.Param("id")
+	idUint64, err := strconv.ParseUint(idParam, 10, 0)
+	id := uint(idUint64)
+	if err != nil {
+		response.DataDetailResponse(c, 422, response.ErrCodeInvalidParams, nil)
+		return
+	}
+
+	var accountRequest dto.ServiceAccountUpdateRequestDto
+	if err := c.ShouldBindJSON(&accountRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := sc.serviceAccountService.UpdateServiceAccount(currentActor(c), id, accountRequest)
+	response.HandleServiceResult(c, result)
+}
+
+// CreateServiceAccountKey godoc
+// @Summary Create an API key for a service account (Admin only)
+// @Description Creates a key ID and secret used to sign requests with HMAC-SHA256 (X-Kado-Key-Id, X-Kado-Timestamp and X-Kado-Signature headers). The secret is only shown in this response
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Service Account ID"
+// @Success 200 {object} response.Response{data=dto.ServiceAccountKeyCreatedResponseDto} "API key created"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Service account not found"
+// @Failure 422 {object} response.Response "Invalid service account ID"
+// @Router /admin/service_accounts/{id}/keys [post]
+func (sc *ServiceAccountController) CreateServiceAccountKey(c *gin.Context) {
+	idParam := c.Param("id")
+	idUint64, err := strconv.ParseUint(idParam, 10, 0)
+	id := uint(idUint64)
+	if err != nil {
+		response.DataDetailResponse(c, 422, response.ErrCodeInvalidParams, nil)
+		return
+	}
+
+	result := sc.serviceAccountService.CreateServiceAccountKey(id)
+	response.HandleServiceResult(c, result)
+}
+
+// DeleteServiceAccountKey godoc
+// @Summary Revoke an API key of a service account (Admin only)
+// @Description Deletes the key, requests signed with it are refused immediately
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Service Account ID"
+// @Param key_id path string true "Key ID"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "API key revoked"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "API key not found"
+// @Failure 422 {object} response.Response "Invalid service account ID"
+// @Router /admin/service_accounts/{id}/keys/{key_id} [delete]
+func (sc *ServiceAccountController) DeleteServiceAccountKey(c *gin.Context) {
+	idParam := c.Param("id")
+	idUint64, err := strconv.ParseUint(idParam, 10, 0)
+	id := uint(idUint64)
+	if err != nil {
+		response.DataDetailResponse(c, 422, response.ErrCodeInvalidParams, nil)
+		return
+	}
+
+	result := sc.serviceAccountService.DeleteServiceAccountKey(id, c.Param("key_id"))
+	response.HandleServiceResult(c, result)
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/pkg/jwt"
+	"base_go_be/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChangePassword godoc
+// @Summary Change my password
+// @Description Set a new password after confirming the current one. The new password must meet the password policy. Every other session is logged out and the user is notified by email. Not allowed while impersonating
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body dto.ChangePasswordRequestDto true "Current and New Password"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Password changed"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Unauthorized or wrong password"
+// @Failure 403 {object} response.Response "Not allowed while impersonating"
+// @Failure 422 {object} response.Response{data=dto.PasswordPolicyErrorDto} "Password does not meet the policy"
+// @Failure 423 {object} response.Response "Account locked after too many wrong passwords"
+// @Router /user/me/password [post]
+func (uc *UserController) ChangePassword(c *gin.Context) {
+	var changeRequest dto.ChangePasswordRequestDto
+	if err := c.ShouldBindJSON(&changeRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	current := claims.(*jwt.JWTClaims)
+	result := uc.userService.ChangePassword(currentActor(c).UserID, current.SessionID, changeRequest, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// RequestEmailChange godoc
+// @Summary Change my email
+// @Description Email a confirmation link to the new address and a notice to the current one. The account keeps its current email until the link is opened. Needs the current password, and isn't allowed while impersonating
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body dto.ChangeEmailRequestDto true "New Email and Current Password"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Confirmation link sent"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Unauthorized or wrong password"
+// @Failure 403 {object} response.Response "Not allowed while impersonating"
+// @Failure 409 {object} response.Response "Email already used"
+// @Failure 422 {object} response.Response "Email is the current one"
+// @Failure 423 {object} response.Response "Account locked after too many wrong passwords"
+// @Failure 429 {object} response.Response "Too many requests"
+// @Router /user/me/email [post]
+func (uc *UserController) RequestEmailChange(c *gin.Context) {
+	var changeRequest dto.ChangeEmailRequestDto
+	if err := c.ShouldBindJSON(&changeRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	result := uc.userService.RequestEmailChange(userID.(uint), changeRequest, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm an email change
+// @Description Move the account to the new email with the token from the confirmation email. The new email counts as verified
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body dto.ConfirmEmailChangeRequestDto true "Confirmation Token"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Email changed"
+// @Failure 400 {object} response.Response "Invalid request data or invalid token"
+// @Failure 409 {object} response.Response "Email taken in the meantime"
+// @Router /user/confirm_email_change [post]
+func (uc *UserController) ConfirmEmailChange(c *gin.Context) {
+	var confirmRequest dto.ConfirmEmailChangeRequestDto
+	if err := c.ShouldBindJSON(&confirmRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.ConfirmEmailChange(confirmRequest.Token)
+	response.HandleServiceResult(c, result)
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUserDetail godoc
+// @Summary Get the full detail of a user (Admin only)
+// @Description Everything about a user an admin may need, account status and 2FA included. Users whose role has permissions the admin lacks can't be viewed. Recorded in the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User public ID"
+// @Success 200 {object} response.Response{data=dto.AdminUserDetailDto} "User detail"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 422 {object} response.Response "Invalid user ID"
+// @Router /admin/users/{id} [get]
+func (uc *UserController) GetUserDetail(c *gin.Context) {
+	id, ok := publicIDParam(c)
+	if !ok {
+		return
+	}
+
+	result := uc.userService.GetUserDetail(currentActor(c), id, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// DeactivateUser godoc
+// @Summary Deactivate a user (Admin only)
+// @Description Disable the account and end all its sessions, until it is reactivated. Admins can't deactivate themselves or users whose role has permissions they lack. Recorded in the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User public ID"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "User deactivated"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 422 {object} response.Response "Invalid user ID"
+// @Router /admin/users/{id}/deactivate [post]
+func (uc *UserController) DeactivateUser(c *gin.Context) {
+	id, ok := publicIDParam(c)
+	if !ok {
+		return
+	}
+
+	result := uc.userService.DeactivateUser(currentActor(c), id, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// ReactivateUser godoc
+// @Summary Reactivate a user (Admin only)
+// @Description Enable a deactivated account again. Recorded in the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User public ID"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "User reactivated"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 422 {object} response.Response "Invalid user ID"
+// @Router /admin/users/{id}/reactivate [post]
+func (uc *UserController) ReactivateUser(c *gin.Context) {
+	id, ok := publicIDParam(c)
+	if !ok {
+		return
+	}
+
+	result := uc.userService.ReactivateUser(currentActor(c), id, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// ChangeUserRole godoc
+// @Summary Change the role of a user (Admin only)
+// @Description Give the user another role and end their sessions so new tokens carry it. Neither the current nor the new role can have permissions the admin lacks, and admins can't change their own role. Recorded in the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User public ID"
+// @Param body body dto.ChangeRoleRequestDto true "New role"
+// @Success 200 {object} response.Response{data=dto.UserResponseDto} "Role changed"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 422 {object} response.Response "Invalid user ID or role not found"
+// @Router /admin/users/{id}/role [put]
+func (uc *UserController) ChangeUserRole(c *gin.Context) {
+	id, ok := publicIDParam(c)
+	if !ok {
+		return
+	}
+
+	var roleRequest dto.ChangeRoleRequestDto
+	if err := c.ShouldBindJSON(&roleRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.ChangeUserRole(currentActor(c), id, roleRequest.Role, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// ForcePasswordReset godoc
+// @Summary Force a password reset (Admin only)
+// @Description Clear the password of the user, end their sessions and email them a reset link. Recorded in the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User public ID"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Password reset forced"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 422 {object} response.Response "Invalid user ID"
+// @Router /admin/users/{id}/password_reset [post]
+func (uc *UserController) ForcePasswordReset(c *gin.Context) {
+	id, ok := publicIDParam(c)
+	if !ok {
+		return
+	}
+
+	result := uc.userService.ForcePasswordReset(currentActor(c), id, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// DeleteUser godoc
+// @Summary Delete a user (Admin only)
+// @Description Soft delete the user: they can't log in, their sessions end and they disappear from lookups. They can be restored until purged after AUTH_DELETED_USER_RETENTION days. Admins can't delete themselves. Recorded in the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User public ID"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "User deleted"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 422 {object} response.Response "Invalid user ID"
+// @Router /admin/users/{id} [delete]
+func (uc *UserController) DeleteUser(c *gin.Context) {
+	id, ok := publicIDParam(c)
+	if !ok {
+		return
+	}
+
+	result := uc.userService.DeleteUser(currentActor(c), id, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// RestoreUser godoc
+// @Summary Restore a deleted user (Admin only)
+// @Description Bring back a deleted user that wasn't purged yet. Refused if their email or username was taken again. Recorded in the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User public ID"
+// @Success 200 {object} response.Response{data=dto.UserResponseDto} "User restored"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Deleted user not found"
+// @Failure 409 {object} response.Response "Email or username taken again"
+// @Failure 422 {object} response.Response "Invalid user ID"
+// @Router /admin/users/{id}/restore [post]
+func (uc *UserController) RestoreUser(c *gin.Context) {
+	id, ok := publicIDParam(c)
+	if !ok {
+		return
+	}
+
+	result := uc.userService.RestoreUser(currentActor(c), id, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
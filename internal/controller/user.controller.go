@@ -3,9 +3,10 @@ package controller
 import (
 	"base_go_be/global"
 	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
 	"base_go_be/internal/service"
+	"base_go_be/pkg/jwt"
 	"base_go_be/pkg/response"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,14 +23,16 @@ func NewUserController(userService service.IUserService) *UserController {
 
 // Register godoc
 // @Summary Register a new user
-// @Description Register a new user and return JWT token
+// @Description Register a new user and return JWT token. Self-registered users always get the USER role. A verification link is emailed; when verification is required, no token is returned and data is a dto.MessageResponseDto instead
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param user body dto.RegisterRequestDto true "User Registration Data"
 // @Success 200 {object} response.Response{data=dto.AuthResponseDto} "Registration successful"
 // @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 403 {object} response.Response "Role not allowed"
 // @Failure 422 {object} response.Response "User already exists"
+// @Failure 422 {object} response.Response{data=dto.PasswordPolicyErrorDto} "Password does not meet the policy"
 // @Router /user/register [post]
 func (uc *UserController) Register(c *gin.Context) {
 	var registerRequest dto.RegisterRequestDto
@@ -38,13 +41,13 @@ func (uc *UserController) Register(c *gin.Context) {
 		return
 	}
 
-	result := uc.userService.Register(registerRequest)
+	result := uc.userService.Register(registerRequest, clientInfo(c))
 	response.HandleServiceResult(c, result)
 }
 
 // Login godoc
 // @Summary Login user
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return JWT token. Failed attempts are slowed down progressively and lock the email for a while past a threshold. Users with 2FA, or whose role requires it, get a dto.MfaChallengeResponseDto instead, to complete through login_mfa
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -52,6 +55,9 @@ func (uc *UserController) Register(c *gin.Context) {
 // @Success 200 {object} response.Response{data=dto.AuthResponseDto} "Login successful"
 // @Failure 400 {object} response.Response "Invalid request data"
 // @Failure 401 {object} response.Response "Invalid credentials"
+// @Failure 403 {object} response.Response "Account deactivated or email not verified"
+// @Failure 423 {object} response.Response "Account temporarily locked after too many failed attempts"
+// @Failure 429 {object} response.Response "Too many failed attempts from this IP"
 // @Router /user/login [post]
 func (uc *UserController) Login(c *gin.Context) {
 	var loginRequest dto.LoginRequestDto
@@ -60,36 +66,187 @@ func (uc *UserController) Login(c *gin.Context) {
 		return
 	}
 
-	result := uc.userService.Login(loginRequest.Email, loginRequest.Password)
+	result := uc.userService.Login(loginRequest.Email, loginRequest.Password, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// RefreshToken godoc
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access/refresh token pair. A refresh token can only be used once; presenting it again revokes every token issued from the same login
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body dto.RefreshTokenRequestDto true "Refresh Token"
+// @Success 200 {object} response.Response{data=dto.AuthResponseDto} "Token refreshed"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Invalid or reused refresh token"
+// @Router /user/refresh [post]
+func (uc *UserController) RefreshToken(c *gin.Context) {
+	var refreshRequest dto.RefreshTokenRequestDto
+	if err := c.ShouldBindJSON(&refreshRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.RefreshToken(refreshRequest.RefreshToken, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a single-use password reset link. The response is the same whether or not the email belongs to an account
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body dto.ForgotPasswordRequestDto true "Account Email"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Reset link sent if the account exists"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 429 {object} response.Response "Too many requests"
+// @Router /user/forgot_password [post]
+func (uc *UserController) ForgotPassword(c *gin.Context) {
+	var forgotRequest dto.ForgotPasswordRequestDto
+	if err := c.ShouldBindJSON(&forgotRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.ForgotPassword(forgotRequest.Email, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// VerifyEmail godoc
+// @Summary Verify email
+// @Description Confirm the account email with the token from the verification email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body dto.VerifyEmailRequestDto true "Verification Token"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Email verified"
+// @Failure 400 {object} response.Response "Invalid request data or invalid token"
+// @Router /user/verify_email [post]
+func (uc *UserController) VerifyEmail(c *gin.Context) {
+	var verifyRequest dto.VerifyEmailRequestDto
+	if err := c.ShouldBindJSON(&verifyRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.VerifyEmail(verifyRequest.Token)
+	response.HandleServiceResult(c, result)
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Email a new verification link, invalidating the previous one. The response is the same whether or not the email belongs to an unverified account
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body dto.ResendVerificationRequestDto true "Account Email"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Verification link sent if the account exists"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 429 {object} response.Response "Too many requests"
+// @Router /user/resend_verification [post]
+func (uc *UserController) ResendVerification(c *gin.Context) {
+	var resendRequest dto.ResendVerificationRequestDto
+	if err := c.ShouldBindJSON(&resendRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.ResendVerification(resendRequest.Email)
+	response.HandleServiceResult(c, result)
+}
+
+// RequestMagicLink godoc
+// @Summary Request a magic login link
+// @Description Email a signed, single-use link to log in without a password. The response is the same whether or not the email belongs to an account
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body dto.MagicLinkRequestDto true "Account Email"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Login link sent if the account exists"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 429 {object} response.Response "Too many requests"
+// @Router /user/login/magic [post]
+func (uc *UserController) RequestMagicLink(c *gin.Context) {
+	var magicRequest dto.MagicLinkRequestDto
+	if err := c.ShouldBindJSON(&magicRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.RequestMagicLink(magicRequest.Email)
+	response.HandleServiceResult(c, result)
+}
+
+// LoginMagicLink godoc
+// @Summary Log in with a magic link
+// @Description Redeem the token of an emailed login link. The response is the same as for /user/login, including the 2FA challenge when enabled
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body dto.MagicLinkConfirmRequestDto true "Magic Link Token"
+// @Success 200 {object} response.Response{data=dto.AuthResponseDto} "Login successful"
+// @Failure 400 {object} response.Response "Invalid request data or invalid, expired or already used token"
+// @Failure 403 {object} response.Response "Account inactive"
+// @Router /user/login/magic/confirm [post]
+func (uc *UserController) LoginMagicLink(c *gin.Context) {
+	var confirmRequest dto.MagicLinkConfirmRequestDto
+	if err := c.ShouldBindJSON(&confirmRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.LoginMagicLink(confirmRequest.Token, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password with the token from the reset email. Every existing session of the user is logged out
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body dto.ResetPasswordRequestDto true "Reset Token and New Password"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Password reset"
+// @Failure 400 {object} response.Response "Invalid request data or invalid token"
+// @Failure 422 {object} response.Response{data=dto.PasswordPolicyErrorDto} "Password does not meet the policy"
+// @Router /user/reset_password [post]
+func (uc *UserController) ResetPassword(c *gin.Context) {
+	var resetRequest dto.ResetPasswordRequestDto
+	if err := c.ShouldBindJSON(&resetRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.ResetPassword(resetRequest)
 	response.HandleServiceResult(c, result)
 }
 
 // GetUserByID godoc
 // @Summary Get user by ID
-// @Description Retrieves a user by their ID
+// @Description Retrieves the public profile of a user by their public ID, without the email
 // @Tags user
 // @Accept json
 // @Produce json
-// @Param id path int true "User ID"
-// @Success 200 {object} response.Response{data=dto.UserResponseDto} "User details"
+// @Param id path string true "User public ID"
+// @Success 200 {object} response.Response{data=dto.PublicProfileDto} "Public profile"
+// @Failure 404 {object} response.Response "User not found"
 // @Failure 422 {object} response.Response "Invalid user ID"
 // @Router /user/get_user/{id} [get]
 func (uc *UserController) GetUserByID(c *gin.Context) {
-	idParam := c.Param("id")
-	idUint64, err := strconv.ParseUint(idParam, 10, 0)
-	id := uint(idUint64)
-	if err != nil {
-		response.DataDetailResponse(c, 422, response.ErrCodeInvalidParams, nil)
+	id, ok := publicIDParam(c)
+	if !ok {
 		return
 	}
 
-	result := uc.userService.GetUserByID(id)
+	result := uc.userService.GetPublicProfile(id)
 	response.HandleServiceResult(c, result)
 }
 
 // GetListUser godoc
-// @Summary Get all users (Admin only)
-// @Description Returns a paginated list of users with filtering options. Only admin users can access this endpoint.
+// @Summary Get all users
+// @Description Returns a paginated list of users with filtering options. Requires the user:read permission.
 // @Tags user
 // @Accept json
 // @Produce json
@@ -100,7 +257,7 @@ func (uc *UserController) GetUserByID(c *gin.Context) {
 // @Success 200 {object} response.Response{data=dto.UserListResponseDto} "Paginated list of users"
 // @Failure 400 {object} response.Response "Invalid query parameters"
 // @Failure 401 {object} response.Response "Unauthorized"
-// @Failure 403 {object} response.Response "Access denied: Only admin can view user list"
+// @Failure 403 {object} response.Response "Access denied: user:read permission required"
 // @Router /user/list_user [get]
 func (uc *UserController) GetListUser(c *gin.Context) {
 	var req dto.UserListRequestDto
@@ -111,14 +268,13 @@ func (uc *UserController) GetListUser(c *gin.Context) {
 		return
 	}
 
-	role, _ := c.Get("role")
-	result := uc.userService.GetListUser(req, role.(string))
+	result := uc.userService.GetListUser(currentActor(c), req)
 	response.HandleServiceResult(c, result)
 }
 
 // CreateUser godoc
 // @Summary Create a new user
-// @Description Creates a new user with the provided information
+// @Description Creates a new user with the provided information. Requires user:create, and user:manage to assign a role other than USER
 // @Tags user
 // @Accept json
 // @Produce json
@@ -129,6 +285,7 @@ func (uc *UserController) GetListUser(c *gin.Context) {
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden"
 // @Failure 405 {object} response.Response "User already exists"
+// @Failure 422 {object} response.Response{data=dto.PasswordPolicyErrorDto} "Password does not meet the policy"
 // @Router /user/create_user [post]
 func (uc *UserController) CreateUser(c *gin.Context) {
 	userRequest := dto.UserRequestDto{}
@@ -138,30 +295,29 @@ func (uc *UserController) CreateUser(c *gin.Context) {
 		return
 	}
 
-	result := uc.userService.CreateUser(userRequest.Email, userRequest.Username, userRequest.Password, userRequest.Role)
+	result := uc.userService.CreateUser(currentActor(c), userRequest.Email, userRequest.Username, userRequest.Password, userRequest.Role)
 	response.HandleServiceResult(c, result)
 }
 
 // UpdateUser godoc
 // @Summary Update user by ID
-// @Description Updates user information by user ID (email cannot be updated)
+// @Description Updates user information by user ID (email cannot be updated). Users can only update themselves and can't change their own role or password (see POST /user/me/password), updating others requires user:update and changing their role user:manage
 // @Tags user
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Param id path int true "User ID"
+// @Param id path string true "User public ID"
 // @Param user body dto.UserUpdateRequestDto true "User Update Data (username, password, role only)"
 // @Success 200 {object} response.Response{data=dto.UserResponseDto} "User updated successfully"
 // @Failure 400 {object} response.Response "Invalid request data"
 // @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Access denied"
 // @Failure 404 {object} response.Response "User not found"
+// @Failure 422 {object} response.Response{data=dto.PasswordPolicyErrorDto} "Invalid user ID, or password does not meet the policy"
 // @Router /user/update_user/{id} [put]
 func (uc *UserController) UpdateUser(c *gin.Context) {
-	idParam := c.Param("id")
-	idUint64, err := strconv.ParseUint(idParam, 10, 0)
-	id := uint(idUint64)
-	if err != nil {
-		response.DataDetailResponse(c, 422, response.ErrCodeInvalidParams, nil)
+	id, ok := publicIDParam(c)
+	if !ok {
 		return
 	}
 
@@ -171,7 +327,7 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	result := uc.userService.UpdateUser(id, updateRequest)
+	result := uc.userService.UpdateUser(currentActor(c), id, updateRequest)
 	response.HandleServiceResult(c, result)
 }
 
@@ -195,3 +351,128 @@ func (uc *UserController) GetCurrentUser(c *gin.Context) {
 	result := uc.userService.GetUserByID(userID.(uint))
 	response.HandleServiceResult(c, result)
 }
+
+// Logout godoc
+// @Summary Logout
+// @Description End the current session: its access and refresh tokens stop working. Logging out of an impersonation token ends the impersonation
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Logged out"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /user/logout [post]
+func (uc *UserController) Logout(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	result := uc.userService.Logout(currentActor(c), claims.(*jwt.JWTClaims), clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// CreateWebSocketTicket godoc
+// @Summary Create a WebSocket ticket
+// @Description Issue a short-lived, single-use ticket to open the WebSocket with /ws?ticket=... when the client can't send the access token in a header
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=dto.WebSocketTicketResponseDto} "Ticket created"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /user/ws_ticket [post]
+func (uc *UserController) CreateWebSocketTicket(c *gin.Context) {
+	token, exists := c.Get("token")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	result := uc.userService.CreateWebSocketTicket(token.(string))
+	response.HandleServiceResult(c, result)
+}
+
+// ForceLogout godoc
+// @Summary Force logout a user (Admin only)
+// @Description End every session of the user, revoking all access and refresh tokens issued so far. Recorded in the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User public ID"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "User logged out"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 422 {object} response.Response "Invalid user ID"
+// @Router /admin/force_logout/{id} [post]
+// @Router /admin/users/{id}/sessions [delete]
+func (uc *UserController) ForceLogout(c *gin.Context) {
+	id, ok := publicIDParam(c)
+	if !ok {
+		return
+	}
+
+	result := uc.userService.ForceLogout(currentActor(c), id, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// UnlockLogin godoc
+// @Summary Unlock the login of a user (Admin only)
+// @Description Lift the lockout caused by failed login attempts and clear the failure count. Recorded in the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User public ID"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "User login unlocked"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 422 {object} response.Response "Invalid user ID"
+// @Router /admin/unlock_login/{id} [post]
+func (uc *UserController) UnlockLogin(c *gin.Context) {
+	id, ok := publicIDParam(c)
+	if !ok {
+		return
+	}
+
+	result := uc.userService.UnlockLogin(currentActor(c), id, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// clientInfo describes the client of the request, the IP honoring trusted proxies only
+func clientInfo(c *gin.Context) dto.ClientInfoDto {
+	return dto.ClientInfoDto{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// publicIDParam reads the public ID of the ":id" route parameter, answering 422 when it
+// isn't one
+func publicIDParam(c *gin.Context) (string, bool) {
+	id := c.Param("id")
+	if !model.IsPublicID(id) {
+		response.DataDetailResponse(c, 422, response.ErrCodeInvalidParams, nil)
+		return "", false
+	}
+	return id, true
+}
+
+// currentActor builds the service Actor from the user info AuthMiddleware put in the context
+func currentActor(c *gin.Context) *service.Actor {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return nil
+	}
+	role, _ := c.Get("role")
+	permissions, _ := c.Get("permissions")
+	impersonatorID, _ := c.Get("impersonatorID")
+	impersonator, _ := impersonatorID.(uint)
+	return &service.Actor{
+		UserID:         userID.(uint),
+		Role:           role.(string),
+		Permissions:    permissions.([]string),
+		ImpersonatorID: impersonator,
+	}
+}
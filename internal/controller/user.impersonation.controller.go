@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonateUser godoc
+// @Summary Impersonate a user (Admin only)
+// @Description Issue a short-lived access token acting as the user, to reproduce their issues. Requires user:impersonate, and the user's role can't have permissions the admin lacks. Credentials, 2FA, sessions, tokens and admin endpoints are refused to the token, its responses carry the X-Kado-Impersonated-By header and start and end (logout) are recorded in the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User public ID"
+// @Param body body dto.ImpersonateRequestDto true "Reason"
+// @Success 200 {object} response.Response{data=dto.ImpersonationResponseDto} "Impersonation token"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden, or the user is inactive"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 422 {object} response.Response "Invalid user ID"
+// @Router /admin/users/{id}/impersonate [post]
+func (uc *UserController) ImpersonateUser(c *gin.Context) {
+	id, ok := publicIDParam(c)
+	if !ok {
+		return
+	}
+
+	var impersonateRequest dto.ImpersonateRequestDto
+	if err := c.ShouldBindJSON(&impersonateRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.ImpersonateUser(currentActor(c), id, impersonateRequest.Reason, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginMfa godoc
+// @Summary Complete a login with the second factor
+// @Description Exchange the MFA challenge token returned by login and a TOTP or recovery code for tokens. When the challenge was an enrollment, the first valid code enables 2FA and the response carries the recovery codes
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body dto.MfaLoginRequestDto true "Challenge Token and Code"
+// @Success 200 {object} response.Response{data=dto.AuthResponseDto} "Login successful"
+// @Failure 400 {object} response.Response "Invalid request data or 2FA not set up"
+// @Failure 401 {object} response.Response "Invalid challenge token or code"
+// @Failure 429 {object} response.Response "Too many attempts"
+// @Router /user/login_mfa [post]
+func (uc *UserController) LoginMfa(c *gin.Context) {
+	var mfaRequest dto.MfaLoginRequestDto
+	if err := c.ShouldBindJSON(&mfaRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.LoginMfa(mfaRequest.MfaToken, mfaRequest.Code, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// SetupMfaForLogin godoc
+// @Summary Set up 2FA during login
+// @Description For users whose role requires 2FA but who haven't enrolled yet: generate a TOTP secret with the MFA challenge token, then confirm it through login_mfa
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body dto.MfaTokenRequestDto true "Challenge Token"
+// @Success 200 {object} response.Response{data=dto.MfaSetupResponseDto} "TOTP secret"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Invalid challenge token"
+// @Failure 409 {object} response.Response "2FA already enabled"
+// @Router /user/login_mfa_setup [post]
+func (uc *UserController) SetupMfaForLogin(c *gin.Context) {
+	var setupRequest dto.MfaTokenRequestDto
+	if err := c.ShouldBindJSON(&setupRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.SetupMfaForLogin(setupRequest.MfaToken)
+	response.HandleServiceResult(c, result)
+}
+
+// SetupMfa godoc
+// @Summary Start 2FA enrollment
+// @Description Generate a new TOTP secret. 2FA is enabled once a code is confirmed with mfa_confirm
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=dto.MfaSetupResponseDto} "TOTP secret"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 409 {object} response.Response "2FA already enabled"
+// @Router /user/mfa_setup [post]
+func (uc *UserController) SetupMfa(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	result := uc.userService.SetupMfa(userID.(uint))
+	response.HandleServiceResult(c, result)
+}
+
+// ConfirmMfa godoc
+// @Summary Confirm 2FA enrollment
+// @Description Enable 2FA with a code of the new secret. The recovery codes are only shown in this response
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body dto.MfaCodeRequestDto true "TOTP Code"
+// @Success 200 {object} response.Response{data=dto.MfaRecoveryCodesResponseDto} "2FA enabled"
+// @Failure 400 {object} response.Response "Invalid request data or no enrollment started"
+// @Failure 401 {object} response.Response "Unauthorized or invalid code"
+// @Failure 409 {object} response.Response "2FA already enabled"
+// @Router /user/mfa_confirm [post]
+func (uc *UserController) ConfirmMfa(c *gin.Context) {
+	uc.handleMfaCode(c, uc.userService.ConfirmMfa)
+}
+
+// DisableMfa godoc
+// @Summary Disable 2FA
+// @Description Turn 2FA off, confirmed with a TOTP or recovery code. Not allowed for roles that require 2FA
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body dto.MfaCodeRequestDto true "TOTP or Recovery Code"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "2FA disabled"
+// @Failure 400 {object} response.Response "Invalid request data or 2FA not enabled"
+// @Failure 401 {object} response.Response "Unauthorized or invalid code"
+// @Failure 403 {object} response.Response "2FA required for the role"
+// @Router /user/mfa_disable [post]
+func (uc *UserController) DisableMfa(c *gin.Context) {
+	uc.handleMfaCode(c, uc.userService.DisableMfa)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace every recovery code with new ones, confirmed with a TOTP or recovery code
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body dto.MfaCodeRequestDto true "TOTP or Recovery Code"
+// @Success 200 {object} response.Response{data=dto.MfaRecoveryCodesResponseDto} "New recovery codes"
+// @Failure 400 {object} response.Response "Invalid request data or 2FA not enabled"
+// @Failure 401 {object} response.Response "Unauthorized or invalid code"
+// @Router /user/mfa_recovery_codes [post]
+func (uc *UserController) RegenerateRecoveryCodes(c *gin.Context) {
+	uc.handleMfaCode(c, uc.userService.RegenerateRecoveryCodes)
+}
+
+// ResetMfa godoc
+// @Summary Reset the 2FA of a user (Admin only)
+// @Description Remove the 2FA enrollment and recovery codes of a user who lost access to them. Recorded in the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User public ID"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "2FA reset"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 422 {object} response.Response "Invalid user ID"
+// @Router /admin/reset_mfa/{id} [post]
+func (uc *UserController) ResetMfa(c *gin.Context) {
+	id, ok := publicIDParam(c)
+	if !ok {
+		return
+	}
+
+	result := uc.userService.ResetMfa(currentActor(c), id, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// handleMfaCode runs a 2FA action of the current user that needs a code
+func (uc *UserController) handleMfaCode(c *gin.Context, action func(userID uint, code string) *response.ServiceResult) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	var codeRequest dto.MfaCodeRequestDto
+	if err := c.ShouldBindJSON(&codeRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := action(userID.(uint), codeRequest.Code)
+	response.HandleServiceResult(c, result)
+}
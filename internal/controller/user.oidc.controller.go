@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OidcAuthorize godoc
+// @Summary Start a login with an external provider
+// @Description Returns the OpenID Connect provider URL to send the user to (authorization code flow with PKCE). The provider redirects back to the frontend with code and state, to be posted to oidc/{provider}/callback
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name, e.g. google"
+// @Success 200 {object} response.Response{data=dto.OidcAuthorizeResponseDto} "Provider login URL"
+// @Failure 404 {object} response.Response "Provider not configured"
+// @Failure 502 {object} response.Response "Provider unreachable"
+// @Router /user/oidc/{provider}/authorize [get]
+func (uc *UserController) OidcAuthorize(c *gin.Context) {
+	result := uc.userService.OidcAuthorize(c.Param("provider"))
+	response.HandleServiceResult(c, result)
+}
+
+// OidcCallback godoc
+// @Summary Complete a login with an external provider
+// @Description Exchange the code the provider redirected back with for tokens. The identity is linked to the user with the same email if the provider verified it, or to a new user. Answers like login, including the 2FA challenge
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name, e.g. google"
+// @Param body body dto.OidcCallbackRequestDto true "Code and State"
+// @Success 200 {object} response.Response{data=dto.AuthResponseDto} "Login successful, or dto.MfaChallengeResponseDto"
+// @Failure 400 {object} response.Response "Invalid request data or expired state"
+// @Failure 401 {object} response.Response "Login rejected by the provider"
+// @Failure 403 {object} response.Response "Email not verified by the provider or account deactivated"
+// @Failure 404 {object} response.Response "Provider not configured or no account for the email"
+// @Router /user/oidc/{provider}/callback [post]
+func (uc *UserController) OidcCallback(c *gin.Context) {
+	var callbackRequest dto.OidcCallbackRequestDto
+	if err := c.ShouldBindJSON(&callbackRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.OidcCallback(c.Param("provider"), callbackRequest.Code, callbackRequest.State, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BeginPasskeyLogin godoc
+// @Summary Start a passkey login
+// @Description Returns the options for navigator.credentials.get(). No username is needed, the authenticator offers the passkeys it holds for this site
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=dto.PasskeyOptionsResponseDto} "Assertion options"
+// @Router /user/login_passkey_begin [post]
+func (uc *UserController) BeginPasskeyLogin(c *gin.Context) {
+	result := uc.userService.BeginPasskeyLogin()
+	response.HandleServiceResult(c, result)
+}
+
+// FinishPasskeyLogin godoc
+// @Summary Log in with a passkey
+// @Description Verify the PublicKeyCredential returned by navigator.credentials.get() and return tokens like login. The passkey's user verification counts as second factor, so there is no 2FA challenge
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body dto.PasskeyLoginRequestDto true "Ceremony ID and Credential"
+// @Success 200 {object} response.Response{data=dto.AuthResponseDto} "Login successful"
+// @Failure 400 {object} response.Response "Invalid request data or expired ceremony"
+// @Failure 401 {object} response.Response "Passkey verification failed"
+// @Failure 403 {object} response.Response "Account deactivated or email not verified"
+// @Router /user/login_passkey [post]
+func (uc *UserController) FinishPasskeyLogin(c *gin.Context) {
+	var loginRequest dto.PasskeyLoginRequestDto
+	if err := c.ShouldBindJSON(&loginRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.FinishPasskeyLogin(loginRequest, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// BeginPasskeyRegistration godoc
+// @Summary Start adding a passkey
+// @Description Returns the options for navigator.credentials.create(). Passkeys the user already has are excluded
+// @Tags passkey
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=dto.PasskeyOptionsResponseDto} "Creation options"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /user/passkey_register_begin [post]
+func (uc *UserController) BeginPasskeyRegistration(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	result := uc.userService.BeginPasskeyRegistration(userID.(uint))
+	response.HandleServiceResult(c, result)
+}
+
+// FinishPasskeyRegistration godoc
+// @Summary Add a passkey
+// @Description Verify the PublicKeyCredential returned by navigator.credentials.create() and store its public key
+// @Tags passkey
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body dto.PasskeyRegisterRequestDto true "Ceremony ID, Name and Credential"
+// @Success 200 {object} response.Response{data=dto.PasskeyResponseDto} "Passkey added"
+// @Failure 400 {object} response.Response "Invalid request data, expired ceremony or rejected credential"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /user/passkey_register [post]
+func (uc *UserController) FinishPasskeyRegistration(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	var registerRequest dto.PasskeyRegisterRequestDto
+	if err := c.ShouldBindJSON(&registerRequest); err != nil {
+		response.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	result := uc.userService.FinishPasskeyRegistration(userID.(uint), registerRequest)
+	response.HandleServiceResult(c, result)
+}
+
+// ListPasskeys godoc
+// @Summary List my passkeys
+// @Description List the passkeys the current user can log in with
+// @Tags passkey
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]dto.PasskeyResponseDto} "Passkeys"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /user/passkeys [get]
+func (uc *UserController) ListPasskeys(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	result := uc.userService.ListPasskeys(userID.(uint))
+	response.HandleServiceResult(c, result)
+}
+
+// DeletePasskey godoc
+// @Summary Remove a passkey
+// @Description Delete one of the current user's passkeys, it can't be used to log in anymore
+// @Tags passkey
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Passkey ID"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Passkey removed"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Passkey not found"
+// @Failure 422 {object} response.Response "Invalid passkey ID"
+// @Router /user/passkeys/{id} [delete]
+func (uc *UserController) DeletePasskey(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	idParam := c.Param("id")
+	idUint64, err := strconv.ParseUint(idParam, 10, 0)
+	id := uint(idUint64)
+	if err != nil {
+		response.DataDetailResponse(c, 422, response.ErrCodeInvalidParams, nil)
+		return
+	}
+
+	result := uc.userService.DeletePasskey(userID.(uint), id)
+	response.HandleServiceResult(c, result)
+}
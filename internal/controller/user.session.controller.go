@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"base_go_be/pkg/jwt"
+	"base_go_be/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListSessions godoc
+// @Summary List my sessions
+// @Description List the devices the current user is logged in on, most recently used first
+// @Tags session
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]dto.SessionResponseDto} "Sessions"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /user/sessions [get]
+func (uc *UserController) ListSessions(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	current := claims.(*jwt.JWTClaims)
+	result := uc.userService.ListSessions(currentActor(c).UserID, current.SessionID)
+	response.HandleServiceResult(c, result)
+}
+
+// RevokeSession godoc
+// @Summary Revoke one of my sessions
+// @Description Log out the device of the session, its tokens stop working immediately
+// @Tags session
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param session_id path string true "Session ID"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Session revoked"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Session not found"
+// @Router /user/sessions/{session_id} [delete]
+func (uc *UserController) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	result := uc.userService.RevokeSession(userID.(uint), c.Param("session_id"))
+	response.HandleServiceResult(c, result)
+}
+
+// RevokeOtherSessions godoc
+// @Summary Revoke my other sessions
+// @Description Log out every device except the one making the request
+// @Tags session
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Sessions revoked"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /user/sessions [delete]
+func (uc *UserController) RevokeOtherSessions(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.ErrorResponse(c, 401, "Unauthorized")
+		return
+	}
+
+	current := claims.(*jwt.JWTClaims)
+	result := uc.userService.RevokeOtherSessions(currentActor(c).UserID, current.SessionID)
+	response.HandleServiceResult(c, result)
+}
+
+// ListUserSessions godoc
+// @Summary List the sessions of a user (Admin only)
+// @Description List the devices a user is logged in on, most recently used first. Users whose role has permissions the admin lacks can't be viewed. Recorded in the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User public ID"
+// @Success 200 {object} response.Response{data=[]dto.SessionResponseDto} "Sessions"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 422 {object} response.Response "Invalid user ID"
+// @Router /admin/users/{id}/sessions [get]
+func (uc *UserController) ListUserSessions(c *gin.Context) {
+	id, ok := publicIDParam(c)
+	if !ok {
+		return
+	}
+
+	result := uc.userService.ListUserSessions(currentActor(c), id, clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
+
+// RevokeUserSession godoc
+// @Summary Revoke a session of a user (Admin only)
+// @Description Log out one device of a user, its tokens stop working immediately. Recorded in the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User public ID"
+// @Param session_id path string true "Session ID"
+// @Success 200 {object} response.Response{data=dto.MessageResponseDto} "Session revoked"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Session not found"
+// @Failure 422 {object} response.Response "Invalid user ID"
+// @Router /admin/users/{id}/sessions/{session_id} [delete]
+func (uc *UserController) RevokeUserSession(c *gin.Context) {
+	id, ok := publicIDParam(c)
+	if !ok {
+		return
+	}
+
+	result := uc.userService.RevokeUserSession(currentActor(c), id, c.Param("session_id"), clientInfo(c))
+	response.HandleServiceResult(c, result)
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+// AuditLogListRequestDto for pagination and filtering of the audit log
+type AuditLogListRequestDto struct {
+	Skip         int    `form:"skip" binding:"min=0"`
+	Limit        int    `form:"limit" binding:"min=0,max=100"`
+	ActorUserID  string `form:"actor_user_id" binding:"omitempty,uuid"`
+	TargetUserID string `form:"target_user_id" binding:"omitempty,uuid"`
+	Action       string `form:"action"`
+}
+
+// AuditLogResponseDto is one recorded sensitive action
+type AuditLogResponseDto struct {
+	Id           uint           `json:"id"`
+	ActorUserID  string         `json:"actor_user_id"`
+	Action       string         `json:"action"`
+	TargetUserID string         `json:"target_user_id,omitempty"`
+	Details      map[string]any `json:"details"`
+	IP           string         `json:"ip"`
+	UserAgent    string         `json:"user_agent"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// AuditLogListResponseDto for paginated audit log response
+type AuditLogListResponseDto struct {
+	Total int64                 `json:"total"`
+	Data  []AuditLogResponseDto `json:"data"`
+}
@@ -6,12 +6,71 @@ type LoginRequestDto struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// ClientInfoDto describes the client a request came from
+type ClientInfoDto struct {
+	IP        string
+	UserAgent string
+}
+
 // RegisterRequestDto represents the registration request structure
 type RegisterRequestDto struct {
 	Username string `json:"username" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
-	Role     string `json:"role" binding:"required,oneof=ADMIN USER"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"omitempty,oneof=ADMIN USER"`
+}
+
+// RefreshTokenRequestDto represents the refresh token request structure
+type RefreshTokenRequestDto struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// ForgotPasswordRequestDto represents the request for a password reset link
+type ForgotPasswordRequestDto struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequestDto represents the request to set a new password with a reset token
+type ResetPasswordRequestDto struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ChangePasswordRequestDto sets a new password for the signed-in user
+type ChangePasswordRequestDto struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// ChangeEmailRequestDto asks to move the account to another email, confirmed by a link sent there
+type ChangeEmailRequestDto struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"` // current password
+}
+
+// ConfirmEmailChangeRequestDto applies an email change with the token sent to the new address
+type ConfirmEmailChangeRequestDto struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// VerifyEmailRequestDto represents the request to confirm an email with the emailed token
+type VerifyEmailRequestDto struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResendVerificationRequestDto represents the request for a new verification link
+type ResendVerificationRequestDto struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// MagicLinkRequestDto represents the request for a passwordless login link
+type MagicLinkRequestDto struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// MagicLinkConfirmRequestDto represents the request to log in with the emailed link's token
+type MagicLinkConfirmRequestDto struct {
+	Token string `json:"token" binding:"required"`
 }
 
 // AuthResponseDto represents the authentication response
@@ -19,4 +78,28 @@ type AuthResponseDto struct {
 	Token        string          `json:"token"`
 	RefreshToken string          `json:"refresh_token"`
 	User         UserResponseDto `json:"user"`
+	// Set once, when a login completes the 2FA enrollment
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// WebSocketTicketResponseDto represents a one-time ticket for the WebSocket handshake
+type WebSocketTicketResponseDto struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+// PasswordPolicyErrorDto lists the password policy rules a new password breaks
+type PasswordPolicyErrorDto struct {
+	Violations []PasswordViolationDto `json:"violations"`
+}
+
+// PasswordViolationDto is one broken password rule, e.g. min_length or breached
+type PasswordViolationDto struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// MessageResponseDto represents a response that only carries a message
+type MessageResponseDto struct {
+	Message string `json:"message"`
 }
@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+// ImpersonateRequestDto represents the request to act as another user
+type ImpersonateRequestDto struct {
+	Reason string `json:"reason" binding:"required,max=255"` // kept in the audit log, e.g. a ticket number
+}
+
+// ImpersonationResponseDto carries the access token acting as the user. There is no
+// refresh token, a new impersonation has to be started once it expires
+type ImpersonationResponseDto struct {
+	Token          string          `json:"token"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	ImpersonatorID string          `json:"impersonator_id"`
+	User           UserResponseDto `json:"user"`
+}
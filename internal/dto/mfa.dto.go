@@ -0,0 +1,37 @@
+package dto
+
+// MfaChallengeResponseDto is returned by login instead of tokens when a second factor is due.
+// With MfaEnrollmentRequired the user has to set up TOTP first (see login_mfa_setup)
+type MfaChallengeResponseDto struct {
+	MfaRequired           bool   `json:"mfa_required"`
+	MfaEnrollmentRequired bool   `json:"mfa_enrollment_required"`
+	MfaToken              string `json:"mfa_token"`
+	ExpiresIn             int    `json:"expires_in"` // seconds
+}
+
+// MfaLoginRequestDto represents the second login step. Code is a TOTP code or a recovery code
+type MfaLoginRequestDto struct {
+	MfaToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// MfaTokenRequestDto represents a request authenticated by a MFA challenge token
+type MfaTokenRequestDto struct {
+	MfaToken string `json:"mfa_token" binding:"required"`
+}
+
+// MfaCodeRequestDto represents a request confirmed with a TOTP or recovery code
+type MfaCodeRequestDto struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MfaSetupResponseDto carries a new TOTP secret. OtpauthURI is the QR code payload
+type MfaSetupResponseDto struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+// MfaRecoveryCodesResponseDto lists recovery codes, shown to the user only once
+type MfaRecoveryCodesResponseDto struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+// OidcAuthorizeResponseDto gives the provider page the user must be sent to
+type OidcAuthorizeResponseDto struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// OidcCallbackRequestDto carries the query parameters the provider redirected back with
+type OidcCallbackRequestDto struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PasskeyOptionsResponseDto starts a passkey ceremony: Options go to
+// navigator.credentials.create() or get(), CeremonyID comes back with the result
+type PasskeyOptionsResponseDto struct {
+	CeremonyID string `json:"ceremony_id"`
+	Options    any    `json:"options" swaggertype:"object"`
+}
+
+// PasskeyRegisterRequestDto carries the PublicKeyCredential JSON from navigator.credentials.create()
+type PasskeyRegisterRequestDto struct {
+	CeremonyID string          `json:"ceremony_id" binding:"required"`
+	Name       string          `json:"name" binding:"required,max=100"`
+	Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+}
+
+// PasskeyLoginRequestDto carries the PublicKeyCredential JSON from navigator.credentials.get()
+type PasskeyLoginRequestDto struct {
+	CeremonyID string          `json:"ceremony_id" binding:"required"`
+	Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+}
+
+type PasskeyResponseDto struct {
+	Id         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Synced     bool       `json:"synced"` // backed up by a passkey provider, e.g. iCloud Keychain
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
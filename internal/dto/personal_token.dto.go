@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+// PersonalTokenRequestDto represents the request to create a personal access token
+type PersonalTokenRequestDto struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,required"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"required,min=1,max=365"`
+}
+
+type PersonalTokenResponseDto struct {
+	Id          uint       `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// PersonalTokenCreatedResponseDto carries the token itself, returned only at creation
+type PersonalTokenCreatedResponseDto struct {
+	Token string `json:"token"`
+	PersonalTokenResponseDto
+}
@@ -5,13 +5,12 @@ import (
 )
 
 type ProductRequestDto struct {
-	UserID      uint   `json:"user_id" gorm:"not null"`
 	Name        string `json:"name" binding:"required" gorm:"type:varchar(255);not null"`
 	Description string `json:"description" gorm:"type:text"`
 }
 
 type ProductDetailDto struct {
-	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ID          string    `json:"id"`
 	Name        string    `json:"name" gorm:"type:varchar(255);not null"`
 	Description string    `json:"description" gorm:"type:text"`
 	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
@@ -19,11 +18,11 @@ type ProductDetailDto struct {
 }
 
 type ProductResponseDto struct {
-	ID          uint            `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserID      uint            `json:"user_id" gorm:"not null"`
-	User        UserResponseDto `json:"user"`
-	Name        string          `json:"name" gorm:"type:varchar(255);not null"`
-	Description string          `json:"description" gorm:"type:text"`
-	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	ID          string            `json:"id"`
+	UserID      string            `json:"user_id"`
+	User        *PublicProfileDto `json:"user"`
+	Name        string            `json:"name" gorm:"type:varchar(255);not null"`
+	Description string            `json:"description" gorm:"type:text"`
+	CreatedAt   time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
 }
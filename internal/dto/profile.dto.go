@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+type NotificationPrefsDto struct {
+	ProductUpdates bool `json:"product_updates"`
+	Newsletter     bool `json:"newsletter"`
+}
+
+// ProfileResponseDto is the profile of the current user, private fields included
+type ProfileResponseDto struct {
+	Id                string               `json:"id"`
+	Email             string               `json:"email"`
+	Username          string               `json:"username"`
+	Fullname          string               `json:"fullname"`
+	Bio               string               `json:"bio"`
+	AvatarURL         string               `json:"avatar_url"` // "" without avatar
+	Locale            string               `json:"locale"`
+	Timezone          string               `json:"timezone"`
+	NotificationPrefs NotificationPrefsDto `json:"notification_prefs"`
+	CreatedAt         time.Time            `json:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at"`
+}
+
+// ProfileUpdateRequestDto changes the fields sent, "" clears a text field
+type ProfileUpdateRequestDto struct {
+	Fullname          *string                     `json:"fullname" binding:"omitempty,max=100"`
+	Bio               *string                     `json:"bio" binding:"omitempty,max=500"`
+	Locale            *string                     `json:"locale" binding:"omitempty,max=35" example:"vi-VN"`
+	Timezone          *string                     `json:"timezone" binding:"omitempty,max=64" example:"Asia/Ho_Chi_Minh"`
+	NotificationPrefs *NotificationPrefsUpdateDto `json:"notification_prefs"`
+}
+
+// NotificationPrefsUpdateDto changes the preferences sent and keeps the others
+type NotificationPrefsUpdateDto struct {
+	ProductUpdates *bool `json:"product_updates"`
+	Newsletter     *bool `json:"newsletter"`
+}
+
+// PublicProfileDto is what anyone can see of a user
+type PublicProfileDto struct {
+	Id        string `json:"id"`
+	Username  string `json:"username"`
+	Fullname  string `json:"fullname"`
+	Bio       string `json:"bio"`
+	AvatarURL string `json:"avatar_url"`
+}
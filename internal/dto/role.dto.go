@@ -0,0 +1,24 @@
+package dto
+
+// RoleRequestDto represents the request to create a role
+type RoleRequestDto struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+// RolePermissionsRequestDto replaces the permissions of a role
+type RolePermissionsRequestDto struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+type RoleResponseDto struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type PermissionResponseDto struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
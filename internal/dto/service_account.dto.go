@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// ServiceAccountRequestDto represents the request to create a service account
+type ServiceAccountRequestDto struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=255"`
+	Role        string `json:"role" binding:"required,max=50"`
+}
+
+// ServiceAccountUpdateRequestDto changes a service account, omitted fields are kept
+type ServiceAccountUpdateRequestDto struct {
+	Description *string `json:"description" binding:"omitempty,max=255"`
+	Role        string  `json:"role" binding:"omitempty,max=50"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+type ServiceAccountResponseDto struct {
+	Id          uint                           `json:"id"`
+	Name        string                         `json:"name"`
+	Description string                         `json:"description"`
+	Role        string                         `json:"role"`
+	OwnerUserId string                         `json:"owner_user_id"`
+	IsActive    bool                           `json:"is_active"`
+	Keys        []ServiceAccountKeyResponseDto `json:"keys"`
+	CreatedAt   time.Time                      `json:"created_at"`
+}
+
+type ServiceAccountKeyResponseDto struct {
+	KeyId         string     `json:"key_id"`
+	RequestCount  int64      `json:"request_count"`
+	RequestsToday int64      `json:"requests_today"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ServiceAccountKeyCreatedResponseDto carries the key secret, returned only at creation
+type ServiceAccountKeyCreatedResponseDto struct {
+	Secret string `json:"secret"`
+	ServiceAccountKeyResponseDto
+}
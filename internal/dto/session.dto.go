@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// SessionResponseDto describes a login session of the user
+type SessionResponseDto struct {
+	Id         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"` // the session of the token making the request
+	// Set when an admin is acting as the user in this session
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
+}
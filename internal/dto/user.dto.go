@@ -1,20 +1,22 @@
 package dto
 
+import "time"
+
 type UserRequestDto struct {
 	Email    string `json:"email" binding:"required,email"`
 	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required,min=6"`
-	Role     string `json:"role" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"required,max=50"`
 }
 
 type UserUpdateRequestDto struct {
 	Username string `json:"username" binding:"omitempty"`
-	Password string `json:"password" binding:"omitempty,min=6"`
-	Role     string `json:"role" binding:"omitempty"`
+	Password string `json:"password" binding:"omitempty"`
+	Role     string `json:"role" binding:"omitempty,max=50"`
 }
 
 type UserResponseDto struct {
-	Id       uint   `json:"id" binding:"required"`
+	Id       string `json:"id" binding:"required"`
 	Email    string `json:"email"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
@@ -32,3 +34,20 @@ type UserListResponseDto struct {
 	Total int64             `json:"total"`
 	Data  []UserResponseDto `json:"data"`
 }
+
+// AdminUserDetailDto is everything an admin sees of a user
+type AdminUserDetailDto struct {
+	Id              string     `json:"id"`
+	Email           string     `json:"email"`
+	Username        string     `json:"username"`
+	Role            string     `json:"role"`
+	IsActive        bool       `json:"is_active"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	MfaEnabled      bool       `json:"mfa_enabled"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// ChangeRoleRequestDto gives a user another role
+type ChangeRoleRequestDto struct {
+	Role string `json:"role" binding:"required,max=50"`
+}
@@ -0,0 +1,32 @@
+package initialize
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/wire"
+	"fmt"
+	"time"
+)
+
+// impersonationExpiryInterval is how late the end of an expired impersonation can be recorded
+const impersonationExpiryInterval = time.Minute
+
+// InitImpersonationExpiry starts the job recording in the audit log the end of the
+// impersonations left to expire
+func InitImpersonationExpiry() {
+	userService, err := wire.InitUserService()
+	checkErrPanic(err, "Initialize impersonation expiry failed")
+
+	go func() {
+		ticker := time.NewTicker(impersonationExpiryInterval)
+		defer ticker.Stop()
+		for ; ; <-ticker.C {
+			ended, err := userService.EndExpiredImpersonations()
+			if err != nil {
+				global.Logger.Error("Failed to end expired impersonations: " + err.Error())
+			}
+			if ended > 0 {
+				global.Logger.Info(fmt.Sprintf("Ended %d expired impersonation(s)", ended))
+			}
+		}
+	}()
+}
@@ -0,0 +1,91 @@
+package initialize
+
+import (
+	"base_go_be/global"
+	"base_go_be/pkg/config"
+	"base_go_be/pkg/jwt"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// InitJWT loads the signing keys. Rotating a key means adding the new <kid>.pem,
+// pointing JWT_ACTIVE_KID at it and keeping the old file (its public key is enough)
+// until the tokens it signed have expired. Tokens signed with JWT_SECRET_KEY before
+// the keys were configured are still accepted while it is set, but no new ones are
+// signed with it
+func InitJWT() {
+	c := config.JWT
+	if c.KeysDir == "" && c.PrivateKey == "" {
+		global.Logger.Warn("No JWT signing keys configured, falling back to HS256 with JWT_SECRET_KEY")
+		return
+	}
+
+	keys, err := loadJWTKeys(c)
+	checkErrPanic(err, "Load JWT keys failed")
+
+	keySet, err := jwt.NewKeySet(c.ActiveKeyID, keys...)
+	checkErrPanic(err, "Initialize JWT key set failed")
+	jwt.SetKeySet(keySet)
+	global.Logger.Info("JWT keys loaded", zap.Int("keys", len(keys)), zap.String("active_kid", keySet.ActiveKeyID()))
+}
+
+func loadJWTKeys(c config.JWTConfig) ([]*jwt.Key, error) {
+	var keys []*jwt.Key
+
+	if c.PrivateKey != "" {
+		if c.ActiveKeyID == "" {
+			return nil, fmt.Errorf("JWT_ACTIVE_KID is required with JWT_PRIVATE_KEY")
+		}
+		// env files can't hold multi-line values, so accept escaped newlines
+		key, err := jwt.ParseKeyPEM(c.ActiveKeyID, []byte(strings.ReplaceAll(c.PrivateKey, `\n`, "\n")))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if c.KeysDir != "" {
+		files, err := filepath.Glob(filepath.Join(c.KeysDir, "*.pem"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+			if c.PrivateKey != "" && kid == c.ActiveKeyID {
+				continue // the env key takes precedence
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			key, err := jwt.ParseKeyPEM(kid, data)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		}
+	}
+
+	// The shared secret only verifies, unless it is the public placeholder or a key
+	// file took its kid
+	if c.SecretKey != "" && c.SecretKey != config.DefaultSecretKey && !slices.ContainsFunc(keys, func(key *jwt.Key) bool {
+		return key.ID == jwt.DefaultKeyID
+	}) {
+		keys = append(keys, jwt.NewHMACVerifyKey(jwt.DefaultKeyID, []byte(c.SecretKey)))
+	}
+
+	return keys, nil
+}
+
+// JWKSHandler publishes the public keys so other services can verify our tokens
+func JWKSHandler(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwt.PublicJWKS())
+}
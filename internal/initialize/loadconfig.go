@@ -2,10 +2,13 @@ package initialize
 
 import (
 	"base_go_be/global"
+	"base_go_be/internal/model"
+	"base_go_be/pkg/config"
 	"base_go_be/pkg/setting"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -26,13 +29,16 @@ func LoadConfig() {
 		fmt.Printf("Error loading config: %v\n", err)
 		panic(err)
 	}
+	loadJWTConfigFromEnv(&config.JWT)
 }
 
 func loadConfigFromEnv(config *setting.Config) error {
 	// Load Server settings
 	config.Server = setting.ServerSetting{
-		Port: getEnvAsInt("SERVER_PORT", 8082),
-		Mode: getEnv("SERVER_MODE", "dev"),
+		Port:           getEnvAsInt("SERVER_PORT", 8082),
+		Mode:           getEnv("SERVER_MODE", "dev"),
+		FrontendURL:    getEnv("FRONTEND_URL", "http://localhost:3000"),
+		TrustedProxies: getEnvAsSlice("SERVER_TRUSTED_PROXIES", nil),
 	}
 
 	// Load MySQL settings
@@ -78,9 +84,96 @@ func loadConfigFromEnv(config *setting.Config) error {
 		Database: getEnvAsInt("REDIS_DATABASE", 0),
 	}
 
+	// Load Mail settings
+	config.Mail = setting.MailSetting{
+		Driver:       getEnv("MAIL_DRIVER", "smtp"),
+		From:         getEnv("MAIL_FROM", "KADO <no-reply@kado.local>"),
+		SMTPHost:     getEnv("SMTP_HOST", "127.0.0.1"),
+		SMTPPort:     getEnvAsInt("SMTP_PORT", 1025),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		FileDir:      getEnv("MAIL_FILE_DIR", "./storages/mails"),
+	}
+
+	// Load Auth settings
+	config.Auth = setting.AuthSetting{
+		PasswordResetTTL:         getEnvAsInt("AUTH_PASSWORD_RESET_TTL", 30),
+		RequireEmailVerification: getEnvAsBool("AUTH_REQUIRE_EMAIL_VERIFICATION", false),
+		EmailVerificationTTL:     getEnvAsInt("AUTH_EMAIL_VERIFICATION_TTL", 1440),
+		LoginMaxAttempts:         getEnvAsInt("AUTH_LOGIN_MAX_ATTEMPTS", 5),
+		LoginIPMaxAttempts:       getEnvAsInt("AUTH_LOGIN_IP_MAX_ATTEMPTS", 50),
+		LoginAttemptWindow:       getEnvAsInt("AUTH_LOGIN_ATTEMPT_WINDOW", 15),
+		LoginLockoutDuration:     getEnvAsInt("AUTH_LOGIN_LOCKOUT_DURATION", 15),
+		MFAIssuer:                getEnv("AUTH_MFA_ISSUER", "KADO"),
+		MFARequiredRoles:         getEnvAsSlice("AUTH_MFA_REQUIRED_ROLES", []string{model.RoleAdmin}),
+		SignatureWindow:          getEnvAsInt("AUTH_SIGNATURE_WINDOW", 300),
+		OIDCAutoRegister:         getEnvAsBool("AUTH_OIDC_AUTO_REGISTER", true),
+		MagicLinkTTL:             getEnvAsInt("AUTH_MAGIC_LINK_TTL", 15),
+		ImpersonationTTL:         getEnvAsInt("AUTH_IMPERSONATION_TTL", 15),
+		DeletedUserRetention:     getEnvAsInt("AUTH_DELETED_USER_RETENTION", 30),
+		UserPurgeInterval:        getEnvAsInt("AUTH_USER_PURGE_INTERVAL", 60),
+	}
+
+	// Load Security settings
+	config.Security = setting.SecuritySetting{
+		EncryptionKey: getEnv("APP_ENCRYPTION_KEY", "your-encryption-key-change-in-production"),
+	}
+
+	// Load Password policy and hashing settings
+	config.Password = setting.PasswordSetting{
+		MinLength:         getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+		MaxLength:         getEnvAsInt("PASSWORD_MAX_LENGTH", 128),
+		RequireUpper:      getEnvAsBool("PASSWORD_REQUIRE_UPPER", true),
+		RequireLower:      getEnvAsBool("PASSWORD_REQUIRE_LOWER", true),
+		RequireDigit:      getEnvAsBool("PASSWORD_REQUIRE_DIGIT", true),
+		RequireSymbol:     getEnvAsBool("PASSWORD_REQUIRE_SYMBOL", false),
+		BreachedListFile:  getEnv("PASSWORD_BREACHED_LIST_FILE", ""),
+		Argon2Memory:      getEnvAsInt("PASSWORD_ARGON2_MEMORY", 64*1024),
+		Argon2Iterations:  getEnvAsInt("PASSWORD_ARGON2_ITERATIONS", 3),
+		Argon2Parallelism: getEnvAsInt("PASSWORD_ARGON2_PARALLELISM", 2),
+	}
+
+	// Load WebAuthn (passkey) settings
+	config.WebAuthn = setting.WebAuthnSetting{
+		RPID:          getEnv("WEBAUTHN_RP_ID", "localhost"),
+		RPDisplayName: getEnv("WEBAUTHN_RP_NAME", "KADO"),
+		RPOrigins:     getEnvAsSlice("WEBAUTHN_RP_ORIGINS", []string{config.Server.FrontendURL}),
+	}
+
+	// Load storage settings
+	config.Storage = setting.StorageSetting{
+		Driver:        getEnv("STORAGE_DRIVER", "local"),
+		LocalDir:      getEnv("STORAGE_LOCAL_DIR", "./storages/uploads"),
+		PublicURL:     getEnv("STORAGE_PUBLIC_URL", ""),
+		AvatarMaxSize: getEnvAsInt("STORAGE_AVATAR_MAX_SIZE", 2048),
+	}
+
+	// Load OIDC providers, each configured by OIDC_<NAME>_* variables
+	config.OIDC = nil
+	for _, name := range getEnvAsSlice("OIDC_PROVIDERS", nil) {
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		config.OIDC = append(config.OIDC, setting.OIDCProviderSetting{
+			Name:         strings.ToLower(name),
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", config.Server.FrontendURL+"/auth/oidc/"+strings.ToLower(name)+"/callback"),
+			Scopes:       getEnvAsSlice(prefix+"SCOPES", nil),
+		})
+	}
+
 	return nil
 }
 
+func loadJWTConfigFromEnv(jwtConfig *config.JWTConfig) {
+	jwtConfig.Issuer = getEnv("JWT_ISSUER", jwtConfig.Issuer)
+	jwtConfig.Audience = getEnv("JWT_AUDIENCE", jwtConfig.Audience)
+	jwtConfig.SecretKey = getEnv("JWT_SECRET_KEY", jwtConfig.SecretKey)
+	jwtConfig.ActiveKeyID = getEnv("JWT_ACTIVE_KID", jwtConfig.ActiveKeyID)
+	jwtConfig.KeysDir = getEnv("JWT_KEYS_DIR", jwtConfig.KeysDir)
+	jwtConfig.PrivateKey = getEnv("JWT_PRIVATE_KEY", jwtConfig.PrivateKey)
+}
+
 // Helper functions
 func getEnv(key string, defaultVal string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -104,3 +197,14 @@ func getEnvAsBool(name string, defaultVal bool) bool {
 	}
 	return defaultVal
 }
+
+// getEnvAsSlice reads a comma separated list. Set but empty gives an empty list
+func getEnvAsSlice(name string, defaultVal []string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(name, strings.Join(defaultVal, ",")), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
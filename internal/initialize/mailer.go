@@ -0,0 +1,13 @@
+package initialize
+
+import (
+	"base_go_be/global"
+	"base_go_be/pkg/mailer"
+)
+
+func InitMailer() {
+	m, err := mailer.NewMailer(global.Config.Mail, global.Logger.Logger)
+	checkErrPanic(err, "Initialize mailer failed")
+	global.Mailer = m
+	global.Logger.Info("Mailer initialized with driver " + global.Config.Mail.Driver)
+}
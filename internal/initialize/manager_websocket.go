@@ -2,9 +2,15 @@ package initialize
 
 import (
 	"base_go_be/global"
+	"base_go_be/internal/middlewares"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/jwt"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,7 +20,7 @@ import (
 
 type ConnectionManager struct {
 	mu sync.RWMutex
-	// map user_id
+	// public ID of the user → their connections
 	connections map[string]map[*websocket.Conn]struct{}
 }
 
@@ -24,11 +30,16 @@ func NewConnectionManager() *ConnectionManager {
 	}
 }
 
+// wsAuthProtocol lets browsers, which can't set headers on the handshake, send the
+// access token as a second subprotocol: new WebSocket(url, ["kado.bearer", token])
+const wsAuthProtocol = "kado.bearer"
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
 	//Adjust CORS depending on the environment
-	CheckOrigin: func(r *http.Request) bool { return true },
+	CheckOrigin:  func(r *http.Request) bool { return true },
+	Subprotocols: []string{wsAuthProtocol},
 }
 
 func (cm *ConnectionManager) Connect(w http.ResponseWriter, r *http.Request, userID string) (*websocket.Conn, error) {
@@ -164,23 +175,173 @@ func InitWebSocketManager() {
 	global.WsManager = NewConnectionManager()
 }
 
+// wsTokenFromRequest reads the access token from the Authorization header, the
+// Sec-WebSocket-Protocol header or a one-time ticket, in that order
+func wsTokenFromRequest(c *gin.Context, tokenRepo repo.ITokenRepository) (string, error) {
+	if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer "), nil
+	}
+
+	protocols := websocket.Subprotocols(c.Request)
+	for i, protocol := range protocols {
+		if protocol == wsAuthProtocol && i+1 < len(protocols) {
+			return protocols[i+1], nil
+		}
+	}
+
+	if ticket := c.Query("ticket"); ticket != "" {
+		return tokenRepo.ConsumeWebSocketTicket(ticket)
+	}
+	return "", nil
+}
+
+// wsRevalidateInterval is how often a socket's access token is checked again, so that
+// logging out, ending the session or deactivating the account closes it
+const wsRevalidateInterval = 30 * time.Second
+
+// errAccountBarred is returned for a valid token whose user may no longer log in
+var errAccountBarred = errors.New("account barred")
+
+// authorizeSocket validates the access token as AuthMiddleware does, the account
+// status and the impersonating admin included
+func authorizeSocket(tokenRepo repo.ITokenRepository, sessionRepo repo.ISessionRepository, userRepo repo.IUserRepository,
+	tokenString string) (*jwt.JWTClaims, *model.User, error) {
+	claims, session, user, err := middlewares.ValidateAccessToken(tokenRepo, sessionRepo, userRepo, tokenString)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session.ImpersonatorID != 0 && middlewares.SessionImpersonator(userRepo, session, claims) == nil {
+		return nil, nil, middlewares.ErrTokenRevoked
+	}
+	if middlewares.AccountStatusCode(user) != 0 {
+		return nil, nil, errAccountBarred
+	}
+	return claims, user, nil
+}
+
+// wsSession checks the socket's access token again every wsRevalidateInterval and
+// when it expires, and closes the socket once the token is no longer accepted. The
+// client can extend it by sending {"type": "auth", "token": "<new access token>"}
+type wsSession struct {
+	mu          sync.Mutex
+	ws          *websocket.Conn
+	userID      string
+	token       string
+	timer       *time.Timer
+	stopped     bool
+	tokenRepo   repo.ITokenRepository
+	sessionRepo repo.ISessionRepository
+	userRepo    repo.IUserRepository
+}
+
+func newWsSession(ws *websocket.Conn, claims *jwt.JWTClaims, token string, tokenRepo repo.ITokenRepository,
+	sessionRepo repo.ISessionRepository, userRepo repo.IUserRepository) *wsSession {
+	s := &wsSession{ws: ws, userID: claims.UserID, token: token, tokenRepo: tokenRepo, sessionRepo: sessionRepo, userRepo: userRepo}
+	s.timer = time.AfterFunc(nextWsCheck(claims), s.revalidate)
+	return s
+}
+
+func nextWsCheck(claims *jwt.JWTClaims) time.Duration {
+	return min(time.Until(claims.ExpiresAt.Time), wsRevalidateInterval)
+}
+
+func (s *wsSession) revalidate() {
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+
+	claims, _, err := authorizeSocket(s.tokenRepo, s.sessionRepo, s.userRepo, token)
+	switch {
+	case errors.Is(err, jwt.ErrExpiredToken):
+		s.close("token expired")
+		return
+	case errors.Is(err, errAccountBarred) || middlewares.IsTokenError(err):
+		s.close("session ended")
+		return
+	case err != nil:
+		// Redis or the database being down doesn't end anyone's session
+		log.Printf("websocket revalidation failed for %s: %v", s.userID, err)
+		s.schedule(wsRevalidateInterval)
+		return
+	}
+	s.schedule(nextWsCheck(claims))
+}
+
+// schedule runs the next check after d, unless the socket was closed meanwhile
+func (s *wsSession) schedule(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.stopped {
+		s.timer.Reset(d)
+	}
+}
+
+func (s *wsSession) close(reason string) {
+	msg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason)
+	_ = s.ws.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	global.WsManager.Disconnect(s.ws)
+}
+
+func (s *wsSession) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	s.timer.Stop()
+}
+
+func (s *wsSession) reauthenticate(tokenString string) error {
+	claims, _, err := authorizeSocket(s.tokenRepo, s.sessionRepo, s.userRepo, tokenString)
+	if err != nil {
+		return err
+	}
+	if claims.UserID != s.userID {
+		return jwt.ErrInvalidToken
+	}
+	s.mu.Lock()
+	s.token = tokenString
+	s.mu.Unlock()
+	s.schedule(nextWsCheck(claims))
+	return nil
+}
+
 func WebSocketHandler(c *gin.Context) {
+	tokenRepo := repo.NewTokenRepository()
+	sessionRepo := repo.NewSessionRepository()
+	userRepo := repo.NewUserRepository()
+	tokenString, err := wsTokenFromRequest(c, tokenRepo)
+	if err != nil {
+		global.Logger.Error("Failed to read websocket ticket: " + err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if tokenString == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing access token"})
+		return
+	}
 
-	userID := c.Query("user_id")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing user_id parameter"})
+	claims, user, err := authorizeSocket(tokenRepo, sessionRepo, userRepo, tokenString)
+	if errors.Is(err, errAccountBarred) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "account not allowed to log in"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid access token"})
 		return
 	}
 
+	// Clients only ever see public IDs, so sockets are addressed by them
+	userID := user.PublicID
 	ws, err := global.WsManager.Connect(c.Writer, c.Request, userID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "websocket upgrade failed"})
 		return
 	}
+	session := newWsSession(ws, claims, tokenString, tokenRepo, sessionRepo, userRepo)
 
 	// Goroutine reads to detect client close and cleans up
 	go func(userID string, ws *websocket.Conn) {
 		defer global.WsManager.Disconnect(ws)
+		defer session.stop()
 		for {
 			_, msgData, err := ws.ReadMessage()
 			if err != nil {
@@ -198,10 +359,22 @@ func WebSocketHandler(c *gin.Context) {
 
 			// handle type
 			switch payload["type"] {
+			case "auth":
+				token, _ := payload["token"].(string)
+				if err := session.reauthenticate(token); err != nil {
+					log.Printf("websocket re-authentication failed for %s: %v", userID, err)
+				}
 			case "direct":
-				to := payload["to"].(string)
+				to, ok := payload["to"].(string)
+				if !ok {
+					log.Printf("direct message without recipient from %s", userID)
+					continue
+				}
+				// never trust the sender the client claims to be
+				payload["from"] = userID
 				global.WsManager.SendToUser(to, payload)
 			case "broadcast":
+				payload["from"] = userID
 				global.WsManager.Broadcast(payload)
 			default:
 				log.Printf("unknown message type from %s: %+v", userID, payload)
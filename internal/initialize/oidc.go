@@ -0,0 +1,23 @@
+package initialize
+
+import (
+	"base_go_be/global"
+	"base_go_be/pkg/oidc"
+
+	"go.uber.org/zap"
+)
+
+// InitOIDC registers the external login providers. Their discovery documents are only
+// fetched on first login
+func InitOIDC() {
+	providers := make([]*oidc.Provider, 0, len(global.Config.OIDC))
+	for _, provider := range global.Config.OIDC {
+		if provider.Issuer == "" || provider.ClientID == "" {
+			global.Logger.Warn("Skipping OIDC provider without issuer or client ID", zap.String("provider", provider.Name))
+			continue
+		}
+		providers = append(providers, oidc.NewProvider(provider))
+		global.Logger.Info("OIDC provider configured", zap.String("provider", provider.Name), zap.String("issuer", provider.Issuer))
+	}
+	oidc.SetProviders(providers...)
+}
@@ -0,0 +1,36 @@
+package initialize
+
+import (
+	"base_go_be/global"
+	"base_go_be/pkg/passwd"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// InitPassword applies the configured password policy and argon2id cost
+func InitPassword() {
+	c := global.Config.Password
+
+	policy := passwd.DefaultPolicy()
+	policy.MinLength = c.MinLength
+	policy.MaxLength = c.MaxLength
+	policy.RequireUpper = c.RequireUpper
+	policy.RequireLower = c.RequireLower
+	policy.RequireDigit = c.RequireDigit
+	policy.RequireSymbol = c.RequireSymbol
+	if c.BreachedListFile != "" {
+		file, err := os.Open(c.BreachedListFile)
+		checkErrPanic(err, "Open breached password list failed")
+		defer file.Close()
+		checkErrPanic(policy.AddBreached(file), "Read breached password list failed")
+	}
+	passwd.SetPolicy(policy)
+
+	params := passwd.DefaultParams
+	params.Memory = uint32(c.Argon2Memory)
+	params.Iterations = uint32(c.Argon2Iterations)
+	params.Parallelism = uint8(c.Argon2Parallelism)
+	passwd.SetParams(params)
+	global.Logger.Info("Password policy configured", zap.Int("min_length", c.MinLength), zap.String("breached_list", c.BreachedListFile))
+}
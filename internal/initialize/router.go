@@ -2,7 +2,9 @@ package initialize
 
 import (
 	"base_go_be/global"
+	"base_go_be/internal/middlewares"
 	"base_go_be/internal/routers"
+	"base_go_be/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,8 +20,13 @@ func InitRouter() *gin.Engine {
 		r = gin.New()
 	}
 
+	// Only trust X-Forwarded-For from known proxies, c.ClientIP() feeds the login limits
+	if err := r.SetTrustedProxies(global.Config.Server.TrustedProxies); err != nil {
+		panic(err)
+	}
+
 	//middleware
-	//r.Use() //logging
+	r.Use(middlewares.RequestLogger()) //logging
 	//r.Use() // cross
 	//r.Use() // limiter global
 
@@ -28,6 +35,19 @@ func InitRouter() *gin.Engine {
 	{
 		userRouter.InitUserRouter(MainGroup)
 		userRouter.InitProductRouter(MainGroup)
+		userRouter.InitRoleRouter(MainGroup)
+		userRouter.InitPersonalTokenRouter(MainGroup)
+		userRouter.InitServiceAccountRouter(MainGroup)
+		userRouter.InitAuditLogRouter(MainGroup)
+		userRouter.InitProfileRouter(MainGroup)
+	}
+
+	// Public signing keys for services verifying our tokens
+	r.GET("/.well-known/jwks.json", JWKSHandler)
+
+	// Uploaded files, when kept by the app itself
+	if local, ok := global.Storage.(*storage.LocalStorage); ok {
+		r.StaticFS(storage.LocalRoute, gin.Dir(local.Dir(), false))
 	}
 
 	// WebSocket endpoint
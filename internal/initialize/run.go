@@ -19,11 +19,18 @@ func handleErr(err error) {
 func Run() {
 	LoadConfig()
 	InitLogger()
+	InitJWT()
+	InitPassword()
 	//global.Logger.Info("check logger", zap.String("key", "value"))
 	//Mysql()
 	Postgres()
 	Redis()
 	InitWebSocketManager()
+	InitMailer()
+	InitStorage()
+	InitOIDC()
+	InitUserPurge()
+	InitImpersonationExpiry()
 
 	r := InitRouter()
 	r.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
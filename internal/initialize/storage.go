@@ -0,0 +1,13 @@
+package initialize
+
+import (
+	"base_go_be/global"
+	"base_go_be/pkg/storage"
+)
+
+func InitStorage() {
+	s, err := storage.NewStorage(global.Config.Storage)
+	checkErrPanic(err, "Initialize storage failed")
+	global.Storage = s
+	global.Logger.Info("Storage initialized with driver " + global.Config.Storage.Driver)
+}
@@ -0,0 +1,35 @@
+package initialize
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/wire"
+	"fmt"
+	"time"
+)
+
+// InitUserPurge starts the job removing for good the users deleted more than
+// AUTH_DELETED_USER_RETENTION days ago, every AUTH_USER_PURGE_INTERVAL minutes
+func InitUserPurge() {
+	interval := time.Duration(global.Config.Auth.UserPurgeInterval) * time.Minute
+	if interval <= 0 {
+		global.Logger.Info("Purge of deleted users disabled")
+		return
+	}
+	retention := time.Duration(global.Config.Auth.DeletedUserRetention) * 24 * time.Hour
+	userService, err := wire.InitUserService()
+	checkErrPanic(err, "Initialize user purge failed")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for ; ; <-ticker.C {
+			purged, err := userService.PurgeDeletedUsers(retention)
+			if err != nil {
+				global.Logger.Error("Failed to purge deleted users: " + err.Error())
+			}
+			if purged > 0 {
+				global.Logger.Info(fmt.Sprintf("Purged %d deleted user(s)", purged))
+			}
+		}
+	}()
+}
@@ -1,17 +1,109 @@
 package middlewares
 
 import (
-	"base_go_be/pkg/config"
+	"base_go_be/global"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/hmacsign"
 	"base_go_be/pkg/jwt"
 	"base_go_be/pkg/response"
 	"errors"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+var ErrTokenRevoked = errors.New("token revoked")
+
+// HeaderImpersonatedBy is set on every response to a request made with an
+// impersonation token, to the public ID of the admin acting as the user
+const HeaderImpersonatedBy = "X-Kado-Impersonated-By"
+
+// sessionTouchInterval limits how often a session's last-seen time is written,
+// and a personal token's last-used time
+const sessionTouchInterval = time.Minute
+
+// ValidateAccessToken validates an access token and rejects it if it was revoked
+// by logout or by an admin, or if the session it belongs to has ended. It resolves
+// the public ID the token names to the user
+func ValidateAccessToken(tokenRepo repo.ITokenRepository, sessionRepo repo.ISessionRepository, userRepo repo.IUserRepository,
+	tokenString string) (*jwt.JWTClaims, *repo.Session, *model.User, error) {
+	claims, err := jwt.ValidateToken(tokenString, jwt.TokenTypeAccess)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// A deleted user's tokens name no one anymore
+	user := userRepo.GetUserByPublicID(claims.UserID)
+	if user == nil {
+		return nil, nil, nil, ErrTokenRevoked
+	}
+
+	revoked, err := tokenRepo.IsTokenRevoked(claims.ID, user.ID, claims.IssuedAt.Time)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if revoked || claims.SessionID == "" {
+		return nil, nil, nil, ErrTokenRevoked
+	}
+
+	session, err := sessionRepo.GetSession(claims.SessionID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if session == nil || session.UserID != user.ID || (session.ImpersonatorID != 0) != (claims.ImpersonatorID != "") {
+		return nil, nil, nil, ErrTokenRevoked
+	}
+	return claims, session, user, nil
+}
+
+// SessionImpersonator returns the admin acting as the user in an impersonation
+// session, or nil if the token doesn't name them or they may no longer log in
+func SessionImpersonator(userRepo repo.IUserRepository, session *repo.Session, claims *jwt.JWTClaims) *model.User {
+	impersonator := userRepo.GetUserByID(session.ImpersonatorID)
+	if impersonator == nil || impersonator.PublicID != claims.ImpersonatorID || AccountStatusCode(impersonator) != 0 {
+		return nil
+	}
+	return impersonator
+}
+
+// IsTokenError tells token problems (401) apart from infrastructure failures (500)
+func IsTokenError(err error) bool {
+	return errors.Is(err, jwt.ErrInvalidToken) || errors.Is(err, jwt.ErrExpiredToken) ||
+		errors.Is(err, jwt.ErrInvalidTokenType) || errors.Is(err, ErrTokenRevoked)
+}
+
+// AccountStatusCode returns the error code barring the user from the API, or 0
+func AccountStatusCode(user *model.User) int {
+	if !user.IsActive {
+		return response.ErrCodeAccountInactive
+	}
+	if global.Config.Auth.RequireEmailVerification && !user.IsEmailVerified() {
+		return response.ErrCodeEmailNotVerified
+	}
+	return 0
+}
+
+// AuthMiddleware authenticates the request with either an access token (JWT), a
+// personal access token, or a service account signature. A personal access token only
+// gets the permissions of the user's role that are also in its scopes
 func AuthMiddleware() gin.HandlerFunc {
+	return NewAuthMiddleware(repo.NewTokenRepository(), repo.NewRoleRepository(), repo.NewUserRepository(),
+		repo.NewSessionRepository(), repo.NewPersonalTokenRepository(), ServiceAccountMiddleware())
+}
+
+// NewAuthMiddleware is AuthMiddleware on the repositories given, serviceAccountAuth
+// handling the signed requests
+func NewAuthMiddleware(tokenRepo repo.ITokenRepository, roleRepo repo.IRoleRepository, userRepo repo.IUserRepository,
+	sessionRepo repo.ISessionRepository, personalTokenRepo repo.IPersonalTokenRepository, serviceAccountAuth gin.HandlerFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if c.GetHeader(hmacsign.HeaderKeyID) != "" {
+			serviceAccountAuth(c)
+			return
+		}
+
 		authHeader := c.Request.Header.Get("Authorization")
 		if authHeader == "" {
 			response.ErrorResponse(c, 401, response.ErrInvalidToken)
@@ -28,46 +120,139 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		// Extract the token
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		claims, err := jwt.ValidateToken(tokenString, config.JWT.SecretKey)
-		if err != nil {
-			if errors.Is(err, jwt.ErrExpiredToken) {
-				response.ErrorResponse(c, 401, "Token expired")
-			} else {
+
+		var user *model.User
+		var claims *jwt.JWTClaims
+		var session *repo.Session
+		var personalToken *model.PersonalAccessToken
+		if strings.HasPrefix(tokenString, model.PersonalTokenPrefix) {
+			personalToken = personalTokenRepo.GetPersonalTokenByToken(tokenString)
+			if personalToken == nil || personalToken.IsExpired() {
 				response.ErrorResponse(c, 401, response.ErrInvalidToken)
+				c.Abort()
+				return
+			}
+			user = userRepo.GetUserByID(personalToken.UserID)
+		} else {
+			var err error
+			claims, session, user, err = ValidateAccessToken(tokenRepo, sessionRepo, userRepo, tokenString)
+			if err != nil {
+				switch {
+				case errors.Is(err, jwt.ErrExpiredToken):
+					response.ErrorResponse(c, 401, "Token expired")
+				case IsTokenError(err):
+					response.ErrorResponse(c, 401, response.ErrInvalidToken)
+				default:
+					global.Logger.Error("Failed to validate access token: " + err.Error())
+					response.ErrorResponse(c, 500, response.ErrCodeInternalError)
+				}
+				c.Abort()
+				return
 			}
+
+			// The admin losing access ends their impersonations too
+			if session.ImpersonatorID != 0 {
+				impersonator := SessionImpersonator(userRepo, session, claims)
+				if impersonator == nil {
+					response.ErrorResponse(c, 401, response.ErrInvalidToken)
+					c.Abort()
+					return
+				}
+				c.Set("impersonatorID", impersonator.ID)
+				c.Header(HeaderImpersonatedBy, impersonator.PublicID)
+			}
+		}
+
+		// The token may outlive the account being deactivated
+		if user == nil {
+			response.ErrorResponse(c, 401, response.ErrInvalidToken)
+			c.Abort()
+			return
+		}
+		if code := AccountStatusCode(user); code != 0 {
+			response.DataDetailResponse(c, 403, code, nil)
+			c.Abort()
+			return
+		}
+
+		// Load the permissions granted by the user's role
+		permissions, err := roleRepo.GetRolePermissions(user.Role)
+		if err != nil {
+			global.Logger.Error("Failed to load role permissions: " + err.Error())
+			response.ErrorResponse(c, 500, response.ErrCodeInternalError)
 			c.Abort()
 			return
 		}
 
+		if personalToken != nil {
+			permissions = slices.DeleteFunc(permissions, func(p string) bool {
+				return !slices.Contains(personalToken.Scopes, p)
+			})
+			if personalToken.LastUsedAt == nil || time.Since(*personalToken.LastUsedAt) > sessionTouchInterval {
+				if err := personalTokenRepo.TouchPersonalToken(personalToken.ID); err != nil {
+					global.Logger.Warn("Failed to update personal token: " + err.Error())
+				}
+			}
+			c.Set("personalTokenID", personalToken.ID)
+		} else {
+			if time.Since(session.LastSeenAt) > sessionTouchInterval {
+				if err := sessionRepo.TouchSession(session, c.ClientIP(), 0); err != nil {
+					global.Logger.Warn("Failed to update session: " + err.Error())
+				}
+			}
+			c.Set("claims", claims)
+			c.Set("token", tokenString)
+		}
+
 		// Store user info in context for later use
-		c.Set("userID", claims.UserID)
-		c.Set("email", claims.Email)
-		c.Set("role", claims.Role)
+		c.Set("userID", user.ID)
+		c.Set("email", user.Email)
+		c.Set("role", user.Role)
+		c.Set("permissions", permissions)
 
 		c.Next()
 	}
 }
 
-// RoleMiddleware checks if the user has a required role
-func RoleMiddleware(roles ...string) gin.HandlerFunc {
+// RequireUserSession only lets through requests made from a login session (JWT), for
+// account and admin actions that personal access tokens must not reach whatever their scopes
+func RequireUserSession() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userRole, exists := c.Get("role")
-		if !exists {
-			response.ErrorResponse(c, 401, response.ErrInvalidToken)
+		if _, exists := c.Get("claims"); !exists {
+			response.ErrorResponse(c, 403, "Forbidden: this endpoint requires a login session")
 			c.Abort()
 			return
 		}
 
-		// Check if a user role is in the allowed roles
-		hasRole := false
-		for _, role := range roles {
-			if userRole == role {
-				hasRole = true
-				break
-			}
+		c.Next()
+	}
+}
+
+// RejectImpersonation refuses sensitive account actions (credentials, 2FA, sessions,
+// tokens) to an admin acting as the user
+func RejectImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, impersonated := c.Get("impersonatorID"); impersonated {
+			response.DataDetailResponse(c, 403, response.ErrCodeImpersonationForbidden, nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission checks if the role of the user grants the permission
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		permissions, exists := c.Get("permissions")
+		if !exists {
+			response.ErrorResponse(c, 401, response.ErrInvalidToken)
+			c.Abort()
+			return
 		}
 
-		if !hasRole {
+		if !slices.Contains(permissions.([]string), permission) {
 			response.ErrorResponse(c, 403, "Forbidden: insufficient permissions")
 			c.Abort()
 			return
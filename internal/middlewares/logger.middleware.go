@@ -1 +1,35 @@
 package middlewares
+
+import (
+	"base_go_be/global"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RequestLogger logs every request once it has been handled, with the user making it
+// and, for impersonation tokens, the admin acting as them
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("ip", c.ClientIP()),
+		}
+		if userID, exists := c.Get("userID"); exists {
+			fields = append(fields, zap.Any("user_id", userID))
+		}
+		if impersonatorID, exists := c.Get("impersonatorID"); exists {
+			fields = append(fields, zap.Bool("impersonated", true), zap.Any("impersonator_id", impersonatorID))
+			global.Logger.Info("Request (impersonated)", fields...)
+			return
+		}
+		global.Logger.Info("Request", fields...)
+	}
+}
@@ -0,0 +1,147 @@
+package middlewares
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/hmacsign"
+	"base_go_be/pkg/response"
+	"base_go_be/pkg/secretbox"
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSignedBodySize caps the body read into memory to check its hash
+const maxSignedBodySize = 10 << 20
+
+// ServiceAccountMiddleware authenticates a request signed with a service account's API
+// key (see pkg/hmacsign). The timestamp must be within AUTH_SIGNATURE_WINDOW of server
+// time and a signature is only accepted once. The account gets the permissions of its
+// role and acts as its owner user for what it creates
+func ServiceAccountMiddleware() gin.HandlerFunc {
+	return NewServiceAccountMiddleware(repo.NewServiceAccountRepository(), repo.NewSignedRequestRepository(),
+		repo.NewRoleRepository(), repo.NewUserRepository())
+}
+
+// NewServiceAccountMiddleware is ServiceAccountMiddleware on the repositories given
+func NewServiceAccountMiddleware(serviceAccountRepo repo.IServiceAccountRepository, signedRequestRepo repo.ISignedRequestRepository,
+	roleRepo repo.IRoleRepository, userRepo repo.IUserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keyID := c.GetHeader(hmacsign.HeaderKeyID)
+		timestamp := c.GetHeader(hmacsign.HeaderTimestamp)
+		// Hex is case-insensitive, so the signature is remembered in one case only:
+		// otherwise changing the case of a letter would get a replay through
+		signature := strings.ToLower(c.GetHeader(hmacsign.HeaderSignature))
+		if keyID == "" || timestamp == "" || signature == "" {
+			response.DataDetailResponse(c, 401, response.ErrCodeInvalidSignature, nil)
+			c.Abort()
+			return
+		}
+
+		window := time.Duration(global.Config.Auth.SignatureWindow) * time.Second
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			response.DataDetailResponse(c, 401, response.ErrCodeInvalidSignature, nil)
+			c.Abort()
+			return
+		}
+		if skew := time.Since(time.Unix(unix, 0)); skew > window || skew < -window {
+			response.DataDetailResponse(c, 401, response.ErrCodeRequestExpired, nil)
+			c.Abort()
+			return
+		}
+
+		key := serviceAccountRepo.GetServiceAccountKey(keyID)
+		if key == nil {
+			response.DataDetailResponse(c, 401, response.ErrCodeInvalidSignature, nil)
+			c.Abort()
+			return
+		}
+		if !key.ServiceAccount.IsActive {
+			response.DataDetailResponse(c, 403, response.ErrCodeAccountInactive, nil)
+			c.Abort()
+			return
+		}
+
+		secret, err := secretbox.Decrypt(global.Config.Security.EncryptionKey, key.Secret)
+		if err != nil {
+			global.Logger.Error("Failed to decrypt service account key: " + err.Error())
+			response.ErrorResponse(c, 500, response.ErrCodeInternalError)
+			c.Abort()
+			return
+		}
+
+		// Read the body to hash it, then put it back for the handler
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedBodySize))
+		if err != nil {
+			response.ErrorResponse(c, 413, "Request body too large")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if !hmacsign.Verify(secret, signature, c.Request.Method, c.Request.URL.RequestURI(), timestamp, body) {
+			response.DataDetailResponse(c, 401, response.ErrCodeInvalidSignature, nil)
+			c.Abort()
+			return
+		}
+
+		// Remembered for twice the window, as the timestamp may be ahead of server time
+		fresh, err := signedRequestRepo.MarkSignatureUsed(keyID, signature, 2*window)
+		if err != nil {
+			global.Logger.Error("Failed to check request replay: " + err.Error())
+			response.ErrorResponse(c, 500, response.ErrCodeInternalError)
+			c.Abort()
+			return
+		}
+		if !fresh {
+			response.DataDetailResponse(c, 401, response.ErrCodeRequestReplayed, nil)
+			c.Abort()
+			return
+		}
+
+		// What the account creates belongs to its owner, who must still exist and be
+		// allowed in as they would be with their own token
+		owner := userRepo.GetUserByID(key.ServiceAccount.OwnerUserID)
+		if owner == nil {
+			response.DataDetailResponse(c, 403, response.ErrCodeAccountInactive, nil)
+			c.Abort()
+			return
+		}
+		if code := AccountStatusCode(owner); code != 0 {
+			response.DataDetailResponse(c, 403, code, nil)
+			c.Abort()
+			return
+		}
+
+		permissions, err := roleRepo.GetRolePermissions(key.ServiceAccount.Role)
+		if err != nil {
+			global.Logger.Error("Failed to load role permissions: " + err.Error())
+			response.ErrorResponse(c, 500, response.ErrCodeInternalError)
+			c.Abort()
+			return
+		}
+
+		if err := signedRequestRepo.RecordKeyUsage(keyID); err != nil {
+			global.Logger.Warn("Failed to count service account key usage: " + err.Error())
+		}
+		if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) > sessionTouchInterval {
+			if err := serviceAccountRepo.TouchServiceAccountKey(key.ID); err != nil {
+				global.Logger.Warn("Failed to update service account key: " + err.Error())
+			}
+		}
+
+		c.Set("userID", key.ServiceAccount.OwnerUserID)
+		c.Set("role", key.ServiceAccount.Role)
+		c.Set("permissions", permissions)
+		c.Set("serviceAccountID", key.ServiceAccount.ID)
+		c.Set("serviceAccountKeyID", keyID)
+
+		c.Next()
+	}
+}
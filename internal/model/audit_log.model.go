@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+)
+
+// Actions recorded in the audit log
+const (
+	AuditActionImpersonationStart = "impersonation.start"
+	AuditActionImpersonationEnd   = "impersonation.end"
+	AuditActionUserDeactivate     = "user.deactivate"
+	AuditActionUserReactivate     = "user.reactivate"
+	AuditActionUserRoleChange     = "user.role_change"
+	AuditActionUserPasswordReset  = "user.password_reset"
+	AuditActionUserSessionsRevoke = "user.sessions_revoke"
+	AuditActionUserSessionRevoke  = "user.session_revoke"
+	AuditActionUserLoginUnlock    = "user.login_unlock"
+	AuditActionUserMfaReset       = "user.mfa_reset"
+	AuditActionUserDelete         = "user.delete"
+	AuditActionUserRestore        = "user.restore"
+	AuditActionUserView           = "user.view"
+	AuditActionUserSessionsView   = "user.sessions_view"
+)
+
+// AuditLog records a sensitive action: who did it, to which user, and from where
+type AuditLog struct {
+	ID           uint           `gorm:"primaryKey;autoIncrement"`
+	ActorUserID  uint           `gorm:"not null;index"`
+	Actor        *User          `gorm:"foreignKey:ActorUserID"`
+	Action       string         `gorm:"type:varchar(64);not null;index"`
+	TargetUserID *uint          `gorm:"index"`
+	Target       *User          `gorm:"foreignKey:TargetUserID"`
+	Details      map[string]any `gorm:"type:jsonb;serializer:json;not null"`
+	IP           string         `gorm:"type:varchar(45);not null"`
+	UserAgent    string         `gorm:"type:text;not null"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime"`
+}
+
+func (a *AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+// UserMfa is the TOTP enrollment of a user. TotpSecret is stored encrypted
+type UserMfa struct {
+	UserID       uint       `gorm:"primaryKey"`
+	TotpSecret   string     `gorm:"type:varchar(255);not null"`
+	EnabledAt    *time.Time `gorm:"default:null"`
+	LastUsedStep int64      `gorm:"not null;default:0"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+}
+
+func (m *UserMfa) TableName() string {
+	return "user_mfa"
+}
+
+// IsEnabled reports whether the enrollment was confirmed with a valid code
+func (m *UserMfa) IsEnabled() bool {
+	return m.EnabledAt != nil
+}
+
+// MfaRecoveryCode is a single-use code to log in without the authenticator, stored hashed
+type MfaRecoveryCode struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `gorm:"not null;index"`
+	CodeHash  string     `gorm:"type:varchar(64);not null"`
+	UsedAt    *time.Time `gorm:"default:null"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+func (c *MfaRecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
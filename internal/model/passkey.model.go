@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// Passkey is a WebAuthn credential a user logs in with. Only the public key is stored,
+// with the sign counter of the authenticator
+type Passkey struct {
+	ID              uint       `gorm:"primaryKey;autoIncrement"`
+	UserID          uint       `gorm:"not null;index"`
+	Name            string     `gorm:"type:varchar(100);not null"`
+	CredentialID    []byte     `gorm:"type:bytea;uniqueIndex;not null"`
+	PublicKey       []byte     `gorm:"type:bytea;not null"`
+	AttestationType string     `gorm:"type:varchar(32);not null;default:''"`
+	Transports      []string   `gorm:"type:jsonb;serializer:json;not null"`
+	AAGUID          []byte     `gorm:"column:aaguid;type:bytea"`
+	SignCount       uint32     `gorm:"type:bigint;not null;default:0"`
+	BackupEligible  bool       `gorm:"not null;default:false"`
+	BackupState     bool       `gorm:"not null;default:false"`
+	LastUsedAt      *time.Time `gorm:"default:null"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime"`
+}
+
+func (p *Passkey) TableName() string {
+	return "passkeys"
+}
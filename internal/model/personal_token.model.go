@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+)
+
+// PersonalTokenPrefix starts every personal access token, telling them apart from JWTs
+const PersonalTokenPrefix = "kado_pat_"
+
+// PersonalAccessToken is a long-lived token a user creates for scripts. Only its hash
+// is stored, and it only grants the Scopes its owner's role still has
+type PersonalAccessToken struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement"`
+	UserID      uint       `gorm:"not null;index"`
+	Name        string     `gorm:"type:varchar(100);not null"`
+	TokenPrefix string     `gorm:"type:varchar(20);not null"`
+	TokenHash   string     `gorm:"type:varchar(64);uniqueIndex;not null"`
+	Scopes      []string   `gorm:"type:jsonb;serializer:json;not null"`
+	ExpiresAt   time.Time  `gorm:"not null"`
+	LastUsedAt  *time.Time `gorm:"default:null"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+}
+
+func (t *PersonalAccessToken) TableName() string {
+	return "personal_access_tokens"
+}
+
+func (t *PersonalAccessToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
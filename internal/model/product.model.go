@@ -2,12 +2,15 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Product struct {
 	ID          uint      `gorm:"primaryKey;autoIncrement"`
-	UserID      uint      `gorm:"not null"`
-	User        User      `gorm:"foreignKey:UserID;references:ID"`
+	PublicID    string    `gorm:"type:uuid;uniqueIndex;not null"` // the only ID exposed by the API
+	UserID      *uint     // nil once the owner has been purged
+	User        *User     `gorm:"foreignKey:UserID;references:ID"`
 	Name        string    `gorm:"type:varchar(255);not null"`
 	Description string    `gorm:"type:text"`
 	CreatedAt   time.Time `gorm:"autoCreateTime"`
@@ -17,3 +20,12 @@ type Product struct {
 func (p *Product) TableName() string {
 	return "products"
 }
+
+func (p *Product) BeforeCreate(tx *gorm.DB) error {
+	if p.PublicID != "" {
+		return nil
+	}
+	publicID, err := NewPublicID()
+	p.PublicID = publicID
+	return err
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"github.com/google/uuid"
+)
+
+// NewPublicID returns a UUIDv7, the identifier clients see instead of the integer
+// primary key: it can't be guessed by counting, yet sorts by creation time
+func NewPublicID() (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// IsPublicID reports whether s has the form of a public ID
+func IsPublicID(s string) bool {
+	_, err := uuid.Parse(s)
+	return err == nil && len(s) == 36
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+)
+
+// Roles and permissions seeded by the migrations
+const (
+	RoleAdmin = "ADMIN"
+	RoleUser  = "USER"
+
+	PermissionUserRead      = "user:read"
+	PermissionUserCreate    = "user:create"
+	PermissionUserUpdate    = "user:update"
+	PermissionUserManage    = "user:manage"
+	PermissionRoleManage    = "role:manage"
+	PermissionProductRead   = "product:read"
+	PermissionProductWrite  = "product:write"
+	PermissionProductDelete = "product:delete"
+
+	PermissionServiceAccountManage = "service_account:manage"
+	PermissionUserImpersonate      = "user:impersonate"
+	PermissionAuditLogRead         = "audit_log:read"
+)
+
+type Role struct {
+	Name        string       `gorm:"primaryKey;type:varchar(50)"`
+	Description string       `gorm:"type:varchar(255)"`
+	Permissions []Permission `gorm:"many2many:role_permissions;foreignKey:Name;joinForeignKey:RoleName;references:Name;joinReferences:PermissionName"`
+	CreatedAt   time.Time    `gorm:"autoCreateTime"`
+}
+
+func (r *Role) TableName() string {
+	return "roles"
+}
+
+type Permission struct {
+	Name        string `gorm:"primaryKey;type:varchar(100)"`
+	Description string `gorm:"type:varchar(255)"`
+}
+
+func (p *Permission) TableName() string {
+	return "permissions"
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+)
+
+// ServiceAccountKeyPrefix starts every service account key ID
+const ServiceAccountKeyPrefix = "kado_sak_"
+
+// ServiceAccount is a non-human principal for partner backends. It gets the permissions
+// of its Role, and what it creates belongs to OwnerUserID, the admin who set it up
+type ServiceAccount struct {
+	ID          uint                `gorm:"primaryKey;autoIncrement"`
+	Name        string              `gorm:"type:varchar(100);unique;not null"`
+	Description string              `gorm:"type:varchar(255)"`
+	Role        string              `gorm:"type:varchar(50);not null"`
+	OwnerUserID uint                `gorm:"not null"`
+	Owner       *User               `gorm:"foreignKey:OwnerUserID"`
+	IsActive    bool                `gorm:"not null;default:true"`
+	Keys        []ServiceAccountKey `gorm:"foreignKey:ServiceAccountID"`
+	CreatedAt   time.Time           `gorm:"autoCreateTime"`
+}
+
+func (s *ServiceAccount) TableName() string {
+	return "service_accounts"
+}
+
+// ServiceAccountKey is an API key of a service account, used to sign requests with
+// HMAC. Unlike tokens the secret can't be hashed, so it is stored encrypted
+type ServiceAccountKey struct {
+	ID               uint            `gorm:"primaryKey;autoIncrement"`
+	ServiceAccountID uint            `gorm:"not null;index"`
+	ServiceAccount   *ServiceAccount `gorm:"foreignKey:ServiceAccountID"`
+	KeyID            string          `gorm:"type:varchar(40);uniqueIndex;not null"`
+	Secret           string          `gorm:"type:text;not null"`
+	LastUsedAt       *time.Time      `gorm:"default:null"`
+	CreatedAt        time.Time       `gorm:"autoCreateTime"`
+}
+
+func (k *ServiceAccountKey) TableName() string {
+	return "service_account_keys"
+}
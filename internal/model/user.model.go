@@ -2,18 +2,60 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement"`
-	Username  string    `gorm:"type:varchar(255);not null"`
-	Email     string    `gorm:"type:varchar(255);unique;not null"`
-	Password  string    `gorm:"type:varchar(255);not null"`
-	IsActive  bool      `gorm:"not null;default:true"`
-	Role      string    `gorm:"type:enum('ADMIN', 'USER');not null"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
+	ID                uint              `gorm:"primaryKey;autoIncrement"`
+	PublicID          string            `gorm:"type:uuid;uniqueIndex;not null"` // the only ID exposed by the API
+	Username          string            `gorm:"type:varchar(255);not null"`
+	Email             string            `gorm:"type:varchar(255);unique;not null"`
+	Password          string            `gorm:"type:varchar(255);not null"`
+	IsActive          bool              `gorm:"not null;default:true"`
+	Role              string            `gorm:"type:varchar(50);not null;default:USER"`
+	EmailVerifiedAt   *time.Time        `gorm:"default:null"`
+	Fullname          string            `gorm:"type:varchar(100);not null;default:''"`
+	Bio               string            `gorm:"type:varchar(500);not null;default:''"`
+	AvatarKey         string            `gorm:"type:varchar(255);not null;default:''"` // storage key of the avatar, "" without one
+	Locale            string            `gorm:"type:varchar(35);not null;default:''"`  // BCP 47 tag
+	Timezone          string            `gorm:"type:varchar(64);not null;default:''"`  // IANA time zone name
+	NotificationPrefs NotificationPrefs `gorm:"type:jsonb;serializer:json;not null"`
+	CreatedAt         time.Time         `gorm:"autoCreateTime"`
+	UpdatedAt         time.Time         `gorm:"autoUpdateTime"`
+	// Deleted users are left out of every query, unless Unscoped, until they are purged
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 func (u *User) TableName() string {
 	return "users"
 }
+
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.NotificationPrefs == (NotificationPrefs{}) {
+		u.NotificationPrefs = DefaultNotificationPrefs()
+	}
+	if u.PublicID != "" {
+		return nil
+	}
+	publicID, err := NewPublicID()
+	u.PublicID = publicID
+	return err
+}
+
+// NotificationPrefs are the optional notifications the user wants. Account and
+// security emails are always sent
+type NotificationPrefs struct {
+	ProductUpdates bool `json:"product_updates"`
+	Newsletter     bool `json:"newsletter"`
+}
+
+// DefaultNotificationPrefs are those of new users
+func DefaultNotificationPrefs() NotificationPrefs {
+	return NotificationPrefs{ProductUpdates: true}
+}
+
+// IsEmailVerified reports whether the user confirmed owning their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+// UserIdentity links a user to their account at an external OIDC provider
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `gorm:"not null;index"`
+	Provider  string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject   string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Email     string    `gorm:"type:varchar(255)"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (i *UserIdentity) TableName() string {
+	return "user_identities"
+}
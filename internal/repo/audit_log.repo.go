@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type IAuditLogRepository interface {
+	CreateAuditLog(entry *model.AuditLog) error
+	GetListAuditLog(req dto.AuditLogListRequestDto) ([]model.AuditLog, int64, error)
+}
+
+func NewAuditLogRepository() IAuditLogRepository {
+	return &auditLogRepository{db: global.Postgres}
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func (r *auditLogRepository) CreateAuditLog(entry *model.AuditLog) error {
+	if entry.Details == nil {
+		entry.Details = map[string]any{}
+	}
+	return r.db.Create(entry).Error
+}
+
+// GetListAuditLog returns the matching entries, most recent first
+func (r *auditLogRepository) GetListAuditLog(req dto.AuditLogListRequestDto) ([]model.AuditLog, int64, error) {
+	var entries []model.AuditLog
+	var total int64
+
+	// Users are filtered by public ID
+	query := r.db.Model(&model.AuditLog{})
+	if req.ActorUserID != "" {
+		query = query.Where("actor_user_id = (?)", r.db.Model(&model.User{}).Select("id").Where("public_id = ?", req.ActorUserID))
+	}
+	if req.TargetUserID != "" {
+		query = query.Where("target_user_id = (?)", r.db.Model(&model.User{}).Select("id").Where("public_id = ?", req.TargetUserID))
+	}
+	if req.Action != "" {
+		query = query.Where("action = ?", req.Action)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Preload("Actor", unscoped).Preload("Target", unscoped).Order("id DESC").Offset(req.Skip).Limit(req.Limit).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// unscoped includes deleted users, who still appear in the entries about them
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"base_go_be/global"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	loginFailuresEmailKey = "login_failures:email:%s" // email hash -> failed attempts in the current window
+	loginFailuresIPKey    = "login_failures:ip:%s"    // client IP -> failed attempts in the current window
+	loginLockKey          = "login_lock:%s"           // email hash of a locked account
+)
+
+// Counters are keyed by email, not user ID, so unknown emails are counted and locked
+// exactly like real accounts. Emails are hashed to keep them out of Redis
+type ILoginAttemptRepository interface {
+	GetLockTTL(email string) (time.Duration, error)
+	GetIPFailures(ip string) (int64, error)
+	RecordFailure(email string, ip string, window time.Duration) (int64, error)
+	Lock(email string, duration time.Duration) error
+	Reset(email string) error
+}
+
+func NewLoginAttemptRepository() ILoginAttemptRepository {
+	return &loginAttemptRepository{rdb: global.Redis}
+}
+
+type loginAttemptRepository struct {
+	rdb *redis.Client
+}
+
+// GetLockTTL returns how long the email stays locked, or 0 if it isn't
+func (r *loginAttemptRepository) GetLockTTL(email string) (time.Duration, error) {
+	ttl, err := r.rdb.PTTL(ctx, fmt.Sprintf(loginLockKey, emailKey(email))).Result()
+	if err != nil {
+		return 0, err
+	}
+	// PTTL returns a negative value when the key doesn't exist
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (r *loginAttemptRepository) GetIPFailures(ip string) (int64, error) {
+	failures, err := r.rdb.Get(ctx, fmt.Sprintf(loginFailuresIPKey, ip)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return failures, err
+}
+
+// RecordFailure counts a failed attempt against both the email and the IP, and
+// returns the failures of the email in the current window
+func (r *loginAttemptRepository) RecordFailure(email string, ip string, window time.Duration) (int64, error) {
+	emailFailuresKey := fmt.Sprintf(loginFailuresEmailKey, emailKey(email))
+	ipFailuresKey := fmt.Sprintf(loginFailuresIPKey, ip)
+
+	pipe := r.rdb.TxPipeline()
+	emailFailures := pipe.Incr(ctx, emailFailuresKey)
+	pipe.ExpireNX(ctx, emailFailuresKey, window)
+	pipe.Incr(ctx, ipFailuresKey)
+	pipe.ExpireNX(ctx, ipFailuresKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return emailFailures.Val(), nil
+}
+
+// Lock blocks logins for the email and starts a fresh failure count for when it ends
+func (r *loginAttemptRepository) Lock(email string, duration time.Duration) error {
+	hash := emailKey(email)
+	pipe := r.rdb.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf(loginLockKey, hash), 1, duration)
+	pipe.Del(ctx, fmt.Sprintf(loginFailuresEmailKey, hash))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Reset lifts the lock of the email and clears its failures
+func (r *loginAttemptRepository) Reset(email string) error {
+	hash := emailKey(email)
+	return r.rdb.Del(ctx, fmt.Sprintf(loginLockKey, hash), fmt.Sprintf(loginFailuresEmailKey, hash)).Err()
+}
+
+func emailKey(email string) string {
+	return hashToken(strings.ToLower(strings.TrimSpace(email)))
+}
@@ -0,0 +1,87 @@
+package repo
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IMfaRepository interface {
+	GetUserMfa(userID uint) *model.UserMfa
+	SaveUserMfa(mfa *model.UserMfa) error
+	UseTotpStep(userID uint, step int64) (bool, error)
+	DeleteUserMfa(userID uint) error
+	ReplaceRecoveryCodes(userID uint, codeHashes []string) error
+	UseRecoveryCode(userID uint, codeHash string) (bool, error)
+}
+
+func NewMfaRepository() IMfaRepository {
+	return &mfaRepository{db: global.Postgres}
+}
+
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+func (r *mfaRepository) GetUserMfa(userID uint) *model.UserMfa {
+	var mfa model.UserMfa
+	err := r.db.Where("user_id = ?", userID).First(&mfa).Error
+	if err != nil {
+		return nil
+	}
+	return &mfa
+}
+
+// SaveUserMfa creates or replaces the enrollment of the user
+func (r *mfaRepository) SaveUserMfa(mfa *model.UserMfa) error {
+	return r.db.Save(mfa).Error
+}
+
+// UseTotpStep records the step of an accepted code, and returns false if that step (or
+// a later one) was already used, so the same code can't be replayed
+func (r *mfaRepository) UseTotpStep(userID uint, step int64) (bool, error) {
+	result := r.db.Model(&model.UserMfa{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteUserMfa removes the enrollment and recovery codes of the user
+func (r *mfaRepository) DeleteUserMfa(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MfaRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.UserMfa{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes drops every recovery code of the user and stores the new ones
+func (r *mfaRepository) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	codes := make([]model.MfaRecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, model.MfaRecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MfaRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode marks an unused code as used, false if there is no such code
+func (r *mfaRepository) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	result := r.db.Model(&model.MfaRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
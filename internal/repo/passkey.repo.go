@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const passkeyCeremonyKey = "passkey_ceremony:%s"
+
+// PasskeyCeremony is kept between the begin and finish steps of a registration or
+// login. UserID is 0 for a login, the user is only known from the passkey
+type PasskeyCeremony struct {
+	UserID  uint                 `json:"user_id"`
+	Session webauthn.SessionData `json:"session"`
+}
+
+type IPasskeyRepository interface {
+	GetListPasskey(userID uint) ([]model.Passkey, error)
+	CreatePasskey(passkey *model.Passkey) error
+	UpdatePasskeyUsage(passkey *model.Passkey) error
+	DeletePasskey(userID uint, id uint) (bool, error)
+	CreatePasskeyCeremony(ceremony *PasskeyCeremony, ttl time.Duration) (string, error)
+	ConsumePasskeyCeremony(ceremonyID string) (*PasskeyCeremony, error)
+}
+
+func NewPasskeyRepository() IPasskeyRepository {
+	return &passkeyRepository{db: global.Postgres, rdb: global.Redis}
+}
+
+type passkeyRepository struct {
+	db  *gorm.DB
+	rdb *redis.Client
+}
+
+func (r *passkeyRepository) GetListPasskey(userID uint) ([]model.Passkey, error) {
+	var passkeys []model.Passkey
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&passkeys).Error; err != nil {
+		return nil, err
+	}
+	return passkeys, nil
+}
+
+func (r *passkeyRepository) CreatePasskey(passkey *model.Passkey) error {
+	return r.db.Create(passkey).Error
+}
+
+// UpdatePasskeyUsage saves the sign counter and backup state of a login
+func (r *passkeyRepository) UpdatePasskeyUsage(passkey *model.Passkey) error {
+	return r.db.Model(passkey).Updates(map[string]interface{}{
+		"sign_count":   passkey.SignCount,
+		"backup_state": passkey.BackupState,
+		"last_used_at": time.Now(),
+	}).Error
+}
+
+// DeletePasskey deletes a passkey of the user, false if the user has no such passkey
+func (r *passkeyRepository) DeletePasskey(userID uint, id uint) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Passkey{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *passkeyRepository) CreatePasskeyCeremony(ceremony *PasskeyCeremony, ttl time.Duration) (string, error) {
+	ceremonyID, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(ceremony)
+	if err != nil {
+		return "", err
+	}
+	if err := r.rdb.Set(ctx, fmt.Sprintf(passkeyCeremonyKey, ceremonyID), data, ttl).Err(); err != nil {
+		return "", err
+	}
+	return ceremonyID, nil
+}
+
+// ConsumePasskeyCeremony returns the ceremony and deletes it, so its challenge is only
+// answered once. nil if it doesn't exist or has expired
+func (r *passkeyRepository) ConsumePasskeyCeremony(ceremonyID string) (*PasskeyCeremony, error) {
+	data, err := r.rdb.GetDel(ctx, fmt.Sprintf(passkeyCeremonyKey, ceremonyID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ceremony PasskeyCeremony
+	if err := json.Unmarshal(data, &ceremony); err != nil {
+		return nil, err
+	}
+	return &ceremony, nil
+}
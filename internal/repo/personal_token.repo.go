@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const personalTokenDisplayLength = len(model.PersonalTokenPrefix) + 4
+
+type IPersonalTokenRepository interface {
+	CreatePersonalToken(token *model.PersonalAccessToken) (string, error)
+	GetPersonalTokenByToken(plainToken string) *model.PersonalAccessToken
+	GetListPersonalToken(userID uint) ([]model.PersonalAccessToken, error)
+	DeletePersonalToken(userID uint, id uint) (bool, error)
+	TouchPersonalToken(id uint) error
+}
+
+func NewPersonalTokenRepository() IPersonalTokenRepository {
+	return &personalTokenRepository{db: global.Postgres}
+}
+
+type personalTokenRepository struct {
+	db *gorm.DB
+}
+
+// CreatePersonalToken generates the secret token, stores its hash and returns it.
+// This is the only time the token is available in clear
+func (r *personalTokenRepository) CreatePersonalToken(token *model.PersonalAccessToken) (string, error) {
+	secret, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	plain := model.PersonalTokenPrefix + secret
+	token.TokenPrefix = plain[:personalTokenDisplayLength]
+	token.TokenHash = hashToken(plain)
+	if err := r.db.Create(token).Error; err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+func (r *personalTokenRepository) GetPersonalTokenByToken(plainToken string) *model.PersonalAccessToken {
+	var token model.PersonalAccessToken
+	err := r.db.Where("token_hash = ?", hashToken(plainToken)).First(&token).Error
+	if err != nil {
+		return nil
+	}
+	return &token
+}
+
+func (r *personalTokenRepository) GetListPersonalToken(userID uint) ([]model.PersonalAccessToken, error) {
+	var tokens []model.PersonalAccessToken
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// DeletePersonalToken deletes a token of the user, false if the user has no such token
+func (r *personalTokenRepository) DeletePersonalToken(userID uint, id uint) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.PersonalAccessToken{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *personalTokenRepository) TouchPersonalToken(id uint) error {
+	return r.db.Model(&model.PersonalAccessToken{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
+}
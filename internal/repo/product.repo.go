@@ -25,6 +25,7 @@ import (
 
 type IProductRepository interface {
 	FindByID(id uint) (*model.Product, error)
+	FindByPublicID(publicID string) (*model.Product, error)
 	FindAll() ([]model.Product, error)
 	Create(product *model.Product) (*model.Product, error)
 }
@@ -48,6 +49,17 @@ func (pr *ProductRepository) FindByID(id uint) (*model.Product, error) {
 	return &product, nil
 }
 
+func (pr *ProductRepository) FindByPublicID(publicID string) (*model.Product, error) {
+	var product model.Product
+	if err := pr.db.Where("public_id = ?", publicID).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
 func (pr *ProductRepository) FindAll() ([]model.Product, error) {
 	var products []model.Product
 	if err := pr.db.Preload("User").Find(&products).Error; err != nil {
@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"base_go_be/global"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const rateLimitKey = "rate_limit:%s"
+
+type IRateLimitRepository interface {
+	Allow(key string, limit int, window time.Duration) (bool, error)
+}
+
+func NewRateLimitRepository() IRateLimitRepository {
+	return &rateLimitRepository{rdb: global.Redis}
+}
+
+type rateLimitRepository struct {
+	rdb *redis.Client
+}
+
+// Allow counts a hit on key and reports whether it's within limit hits for the
+// current fixed window
+func (r *rateLimitRepository) Allow(key string, limit int, window time.Duration) (bool, error) {
+	k := fmt.Sprintf(rateLimitKey, key)
+	count, err := r.rdb.Incr(ctx, k).Result()
+	if err != nil {
+		return false, err
+	}
+	if count == 1 {
+		if err := r.rdb.Expire(ctx, k, window).Err(); err != nil {
+			return false, err
+		}
+	}
+	return count <= int64(limit), nil
+}
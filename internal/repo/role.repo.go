@@ -0,0 +1,116 @@
+package repo
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	rolePermissionsKey = "role_permissions:%s" // role name -> JSON list of permission names
+	rolePermissionsTTL = 10 * time.Minute
+)
+
+type IRoleRepository interface {
+	GetListRole() ([]model.Role, error)
+	GetRoleByName(name string) *model.Role
+	CreateRole(role *model.Role) error
+	GetListPermission() ([]model.Permission, error)
+	GetPermissionsByNames(names []string) ([]model.Permission, error)
+	SetRolePermissions(role *model.Role, permissions []model.Permission) error
+	GetRolePermissions(roleName string) ([]string, error)
+}
+
+func NewRoleRepository() IRoleRepository {
+	return &roleRepository{db: global.Postgres, rdb: global.Redis}
+}
+
+type roleRepository struct {
+	db  *gorm.DB
+	rdb *redis.Client
+}
+
+func (r *roleRepository) GetListRole() ([]model.Role, error) {
+	var roles []model.Role
+	if err := r.db.Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *roleRepository) GetRoleByName(name string) *model.Role {
+	var role model.Role
+	err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		return nil
+	}
+	return &role
+}
+
+func (r *roleRepository) CreateRole(role *model.Role) error {
+	return r.db.Create(role).Error
+}
+
+func (r *roleRepository) GetListPermission() ([]model.Permission, error) {
+	var permissions []model.Permission
+	if err := r.db.Order("name").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (r *roleRepository) GetPermissionsByNames(names []string) ([]model.Permission, error) {
+	var permissions []model.Permission
+	if len(names) == 0 {
+		return permissions, nil
+	}
+	if err := r.db.Where("name IN ?", names).Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// SetRolePermissions replaces the permissions of the role and drops the cached copy
+func (r *roleRepository) SetRolePermissions(role *model.Role, permissions []model.Permission) error {
+	if err := r.db.Model(role).Association("Permissions").Replace(permissions); err != nil {
+		return err
+	}
+	return r.rdb.Del(ctx, fmt.Sprintf(rolePermissionsKey, role.Name)).Err()
+}
+
+// GetRolePermissions returns the permission names of the role, cached in Redis since
+// it's looked up on every authenticated request
+func (r *roleRepository) GetRolePermissions(roleName string) ([]string, error) {
+	key := fmt.Sprintf(rolePermissionsKey, roleName)
+	cached, err := r.rdb.Get(ctx, key).Bytes()
+	if err == nil {
+		var permissions []string
+		if err := json.Unmarshal(cached, &permissions); err == nil {
+			return permissions, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		global.Logger.Warn("Failed to read cached role permissions: " + err.Error())
+	}
+
+	permissions := []string{}
+	err = r.db.Table("role_permissions").
+		Where("role_name = ?", roleName).
+		Order("permission_name").
+		Pluck("permission_name", &permissions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(permissions); err == nil {
+		if err := r.rdb.Set(ctx, key, data, rolePermissionsTTL).Err(); err != nil {
+			global.Logger.Warn("Failed to cache role permissions: " + err.Error())
+		}
+	}
+	return permissions, nil
+}
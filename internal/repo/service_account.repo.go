@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/model"
+	"base_go_be/pkg/secretbox"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IServiceAccountRepository interface {
+	GetListServiceAccount() ([]model.ServiceAccount, error)
+	GetServiceAccountByID(id uint) *model.ServiceAccount
+	GetServiceAccountByName(name string) *model.ServiceAccount
+	CreateServiceAccount(account *model.ServiceAccount) error
+	UpdateServiceAccount(account *model.ServiceAccount) error
+	CreateServiceAccountKey(key *model.ServiceAccountKey) (string, error)
+	GetServiceAccountKey(keyID string) *model.ServiceAccountKey
+	DeleteServiceAccountKey(serviceAccountID uint, keyID string) (bool, error)
+	TouchServiceAccountKey(id uint) error
+}
+
+func NewServiceAccountRepository() IServiceAccountRepository {
+	return &serviceAccountRepository{db: global.Postgres}
+}
+
+type serviceAccountRepository struct {
+	db *gorm.DB
+}
+
+func (r *serviceAccountRepository) GetListServiceAccount() ([]model.ServiceAccount, error) {
+	var accounts []model.ServiceAccount
+	if err := r.db.Preload("Keys").Preload("Owner").Order("id").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *serviceAccountRepository) GetServiceAccountByID(id uint) *model.ServiceAccount {
+	var account model.ServiceAccount
+	if err := r.db.Preload("Keys").Preload("Owner").First(&account, id).Error; err != nil {
+		return nil
+	}
+	return &account
+}
+
+func (r *serviceAccountRepository) GetServiceAccountByName(name string) *model.ServiceAccount {
+	var account model.ServiceAccount
+	if err := r.db.Where("name = ?", name).First(&account).Error; err != nil {
+		return nil
+	}
+	return &account
+}
+
+// CreateServiceAccount inserts the account and loads its owner
+func (r *serviceAccountRepository) CreateServiceAccount(account *model.ServiceAccount) error {
+	if err := r.db.Create(account).Error; err != nil {
+		return err
+	}
+	return r.db.Preload("Owner").First(account, account.ID).Error
+}
+
+// UpdateServiceAccount saves the editable fields of the account
+func (r *serviceAccountRepository) UpdateServiceAccount(account *model.ServiceAccount) error {
+	return r.db.Model(account).Select("Description", "Role", "IsActive").Updates(account).Error
+}
+
+// CreateServiceAccountKey generates the key ID and secret, stores the secret encrypted
+// and returns it. This is the only time the secret is available in clear
+func (r *serviceAccountRepository) CreateServiceAccountKey(key *model.ServiceAccountKey) (string, error) {
+	id, err := randomID()
+	if err != nil {
+		return "", err
+	}
+	secret, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := secretbox.Encrypt(global.Config.Security.EncryptionKey, secret)
+	if err != nil {
+		return "", err
+	}
+	key.KeyID = model.ServiceAccountKeyPrefix + id
+	key.Secret = encrypted
+	if err := r.db.Create(key).Error; err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// GetServiceAccountKey finds a key with its account. Secret is still encrypted
+func (r *serviceAccountRepository) GetServiceAccountKey(keyID string) *model.ServiceAccountKey {
+	var key model.ServiceAccountKey
+	if err := r.db.Preload("ServiceAccount").Where("key_id = ?", keyID).First(&key).Error; err != nil {
+		return nil
+	}
+	return &key
+}
+
+// DeleteServiceAccountKey deletes a key of the account, false if it has no such key
+func (r *serviceAccountRepository) DeleteServiceAccountKey(serviceAccountID uint, keyID string) (bool, error) {
+	result := r.db.Where("key_id = ? AND service_account_id = ?", keyID, serviceAccountID).Delete(&model.ServiceAccountKey{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *serviceAccountRepository) TouchServiceAccountKey(id uint) error {
+	return r.db.Model(&model.ServiceAccountKey{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
+}
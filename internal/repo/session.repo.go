@@ -0,0 +1,206 @@
+package repo
+
+import (
+	"base_go_be/global"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	sessionKey      = "session:%s"       // session ID (= refresh token family ID) -> Session JSON
+	userSessionsKey = "user_sessions:%d" // user ID -> set of session IDs
+	// Impersonations not ended yet, by expiry time, and their session kept past its own
+	// expiry so that the end can be recorded
+	impersonationsKey = "impersonations"
+	impersonationKey  = "impersonation:%s" // session ID -> Session JSON
+)
+
+// Session is a login on one device. Its ID is the refresh token family ID, so
+// ending the session and revoking its refresh tokens go together
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     uint      `json:"user_id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// Admin acting as the user, for sessions started by impersonation
+	ImpersonatorID uint `json:"impersonator_id,omitempty"`
+}
+
+type ISessionRepository interface {
+	CreateSession(session *Session, ttl time.Duration) error
+	GetSession(sessionID string) (*Session, error)
+	TouchSession(session *Session, ip string, ttl time.Duration) error
+	ListUserSessions(userID uint) ([]Session, error)
+	DeleteSession(userID uint, sessionID string) error
+	TrackImpersonation(session *Session, expiresAt time.Time) error
+	EndImpersonation(sessionID string) (*Session, error)
+	ExpiredImpersonations(before time.Time) ([]string, error)
+}
+
+func NewSessionRepository() ISessionRepository {
+	return &sessionRepository{rdb: global.Redis}
+}
+
+type sessionRepository struct {
+	rdb *redis.Client
+}
+
+// CreateSession stores a new session and fills its ID, timestamps included
+func (r *sessionRepository) CreateSession(session *Session, ttl time.Duration) error {
+	sessionID, err := randomID()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	session.ID = sessionID
+	session.CreatedAt = now
+	session.LastSeenAt = now
+	return r.saveSession(session, ttl)
+}
+
+// GetSession returns the session, or nil if it ended or expired
+func (r *sessionRepository) GetSession(sessionID string) (*Session, error) {
+	data, err := r.rdb.Get(ctx, fmt.Sprintf(sessionKey, sessionID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// TouchSession records activity on the session. A ttl > 0 extends its lifetime,
+// as done when its refresh token is rotated. A session ended meanwhile stays ended
+func (r *sessionRepository) TouchSession(session *Session, ip string, ttl time.Duration) error {
+	session.LastSeenAt = time.Now()
+	if ip != "" {
+		session.IP = ip
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		err = r.rdb.SetArgs(ctx, fmt.Sprintf(sessionKey, session.ID), data, redis.SetArgs{KeepTTL: true, Mode: "XX"}).Err()
+	} else {
+		setKey := fmt.Sprintf(userSessionsKey, session.UserID)
+		pipe := r.rdb.TxPipeline()
+		pipe.SetArgs(ctx, fmt.Sprintf(sessionKey, session.ID), data, redis.SetArgs{TTL: ttl, Mode: "XX"})
+		pipe.ExpireGT(ctx, setKey, ttl)
+		pipe.ExpireNX(ctx, setKey, ttl)
+		_, err = pipe.Exec(ctx)
+	}
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+// ListUserSessions returns the live sessions of the user, most recently used first.
+// Sessions that expired on their own are dropped from the user's set on the way
+func (r *sessionRepository) ListUserSessions(userID uint) ([]Session, error) {
+	setKey := fmt.Sprintf(userSessionsKey, userID)
+	sessionIDs, err := r.rdb.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		session, err := r.GetSession(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if session == nil {
+			r.rdb.SRem(ctx, setKey, sessionID)
+			continue
+		}
+		sessions = append(sessions, *session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	return sessions, nil
+}
+
+func (r *sessionRepository) DeleteSession(userID uint, sessionID string) error {
+	pipe := r.rdb.TxPipeline()
+	pipe.Del(ctx, fmt.Sprintf(sessionKey, sessionID))
+	pipe.SRem(ctx, fmt.Sprintf(userSessionsKey, userID), sessionID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// TrackImpersonation records an impersonation session as ongoing until expiresAt
+func (r *sessionRepository) TrackImpersonation(session *Session, expiresAt time.Time) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.rdb.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf(impersonationKey, session.ID), data, 0)
+	pipe.ZAdd(ctx, impersonationsKey, redis.Z{Score: float64(expiresAt.Unix()), Member: session.ID})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// EndImpersonation marks the impersonation ended and returns its session, or nil when
+// it was already ended, so only one caller records the end
+func (r *sessionRepository) EndImpersonation(sessionID string) (*Session, error) {
+	removed, err := r.rdb.ZRem(ctx, impersonationsKey, sessionID).Result()
+	if err != nil || removed == 0 {
+		return nil, err
+	}
+
+	data, err := r.rdb.GetDel(ctx, fmt.Sprintf(impersonationKey, sessionID)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ExpiredImpersonations returns the sessions of the impersonations that expired before
+// the time given without being ended
+func (r *sessionRepository) ExpiredImpersonations(before time.Time) ([]string, error) {
+	return r.rdb.ZRangeByScore(ctx, impersonationsKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(before.Unix(), 10),
+	}).Result()
+}
+
+func (r *sessionRepository) saveSession(session *Session, ttl time.Duration) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	setKey := fmt.Sprintf(userSessionsKey, session.UserID)
+	pipe := r.rdb.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf(sessionKey, session.ID), data, ttl)
+	pipe.SAdd(ctx, setKey, session.ID)
+	// The set lives as long as the longest session in it
+	pipe.ExpireGT(ctx, setKey, ttl)
+	pipe.ExpireNX(ctx, setKey, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"base_go_be/global"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	signatureUsedKey = "signature_used:%s:%s"
+	keyUsageTotalKey = "api_key_usage:%s:total"
+	keyUsageDayKey   = "api_key_usage:%s:%s"
+
+	// keyUsageDayTTL keeps daily counters for about a month
+	keyUsageDayTTL = 32 * 24 * time.Hour
+)
+
+// KeyUsage counts the requests signed with an API key
+type KeyUsage struct {
+	Total int64
+	Today int64
+}
+
+type ISignedRequestRepository interface {
+	MarkSignatureUsed(keyID string, signature string, ttl time.Duration) (bool, error)
+	RecordKeyUsage(keyID string) error
+	GetKeyUsage(keyID string) (*KeyUsage, error)
+	DeleteKeyUsage(keyID string) error
+}
+
+func NewSignedRequestRepository() ISignedRequestRepository {
+	return &signedRequestRepository{rdb: global.Redis}
+}
+
+type signedRequestRepository struct {
+	rdb *redis.Client
+}
+
+// MarkSignatureUsed remembers a request signature for ttl, false if it was already
+// seen, i.e. the request is replayed
+func (r *signedRequestRepository) MarkSignatureUsed(keyID string, signature string, ttl time.Duration) (bool, error) {
+	return r.rdb.SetNX(ctx, fmt.Sprintf(signatureUsedKey, keyID, signature), 1, ttl).Result()
+}
+
+// RecordKeyUsage counts a request in the key's total and today's counter (UTC)
+func (r *signedRequestRepository) RecordKeyUsage(keyID string) error {
+	dayKey := fmt.Sprintf(keyUsageDayKey, keyID, time.Now().UTC().Format(time.DateOnly))
+	pipe := r.rdb.TxPipeline()
+	pipe.Incr(ctx, fmt.Sprintf(keyUsageTotalKey, keyID))
+	pipe.Incr(ctx, dayKey)
+	pipe.Expire(ctx, dayKey, keyUsageDayTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *signedRequestRepository) GetKeyUsage(keyID string) (*KeyUsage, error) {
+	dayKey := fmt.Sprintf(keyUsageDayKey, keyID, time.Now().UTC().Format(time.DateOnly))
+	values, err := r.rdb.MGet(ctx, fmt.Sprintf(keyUsageTotalKey, keyID), dayKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	return &KeyUsage{Total: counterValue(values[0]), Today: counterValue(values[1])}, nil
+}
+
+// DeleteKeyUsage drops the total counter of a revoked key, daily ones expire by themselves
+func (r *signedRequestRepository) DeleteKeyUsage(keyID string) error {
+	return r.rdb.Del(ctx, fmt.Sprintf(keyUsageTotalKey, keyID)).Err()
+}
+
+// counterValue reads a counter returned by MGET, missing counters being 0
+func counterValue(value interface{}) int64 {
+	s, _ := value.(string)
+	count, _ := strconv.ParseInt(s, 10, 64)
+	return count
+}
@@ -0,0 +1,258 @@
+package repo
+
+import (
+	"base_go_be/global"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ctx = context.Background()
+
+const (
+	refreshTokenKey  = "refresh_token:%s"    // refresh token hash -> family ID
+	refreshFamilyKey = "refresh_family:%s"   // family ID -> hash of the current refresh token
+	revokedTokenKey  = "revoked_token:%s"    // jti of a revoked token
+	revokedUserKey   = "revoked_user:%d"     // user ID -> unix time before which all tokens are revoked
+	wsTicketKey      = "ws_ticket:%s"        // one-time WebSocket ticket hash -> access token
+	oneTimeTokenKey  = "one_time:%s:%s"      // purpose, token hash -> OneTimeToken JSON
+	oneTimeUserKey   = "one_time:%s:user:%d" // purpose, user ID -> hash of the user's outstanding token
+)
+
+// Purposes of one-time tokens sent by email
+const (
+	PurposePasswordReset     = "password_reset"
+	PurposeEmailVerification = "email_verification"
+	PurposeEmailChange       = "email_change"
+)
+
+// OneTimeToken is what a single-use emailed token stands for
+type OneTimeToken struct {
+	UserID uint   `json:"user_id"`
+	Data   string `json:"data,omitempty"`
+}
+
+// rotateRefreshScript swaps the current token of a family only if the presented
+// token is still the current one, so two concurrent refreshes can't both win
+var rotateRefreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[4])
+redis.call("SET", KEYS[2], ARGV[3], "PX", ARGV[4])
+return 1
+`)
+
+type ITokenRepository interface {
+	CreateRefreshFamily(familyID string, refreshToken string, ttl time.Duration) error
+	GetRefreshFamilyID(refreshToken string) (string, error)
+	RotateRefreshToken(familyID string, oldToken string, newToken string, ttl time.Duration) (bool, error)
+	RevokeRefreshFamily(familyID string) error
+	RevokeToken(tokenID string, expiresAt time.Time) error
+	ConsumeToken(tokenID string, expiresAt time.Time) (bool, error)
+	RevokeUserTokens(userID uint, ttl time.Duration) error
+	IsTokenRevoked(tokenID string, userID uint, issuedAt time.Time) (bool, error)
+	CreateWebSocketTicket(accessToken string, ttl time.Duration) (string, error)
+	ConsumeWebSocketTicket(ticket string) (string, error)
+	CreateOneTimeToken(purpose string, token OneTimeToken, ttl time.Duration) (string, error)
+	ConsumeOneTimeToken(purpose string, token string) (*OneTimeToken, error)
+}
+
+func NewTokenRepository() ITokenRepository {
+	return &tokenRepository{rdb: global.Redis}
+}
+
+type tokenRepository struct {
+	rdb *redis.Client
+}
+
+// CreateRefreshFamily starts a token family with refreshToken as its current token.
+// The family ID is the ID of the login session the tokens belong to
+func (r *tokenRepository) CreateRefreshFamily(familyID string, refreshToken string, ttl time.Duration) error {
+	tokenHash := hashToken(refreshToken)
+	pipe := r.rdb.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf(refreshFamilyKey, familyID), tokenHash, ttl)
+	pipe.Set(ctx, fmt.Sprintf(refreshTokenKey, tokenHash), familyID, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetRefreshFamilyID returns the family a refresh token was issued in, or "" if unknown
+func (r *tokenRepository) GetRefreshFamilyID(refreshToken string) (string, error) {
+	familyID, err := r.rdb.Get(ctx, fmt.Sprintf(refreshTokenKey, hashToken(refreshToken))).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return familyID, err
+}
+
+// RotateRefreshToken replaces oldToken with newToken as the current token of the family.
+// It returns false when oldToken is no longer current (already used) or the family was revoked.
+func (r *tokenRepository) RotateRefreshToken(familyID string, oldToken string, newToken string, ttl time.Duration) (bool, error) {
+	newHash := hashToken(newToken)
+	keys := []string{
+		fmt.Sprintf(refreshFamilyKey, familyID),
+		fmt.Sprintf(refreshTokenKey, newHash),
+	}
+	rotated, err := rotateRefreshScript.Run(ctx, r.rdb, keys, hashToken(oldToken), newHash, familyID, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return rotated == 1, nil
+}
+
+// RevokeRefreshFamily invalidates every refresh token issued in the family
+func (r *tokenRepository) RevokeRefreshFamily(familyID string) error {
+	return r.rdb.Del(ctx, fmt.Sprintf(refreshFamilyKey, familyID)).Err()
+}
+
+// RevokeToken denylists a single token until it would have expired anyway
+func (r *tokenRepository) RevokeToken(tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return r.rdb.Set(ctx, fmt.Sprintf(revokedTokenKey, tokenID), 1, ttl).Err()
+}
+
+// ConsumeToken revokes a single-use token, returning false if it was already used
+// (or revoked), so only one of two concurrent redemptions wins
+func (r *tokenRepository) ConsumeToken(tokenID string, expiresAt time.Time) (bool, error) {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return false, nil
+	}
+	return r.rdb.SetNX(ctx, fmt.Sprintf(revokedTokenKey, tokenID), 1, ttl).Result()
+}
+
+// RevokeUserTokens revokes every token issued to the user up to now. ttl must cover
+// the lifetime of the longest-lived token
+func (r *tokenRepository) RevokeUserTokens(userID uint, ttl time.Duration) error {
+	return r.rdb.Set(ctx, fmt.Sprintf(revokedUserKey, userID), time.Now().Unix(), ttl).Err()
+}
+
+// IsTokenRevoked checks both the token denylist and the user's revocation timestamp
+func (r *tokenRepository) IsTokenRevoked(tokenID string, userID uint, issuedAt time.Time) (bool, error) {
+	values, err := r.rdb.MGet(ctx, fmt.Sprintf(revokedTokenKey, tokenID), fmt.Sprintf(revokedUserKey, userID)).Result()
+	if err != nil {
+		return false, err
+	}
+	if values[0] != nil {
+		return true, nil
+	}
+	if values[1] != nil {
+		revokedAt, err := strconv.ParseInt(values[1].(string), 10, 64)
+		if err != nil {
+			return false, err
+		}
+		// iat only has second precision, so a token issued in the same second is revoked too
+		if issuedAt.Unix() <= revokedAt {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CreateWebSocketTicket issues a short-lived ticket standing in for the access token,
+// for clients that can't set headers on the WebSocket handshake
+func (r *tokenRepository) CreateWebSocketTicket(accessToken string, ttl time.Duration) (string, error) {
+	ticket, err := randomID()
+	if err != nil {
+		return "", err
+	}
+	if err := r.rdb.Set(ctx, fmt.Sprintf(wsTicketKey, hashToken(ticket)), accessToken, ttl).Err(); err != nil {
+		return "", err
+	}
+	return ticket, nil
+}
+
+// ConsumeWebSocketTicket returns the access token behind the ticket and deletes it,
+// or "" if the ticket is unknown, expired or already used
+func (r *tokenRepository) ConsumeWebSocketTicket(ticket string) (string, error) {
+	accessToken, err := r.rdb.GetDel(ctx, fmt.Sprintf(wsTicketKey, hashToken(ticket))).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return accessToken, err
+}
+
+// CreateOneTimeToken issues a random single-use token for the purpose. Only its hash is
+// stored, and any token the user still had for the same purpose stops working
+func (r *tokenRepository) CreateOneTimeToken(purpose string, token OneTimeToken, ttl time.Duration) (string, error) {
+	plain, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+
+	userKey := fmt.Sprintf(oneTimeUserKey, purpose, token.UserID)
+	previous, err := r.rdb.Get(ctx, userKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+
+	tokenHash := hashToken(plain)
+	pipe := r.rdb.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, fmt.Sprintf(oneTimeTokenKey, purpose, previous))
+	}
+	pipe.Set(ctx, fmt.Sprintf(oneTimeTokenKey, purpose, tokenHash), data, ttl)
+	pipe.Set(ctx, userKey, tokenHash, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// ConsumeOneTimeToken redeems the token, or returns nil if it's unknown, expired or
+// was already used
+func (r *tokenRepository) ConsumeOneTimeToken(purpose string, token string) (*OneTimeToken, error) {
+	data, err := r.rdb.GetDel(ctx, fmt.Sprintf(oneTimeTokenKey, purpose, hashToken(token))).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var oneTimeToken OneTimeToken
+	if err := json.Unmarshal(data, &oneTimeToken); err != nil {
+		return nil, err
+	}
+	r.rdb.Del(ctx, fmt.Sprintf(oneTimeUserKey, purpose, oneTimeToken.UserID))
+	return &oneTimeToken, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken returns a URL-safe token with 256 bits of entropy, for tokens sent to users
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -4,6 +4,7 @@ import (
 	"base_go_be/global"
 	"base_go_be/internal/dto"
 	"base_go_be/internal/model"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,9 +12,18 @@ import (
 type IUserRepository interface {
 	GetUserByEmail(email string) *model.User
 	GetUserByID(id uint) *model.User
+	GetUserByPublicID(publicID string) *model.User
+	GetUserByUsername(username string) *model.User
 	GetListUser(req dto.UserListRequestDto) ([]*model.User, int64, error)
 	CreateUser(user *model.User) (uint, error)
 	UpdateUser(id uint, user *model.User) (*model.User, error)
+	SetUserActive(id uint, active bool) error
+	UpdateUserFields(id uint, user *model.User, fields ...string) error
+	DeleteUser(id uint) error
+	GetDeletedUserByPublicID(publicID string) *model.User
+	RestoreUser(id uint) error
+	GetUsersDeletedBefore(before time.Time) ([]*model.User, error)
+	PurgeUser(id uint) error
 }
 
 func NewUserRepository() IUserRepository {
@@ -42,6 +52,24 @@ func (r *userRepository) GetUserByID(id uint) *model.User {
 	return &user
 }
 
+func (r *userRepository) GetUserByPublicID(publicID string) *model.User {
+	var user model.User
+	err := r.db.Where("public_id = ?", publicID).First(&user).Error
+	if err != nil {
+		return nil
+	}
+	return &user
+}
+
+func (r *userRepository) GetUserByUsername(username string) *model.User {
+	var user model.User
+	err := r.db.Where("username = ?", username).First(&user).Error
+	if err != nil {
+		return nil
+	}
+	return &user
+}
+
 func (r *userRepository) GetListUser(req dto.UserListRequestDto) ([]*model.User, int64, error) {
 	var users []*model.User
 	var total int64
@@ -80,3 +108,53 @@ func (r *userRepository) UpdateUser(id uint, user *model.User) (*model.User, err
 
 	return &updatedUser, nil
 }
+
+// SetUserActive deactivates or reactivates the user. UpdateUser can't, as it skips false
+func (r *userRepository) SetUserActive(id uint, active bool) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Update("is_active", active).Error
+}
+
+// UpdateUserFields saves the named fields of user, zero values included, and bumps updated_at
+func (r *userRepository) UpdateUserFields(id uint, user *model.User, fields ...string) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Select(append(fields, "updated_at")).Updates(user).Error
+}
+
+// DeleteUser soft deletes the user, who disappears from every other lookup
+func (r *userRepository) DeleteUser(id uint) error {
+	return r.db.Delete(&model.User{}, id).Error
+}
+
+func (r *userRepository) GetDeletedUserByPublicID(publicID string) *model.User {
+	var user model.User
+	err := r.db.Unscoped().Where("public_id = ? AND deleted_at IS NOT NULL", publicID).First(&user).Error
+	if err != nil {
+		return nil
+	}
+	return &user
+}
+
+func (r *userRepository) RestoreUser(id uint) error {
+	return r.db.Unscoped().Model(&model.User{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+func (r *userRepository) GetUsersDeletedBefore(before time.Time) ([]*model.User, error) {
+	var users []*model.User
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Find(&users).Error
+	return users, err
+}
+
+// PurgeUser removes the user for good. Their products are kept without an owner, and
+// their 2FA, identities, passkeys and tokens go with them (ON DELETE CASCADE)
+func (r *userRepository) PurgeUser(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Product{}).Where("user_id = ?", id).Update("user_id", nil).Error; err != nil {
+			return err
+		}
+		// Service accounts act as their owner and stopped working when the owner was
+		// deleted. Their keys go with them
+		if err := tx.Where("owner_user_id = ?", id).Delete(&model.ServiceAccount{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&model.User{}, id).Error
+	})
+}
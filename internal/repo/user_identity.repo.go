@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const oidcStateKey = "oidc_state:%s"
+
+// OidcState is what an external login needs to remember between redirecting the user
+// to the provider and the provider sending them back
+type OidcState struct {
+	Provider string `json:"provider"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"` // PKCE code verifier
+}
+
+type IUserIdentityRepository interface {
+	GetUserIdentity(provider string, subject string) *model.UserIdentity
+	CreateUserIdentity(identity *model.UserIdentity) error
+	CreateOidcState(state *OidcState, ttl time.Duration) (string, error)
+	ConsumeOidcState(stateID string) (*OidcState, error)
+}
+
+func NewUserIdentityRepository() IUserIdentityRepository {
+	return &userIdentityRepository{db: global.Postgres, rdb: global.Redis}
+}
+
+type userIdentityRepository struct {
+	db  *gorm.DB
+	rdb *redis.Client
+}
+
+func (r *userIdentityRepository) GetUserIdentity(provider string, subject string) *model.UserIdentity {
+	var identity model.UserIdentity
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil
+	}
+	return &identity
+}
+
+func (r *userIdentityRepository) CreateUserIdentity(identity *model.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+// CreateOidcState stores the state under a random ID, sent to the provider as the
+// OAuth2 "state" parameter
+func (r *userIdentityRepository) CreateOidcState(state *OidcState, ttl time.Duration) (string, error) {
+	stateID, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	if err := r.rdb.Set(ctx, fmt.Sprintf(oidcStateKey, stateID), data, ttl).Err(); err != nil {
+		return "", err
+	}
+	return stateID, nil
+}
+
+// ConsumeOidcState returns the state and deletes it, so a callback can't be replayed.
+// nil if it doesn't exist or has expired
+func (r *userIdentityRepository) ConsumeOidcState(stateID string) (*OidcState, error) {
+	data, err := r.rdb.GetDel(ctx, fmt.Sprintf(oidcStateKey, stateID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state OidcState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
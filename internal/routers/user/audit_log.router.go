@@ -0,0 +1,22 @@
+package user
+
+import (
+	"base_go_be/internal/middlewares"
+	"base_go_be/internal/model"
+	"base_go_be/internal/wire"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogRouter struct{}
+
+func (ar *AuditLogRouter) InitAuditLogRouter(Router *gin.RouterGroup) {
+	auditLogController, _ := wire.InitAuditLogRouterHandler()
+
+	// admin router - reading the audit log
+	auditLogRouterAdmin := Router.Group("/admin/audit_logs")
+	auditLogRouterAdmin.Use(middlewares.AuthMiddleware(), middlewares.RequireUserSession(), middlewares.RejectImpersonation(), middlewares.RequirePermission(model.PermissionAuditLogRead))
+	{
+		auditLogRouterAdmin.GET("", auditLogController.GetListAuditLog)
+	}
+}
@@ -3,4 +3,9 @@ package user
 type UsersRouterGroup struct {
 	UsersRouter
 	ProductRouter
+	RoleRouter
+	PersonalTokenRouter
+	ServiceAccountRouter
+	AuditLogRouter
+	ProfileRouter
 }
@@ -0,0 +1,23 @@
+package user
+
+import (
+	"base_go_be/internal/middlewares"
+	"base_go_be/internal/wire"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PersonalTokenRouter struct{}
+
+func (pr *PersonalTokenRouter) InitPersonalTokenRouter(Router *gin.RouterGroup) {
+	personalTokenController, _ := wire.InitPersonalTokenRouterHandler()
+
+	// private router - managing tokens needs a real login, not a token
+	personalTokenRouterPrivate := Router.Group("/user/tokens")
+	personalTokenRouterPrivate.Use(middlewares.AuthMiddleware(), middlewares.RequireUserSession(), middlewares.RejectImpersonation())
+	{
+		personalTokenRouterPrivate.GET("", personalTokenController.GetListPersonalToken)
+		personalTokenRouterPrivate.POST("", personalTokenController.CreatePersonalToken)
+		personalTokenRouterPrivate.DELETE("/:id", personalTokenController.DeletePersonalToken)
+	}
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"base_go_be/internal/middlewares"
+	"base_go_be/internal/model"
 	"base_go_be/internal/wire"
 	"github.com/gin-gonic/gin"
 )
@@ -14,9 +15,9 @@ func (pr *ProductRouter) InitProductRouter(Router *gin.RouterGroup) {
 	productRouterPublic := Router.Group("/product")
 	productRouterPublic.Use(middlewares.AuthMiddleware())
 	{
-		productRouterPublic.GET("/detail/:id", productController.GetProductByID)
-		productRouterPublic.GET("/list", productController.GetListProduct)
-		productRouterPublic.POST("/create", productController.CreateProduct)
+		productRouterPublic.GET("/detail/:id", middlewares.RequirePermission(model.PermissionProductRead), productController.GetProductByID)
+		productRouterPublic.GET("/list", middlewares.RequirePermission(model.PermissionProductRead), productController.GetListProduct)
+		productRouterPublic.POST("/create", middlewares.RequirePermission(model.PermissionProductWrite), productController.CreateProduct)
 	}
 
 	//private router
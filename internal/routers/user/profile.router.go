@@ -0,0 +1,24 @@
+package user
+
+import (
+	"base_go_be/internal/middlewares"
+	"base_go_be/internal/wire"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileRouter struct{}
+
+func (pr *ProfileRouter) InitProfileRouter(Router *gin.RouterGroup) {
+	profileController, _ := wire.InitProfileRouterHandler()
+
+	// private router
+	profileRouterPrivate := Router.Group("/user/me")
+	profileRouterPrivate.Use(middlewares.AuthMiddleware(), middlewares.RequireUserSession())
+	{
+		profileRouterPrivate.GET("/profile", profileController.GetProfile)
+		profileRouterPrivate.PATCH("/profile", profileController.UpdateProfile)
+		profileRouterPrivate.PUT("/avatar", profileController.UploadAvatar)
+		profileRouterPrivate.DELETE("/avatar", profileController.DeleteAvatar)
+	}
+}
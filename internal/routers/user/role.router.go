@@ -0,0 +1,25 @@
+package user
+
+import (
+	"base_go_be/internal/middlewares"
+	"base_go_be/internal/model"
+	"base_go_be/internal/wire"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleRouter struct{}
+
+func (rr *RoleRouter) InitRoleRouter(Router *gin.RouterGroup) {
+	roleController, _ := wire.InitRoleRouterHandler()
+
+	// admin router - role and permission management
+	roleRouterAdmin := Router.Group("/admin")
+	roleRouterAdmin.Use(middlewares.AuthMiddleware(), middlewares.RequireUserSession(), middlewares.RejectImpersonation(), middlewares.RequirePermission(model.PermissionRoleManage))
+	{
+		roleRouterAdmin.GET("/list_role", roleController.GetListRole)
+		roleRouterAdmin.GET("/list_permission", roleController.GetListPermission)
+		roleRouterAdmin.POST("/create_role", roleController.CreateRole)
+		roleRouterAdmin.PUT("/update_role_permissions/:name", roleController.UpdateRolePermissions)
+	}
+}
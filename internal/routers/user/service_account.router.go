@@ -0,0 +1,26 @@
+package user
+
+import (
+	"base_go_be/internal/middlewares"
+	"base_go_be/internal/model"
+	"base_go_be/internal/wire"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ServiceAccountRouter struct{}
+
+func (sr *ServiceAccountRouter) InitServiceAccountRouter(Router *gin.RouterGroup) {
+	serviceAccountController, _ := wire.InitServiceAccountRouterHandler()
+
+	// admin router - service accounts and their API keys
+	serviceAccountRouterAdmin := Router.Group("/admin/service_accounts")
+	serviceAccountRouterAdmin.Use(middlewares.AuthMiddleware(), middlewares.RequireUserSession(), middlewares.RejectImpersonation(), middlewares.RequirePermission(model.PermissionServiceAccountManage))
+	{
+		serviceAccountRouterAdmin.GET("", serviceAccountController.GetListServiceAccount)
+		serviceAccountRouterAdmin.POST("", serviceAccountController.CreateServiceAccount)
+		serviceAccountRouterAdmin.PUT("/:id", serviceAccountController.UpdateServiceAccount)
+		serviceAccountRouterAdmin.POST("/:id/keys", serviceAccountController.CreateServiceAccountKey)
+		serviceAccountRouterAdmin.DELETE("/:id/keys/:key_id", serviceAccountController.DeleteServiceAccountKey)
+	}
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"base_go_be/internal/middlewares"
+	"base_go_be/internal/model"
 	"base_go_be/internal/wire"
 
 	"github.com/gin-gonic/gin"
@@ -18,23 +19,79 @@ func (pr *UsersRouter) InitUserRouter(Router *gin.RouterGroup) {
 	{
 		usersRouterPublic.POST("/login", userController.Login)
 		usersRouterPublic.POST("/register", userController.Register)
+		usersRouterPublic.POST("/login/magic", userController.RequestMagicLink)
+		usersRouterPublic.POST("/login/magic/confirm", userController.LoginMagicLink)
+		usersRouterPublic.POST("/login_mfa", userController.LoginMfa)
+		usersRouterPublic.POST("/login_mfa_setup", userController.SetupMfaForLogin)
+		usersRouterPublic.POST("/login_passkey_begin", userController.BeginPasskeyLogin)
+		usersRouterPublic.POST("/login_passkey", userController.FinishPasskeyLogin)
+		usersRouterPublic.GET("/oidc/:provider/authorize", userController.OidcAuthorize)
+		usersRouterPublic.POST("/oidc/:provider/callback", userController.OidcCallback)
+		usersRouterPublic.POST("/refresh", userController.RefreshToken)
+		usersRouterPublic.POST("/forgot_password", userController.ForgotPassword)
+		usersRouterPublic.POST("/reset_password", userController.ResetPassword)
+		usersRouterPublic.POST("/verify_email", userController.VerifyEmail)
+		usersRouterPublic.POST("/resend_verification", userController.ResendVerification)
+		usersRouterPublic.POST("/confirm_email_change", userController.ConfirmEmailChange)
 		usersRouterPublic.GET("/get_user/:id", userController.GetUserByID)
 	}
 
-	// private router - authentication required
+	// private router - authentication required, personal access tokens accepted
+	// (the service checks permissions, which are limited to the token's scopes)
 	usersRouterPrivate := Router.Group("/user")
 	usersRouterPrivate.Use(middlewares.AuthMiddleware())
 	{
 		usersRouterPrivate.GET("/me", userController.GetCurrentUser)
 		usersRouterPrivate.POST("/create_user", userController.CreateUser)
-		usersRouterPrivate.PUT("/update_user/:id", userController.UpdateUser)
 		usersRouterPrivate.GET("/list_user", userController.GetListUser)
 	}
 
-	// admin router - authentication and admin role required
+	// account router - login session required, personal access tokens rejected
+	usersRouterAccount := Router.Group("/user")
+	usersRouterAccount.Use(middlewares.AuthMiddleware(), middlewares.RequireUserSession())
+	{
+		usersRouterAccount.POST("/logout", userController.Logout)
+		usersRouterAccount.GET("/sessions", userController.ListSessions)
+		usersRouterAccount.POST("/ws_ticket", userController.CreateWebSocketTicket)
+		usersRouterAccount.GET("/passkeys", userController.ListPasskeys)
+		usersRouterAccount.PUT("/update_user/:id", userController.UpdateUser)
+	}
+
+	// security router - credentials, email, 2FA and sessions, out of reach of an impersonating admin
+	usersRouterSecurity := Router.Group("/user")
+	usersRouterSecurity.Use(middlewares.AuthMiddleware(), middlewares.RequireUserSession(), middlewares.RejectImpersonation())
+	{
+		usersRouterSecurity.DELETE("/sessions", userController.RevokeOtherSessions)
+		usersRouterSecurity.DELETE("/sessions/:session_id", userController.RevokeSession)
+		usersRouterSecurity.POST("/mfa_setup", userController.SetupMfa)
+		usersRouterSecurity.POST("/mfa_confirm", userController.ConfirmMfa)
+		usersRouterSecurity.POST("/mfa_disable", userController.DisableMfa)
+		usersRouterSecurity.POST("/mfa_recovery_codes", userController.RegenerateRecoveryCodes)
+		usersRouterSecurity.POST("/passkey_register_begin", userController.BeginPasskeyRegistration)
+		usersRouterSecurity.POST("/passkey_register", userController.FinishPasskeyRegistration)
+		usersRouterSecurity.DELETE("/passkeys/:id", userController.DeletePasskey)
+		usersRouterSecurity.POST("/me/password", userController.ChangePassword)
+		usersRouterSecurity.POST("/me/email", userController.RequestEmailChange)
+	}
+
+	// admin router - login session and user management permission required
 	usersRouterAdmin := Router.Group("/admin")
-	usersRouterAdmin.Use(middlewares.AuthMiddleware(), middlewares.RoleMiddleware("ADMIN", "SUPER_ADMIN"))
+	usersRouterAdmin.Use(middlewares.AuthMiddleware(), middlewares.RequireUserSession(), middlewares.RejectImpersonation(),
+		middlewares.RequirePermission(model.PermissionUserManage))
 	{
-		// Admin-only endpoints can be added here
+		usersRouterAdmin.POST("/force_logout/:id", userController.ForceLogout)
+		usersRouterAdmin.POST("/unlock_login/:id", userController.UnlockLogin)
+		usersRouterAdmin.POST("/reset_mfa/:id", userController.ResetMfa)
+		usersRouterAdmin.GET("/users/:id", userController.GetUserDetail)
+		usersRouterAdmin.POST("/users/:id/deactivate", userController.DeactivateUser)
+		usersRouterAdmin.POST("/users/:id/reactivate", userController.ReactivateUser)
+		usersRouterAdmin.PUT("/users/:id/role", userController.ChangeUserRole)
+		usersRouterAdmin.POST("/users/:id/password_reset", userController.ForcePasswordReset)
+		usersRouterAdmin.DELETE("/users/:id", userController.DeleteUser)
+		usersRouterAdmin.POST("/users/:id/restore", userController.RestoreUser)
+		usersRouterAdmin.GET("/users/:id/sessions", userController.ListUserSessions)
+		usersRouterAdmin.DELETE("/users/:id/sessions", userController.ForceLogout)
+		usersRouterAdmin.DELETE("/users/:id/sessions/:session_id", userController.RevokeUserSession)
+		usersRouterAdmin.POST("/users/:id/impersonate", middlewares.RequirePermission(model.PermissionUserImpersonate), userController.ImpersonateUser)
 	}
 }
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/response"
+)
+
+// defaultAuditLogLimit is the page size when the request doesn't set one
+const defaultAuditLogLimit = 20
+
+type IAuditLogService interface {
+	GetListAuditLog(req dto.AuditLogListRequestDto) *response.ServiceResult
+}
+
+type auditLogService struct {
+	auditLogRepo repo.IAuditLogRepository
+}
+
+func NewAuditLogService(auditLogRepo repo.IAuditLogRepository) IAuditLogService {
+	return &auditLogService{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+func (as *auditLogService) GetListAuditLog(req dto.AuditLogListRequestDto) *response.ServiceResult {
+	if req.Limit == 0 {
+		req.Limit = defaultAuditLogLimit
+	}
+	entries, total, err := as.auditLogRepo.GetListAuditLog(req)
+	if err != nil {
+		global.Logger.Error("Failed to get audit log from repository: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	entryDTOs := make([]dto.AuditLogResponseDto, 0, len(entries))
+	for _, e := range entries {
+		entryDTO := dto.AuditLogResponseDto{
+			Id:        e.ID,
+			Action:    e.Action,
+			Details:   e.Details,
+			IP:        e.IP,
+			UserAgent: e.UserAgent,
+			CreatedAt: e.CreatedAt,
+		}
+		if e.Actor != nil {
+			entryDTO.ActorUserID = e.Actor.PublicID
+		}
+		if e.Target != nil {
+			entryDTO.TargetUserID = e.Target.PublicID
+		}
+		entryDTOs = append(entryDTOs, entryDTO)
+	}
+	return response.NewServiceResult(&dto.AuditLogListResponseDto{Data: entryDTOs, Total: total})
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/response"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type IPersonalTokenService interface {
+	GetListPersonalToken(userID uint) *response.ServiceResult
+	CreatePersonalToken(actor *Actor, tokenDto dto.PersonalTokenRequestDto) *response.ServiceResult
+	DeletePersonalToken(userID uint, id uint) *response.ServiceResult
+}
+
+type personalTokenService struct {
+	personalTokenRepo repo.IPersonalTokenRepository
+}
+
+func NewPersonalTokenService(personalTokenRepo repo.IPersonalTokenRepository) IPersonalTokenService {
+	return &personalTokenService{personalTokenRepo: personalTokenRepo}
+}
+
+func (ps *personalTokenService) GetListPersonalToken(userID uint) *response.ServiceResult {
+	tokens, err := ps.personalTokenRepo.GetListPersonalToken(userID)
+	if err != nil {
+		global.Logger.Error("Failed to get personal tokens from repository: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	tokenDTOs := make([]dto.PersonalTokenResponseDto, 0, len(tokens))
+	for i := range tokens {
+		tokenDTOs = append(tokenDTOs, toPersonalTokenResponse(&tokens[i]))
+	}
+	return response.NewServiceResult(tokenDTOs)
+}
+
+func (ps *personalTokenService) CreatePersonalToken(actor *Actor, tokenDto dto.PersonalTokenRequestDto) *response.ServiceResult {
+	if actor == nil {
+		return response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+
+	// A token can't be granted more than its owner has
+	scopes := make([]string, 0, len(tokenDto.Scopes))
+	for _, scope := range tokenDto.Scopes {
+		if !actor.Can(scope) {
+			return response.NewServiceErrorWithCode(422, response.ErrCodeInvalidScope)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	token := &model.PersonalAccessToken{
+		UserID:    actor.UserID,
+		Name:      tokenDto.Name,
+		Scopes:    scopes,
+		ExpiresAt: time.Now().AddDate(0, 0, tokenDto.ExpiresInDays),
+	}
+	plain, err := ps.personalTokenRepo.CreatePersonalToken(token)
+	if err != nil {
+		global.Logger.Error("Failed to create personal token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	global.Logger.Info(fmt.Sprintf("User %d created personal token %d", actor.UserID, token.ID))
+	return response.NewServiceResult(&dto.PersonalTokenCreatedResponseDto{
+		Token:                    plain,
+		PersonalTokenResponseDto: toPersonalTokenResponse(token),
+	})
+}
+
+func (ps *personalTokenService) DeletePersonalToken(userID uint, id uint) *response.ServiceResult {
+	deleted, err := ps.personalTokenRepo.DeletePersonalToken(userID, id)
+	if err != nil {
+		global.Logger.Error("Failed to delete personal token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !deleted {
+		return response.NewServiceErrorWithCode(404, response.ErrCodePersonalTokenNotFound)
+	}
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "Personal access token has been revoked"})
+}
+
+func toPersonalTokenResponse(token *model.PersonalAccessToken) dto.PersonalTokenResponseDto {
+	return dto.PersonalTokenResponseDto{
+		Id:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      token.Scopes,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
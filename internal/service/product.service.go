@@ -10,9 +10,9 @@ import (
 )
 
 type IProductService interface {
-	GetProductByID(id uint) (*dto.ProductDetailDto, error)
+	GetProductByID(publicID string) (*dto.ProductDetailDto, error)
 	GetListProduct() ([]dto.ProductResponseDto, error)
-	CreateProduct(name, description string, userID uint) (uint, error)
+	CreateProduct(name, description string, userID uint) (string, error)
 }
 
 type ProductService struct {
@@ -25,14 +25,14 @@ func NewProductService(productRepo repo.IProductRepository) IProductService {
 	}
 }
 
-func (ps *ProductService) GetProductByID(id uint) (*dto.ProductDetailDto, error) {
-	product, err := ps.productRepo.FindByID(id)
+func (ps *ProductService) GetProductByID(publicID string) (*dto.ProductDetailDto, error) {
+	product, err := ps.productRepo.FindByPublicID(publicID)
 	if err != nil {
 		return nil, err
 	}
 
 	return &dto.ProductDetailDto{
-		ID:          product.ID,
+		ID:          product.PublicID,
 		Name:        product.Name,
 		Description: product.Description,
 		CreatedAt:   product.CreatedAt,
@@ -47,36 +47,39 @@ func (ps *ProductService) GetListProduct() ([]dto.ProductResponseDto, error) {
 	}
 	var productDto []dto.ProductResponseDto
 	for _, product := range products {
-		productDto = append(productDto, dto.ProductResponseDto{
-			ID:     product.ID,
-			UserID: product.UserID,
-			User: dto.UserResponseDto{
-				Id:       product.User.ID,
-				Email:    product.User.Email,
-				Username: product.User.Username,
-				Role:     product.User.Role,
-			},
+		productResponse := dto.ProductResponseDto{
+			ID:          product.PublicID,
 			Name:        product.Name,
 			Description: product.Description,
 			CreatedAt:   product.CreatedAt,
 			UpdatedAt:   product.UpdatedAt,
-		})
+		}
+		// Products of deleted users are listed without an owner. Every user can list
+		// products, so the owner is shown the way a public profile would
+		if product.User != nil {
+			productResponse.UserID = product.User.PublicID
+			productResponse.User = &dto.PublicProfileDto{
+				Id:       product.User.PublicID,
+				Username: product.User.Username,
+			}
+		}
+		productDto = append(productDto, productResponse)
 	}
 
 	return productDto, nil
 }
 
-func (ps *ProductService) CreateProduct(name, description string, userID uint) (uint, error) {
+func (ps *ProductService) CreateProduct(name, description string, userID uint) (string, error) {
 
 	product := &model.Product{
 		Name:        name,
 		Description: description,
-		UserID:      userID,
+		UserID:      &userID,
 	}
 
 	createdProduct, err := ps.productRepo.Create(product)
 	if err != nil {
-		return 0, err
+		return "", err
 	}
 
 	// Broadcast new product
@@ -86,11 +89,11 @@ func (ps *ProductService) CreateProduct(name, description string, userID uint) (
 		global.WsManager.Broadcast(map[string]any{
 			"type":         "new_product",
 			"message":      "New product: " + name,
-			"product_id":   createdProduct.ID,
+			"product_id":   createdProduct.PublicID,
 			"product_name": name,
 			"time":         time.Now().Unix(),
 		})
 	}
 
-	return createdProduct.ID, nil
+	return createdProduct.PublicID, nil
 }
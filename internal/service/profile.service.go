@@ -0,0 +1,225 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/imaging"
+	"base_go_be/pkg/response"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"time"
+	_ "time/tzdata" // timezones are validated the same wherever the app runs
+
+	"golang.org/x/text/language"
+)
+
+const (
+	avatarSize = 256
+	// maxAvatarPixels bounds the memory decoding an upload takes
+	maxAvatarPixels = 4096 * 4096
+)
+
+type IProfileService interface {
+	GetProfile(userID uint) *response.ServiceResult
+	UpdateProfile(actor *Actor, profileDto dto.ProfileUpdateRequestDto) *response.ServiceResult
+	UploadAvatar(actor *Actor, r io.Reader) *response.ServiceResult
+	DeleteAvatar(actor *Actor) *response.ServiceResult
+}
+
+type profileService struct {
+	userRepo repo.IUserRepository
+}
+
+func NewProfileService(userRepo repo.IUserRepository) IProfileService {
+	return &profileService{userRepo: userRepo}
+}
+
+func (ps *profileService) GetProfile(userID uint) *response.ServiceResult {
+	user := ps.userRepo.GetUserByID(userID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+	}
+	return response.NewServiceResult(newProfileResponse(user))
+}
+
+// UpdateProfile changes the fields sent and leaves the others as they are
+func (ps *profileService) UpdateProfile(actor *Actor, profileDto dto.ProfileUpdateRequestDto) *response.ServiceResult {
+	user := ps.userRepo.GetUserByID(actor.UserID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+	}
+
+	var fields []string
+	if profileDto.Fullname != nil {
+		user.Fullname = *profileDto.Fullname
+		fields = append(fields, "fullname")
+	}
+	if profileDto.Bio != nil {
+		user.Bio = *profileDto.Bio
+		fields = append(fields, "bio")
+	}
+	if profileDto.Locale != nil {
+		locale, err := normalizeLocale(*profileDto.Locale)
+		if err != nil {
+			return response.NewServiceErrorWithCode(422, response.ErrCodeInvalidLocale)
+		}
+		user.Locale = locale
+		fields = append(fields, "locale")
+	}
+	if profileDto.Timezone != nil {
+		if *profileDto.Timezone != "" {
+			if _, err := time.LoadLocation(*profileDto.Timezone); err != nil || *profileDto.Timezone == "Local" {
+				return response.NewServiceErrorWithCode(422, response.ErrCodeInvalidTimezone)
+			}
+		}
+		user.Timezone = *profileDto.Timezone
+		fields = append(fields, "timezone")
+	}
+	if prefs := profileDto.NotificationPrefs; prefs != nil {
+		if prefs.ProductUpdates != nil {
+			user.NotificationPrefs.ProductUpdates = *prefs.ProductUpdates
+		}
+		if prefs.Newsletter != nil {
+			user.NotificationPrefs.Newsletter = *prefs.Newsletter
+		}
+		fields = append(fields, "notification_prefs")
+	}
+	if len(fields) == 0 {
+		return response.NewServiceResult(newProfileResponse(user))
+	}
+
+	if err := ps.userRepo.UpdateUserFields(user.ID, user, fields...); err != nil {
+		actor.logger().Error("Failed to update profile: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	return ps.GetProfile(actor.UserID)
+}
+
+// UploadAvatar stores the image as the avatar of the user, cropped to a square and
+// resized, then removes the previous one
+func (ps *profileService) UploadAvatar(actor *Actor, r io.Reader) *response.ServiceResult {
+	user := ps.userRepo.GetUserByID(actor.UserID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+	}
+
+	img, err := imaging.Decode(r, maxAvatarPixels)
+	if errors.Is(err, imaging.ErrUnsupportedImage) || errors.Is(err, imaging.ErrImageTooLarge) {
+		return response.NewServiceErrorWithCode(422, response.ErrCodeInvalidImage)
+	}
+	if err != nil {
+		actor.logger().Error("Failed to read avatar upload: " + err.Error())
+		return response.NewServiceErrorWithCode(400, response.ErrCodeInvalidImage)
+	}
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, imaging.Square(img, avatarSize)); err != nil {
+		actor.logger().Error("Failed to encode avatar: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	// Every upload gets a new key, so clients and caches never show a stale avatar
+	key, err := newAvatarKey(user.PublicID)
+	if err != nil {
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if err := global.Storage.Put(key, &encoded, "image/png"); err != nil {
+		actor.logger().Error("Failed to store avatar: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	previousKey := user.AvatarKey
+	user.AvatarKey = key
+	if err := ps.userRepo.UpdateUserFields(user.ID, user, "avatar_key"); err != nil {
+		actor.logger().Error("Failed to save avatar: " + err.Error())
+		ps.deleteAvatarFile(key)
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	ps.deleteAvatarFile(previousKey)
+
+	return ps.GetProfile(actor.UserID)
+}
+
+func (ps *profileService) DeleteAvatar(actor *Actor) *response.ServiceResult {
+	user := ps.userRepo.GetUserByID(actor.UserID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+	}
+	if user.AvatarKey == "" {
+		return response.NewServiceResult(newProfileResponse(user))
+	}
+
+	previousKey := user.AvatarKey
+	user.AvatarKey = ""
+	if err := ps.userRepo.UpdateUserFields(user.ID, user, "avatar_key"); err != nil {
+		actor.logger().Error("Failed to remove avatar: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	ps.deleteAvatarFile(previousKey)
+
+	return ps.GetProfile(actor.UserID)
+}
+
+// deleteAvatarFile removes a file no longer referenced. Failing only leaves an orphan
+// file behind, so it is logged rather than returned
+func (ps *profileService) deleteAvatarFile(key string) {
+	if key == "" {
+		return
+	}
+	if err := global.Storage.Delete(key); err != nil {
+		global.Logger.Warn(fmt.Sprintf("Failed to delete avatar %s: %s", key, err.Error()))
+	}
+}
+
+func newProfileResponse(user *model.User) *dto.ProfileResponseDto {
+	return &dto.ProfileResponseDto{
+		Id:        user.PublicID,
+		Email:     user.Email,
+		Username:  user.Username,
+		Fullname:  user.Fullname,
+		Bio:       user.Bio,
+		AvatarURL: avatarURL(user.AvatarKey),
+		Locale:    user.Locale,
+		Timezone:  user.Timezone,
+		NotificationPrefs: dto.NotificationPrefsDto{
+			ProductUpdates: user.NotificationPrefs.ProductUpdates,
+			Newsletter:     user.NotificationPrefs.Newsletter,
+		},
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// avatarURL is where clients download the avatar stored under key, "" without one
+func avatarURL(key string) string {
+	if key == "" {
+		return ""
+	}
+	return global.Storage.URL(key)
+}
+
+func newAvatarKey(publicID string) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("avatars/%s-%s.png", publicID, hex.EncodeToString(b)), nil
+}
+
+// normalizeLocale checks the locale is a BCP 47 tag and returns its canonical form,
+// e.g. "en-us" becomes "en-US". "" clears the locale
+func normalizeLocale(locale string) (string, error) {
+	if locale == "" {
+		return "", nil
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", err
+	}
+	return tag.String(), nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/response"
+)
+
+type IRoleService interface {
+	GetListRole() *response.ServiceResult
+	GetListPermission() *response.ServiceResult
+	CreateRole(roleDto dto.RoleRequestDto) *response.ServiceResult
+	UpdateRolePermissions(name string, permissionsDto dto.RolePermissionsRequestDto) *response.ServiceResult
+}
+
+type roleService struct {
+	roleRepo repo.IRoleRepository
+}
+
+func NewRoleService(roleRepo repo.IRoleRepository) IRoleService {
+	return &roleService{roleRepo: roleRepo}
+}
+
+func (rs *roleService) GetListRole() *response.ServiceResult {
+	roles, err := rs.roleRepo.GetListRole()
+	if err != nil {
+		global.Logger.Error("Failed to get roles from repository: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	roleDTOs := make([]dto.RoleResponseDto, 0, len(roles))
+	for i := range roles {
+		roleDTOs = append(roleDTOs, toRoleResponse(&roles[i]))
+	}
+	return response.NewServiceResult(roleDTOs)
+}
+
+func (rs *roleService) GetListPermission() *response.ServiceResult {
+	permissions, err := rs.roleRepo.GetListPermission()
+	if err != nil {
+		global.Logger.Error("Failed to get permissions from repository: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	permissionDTOs := make([]dto.PermissionResponseDto, 0, len(permissions))
+	for _, p := range permissions {
+		permissionDTOs = append(permissionDTOs, dto.PermissionResponseDto{
+			Name:        p.Name,
+			Description: p.Description,
+		})
+	}
+	return response.NewServiceResult(permissionDTOs)
+}
+
+func (rs *roleService) CreateRole(roleDto dto.RoleRequestDto) *response.ServiceResult {
+	if rs.roleRepo.GetRoleByName(roleDto.Name) != nil {
+		return response.NewServiceErrorWithCode(409, response.ErrCodeRoleHasExists)
+	}
+
+	permissions, result := rs.findPermissions(roleDto.Permissions)
+	if result != nil {
+		return result
+	}
+
+	role := &model.Role{
+		Name:        roleDto.Name,
+		Description: roleDto.Description,
+		Permissions: permissions,
+	}
+	if err := rs.roleRepo.CreateRole(role); err != nil {
+		global.Logger.Error("Failed to create role: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	roleResponse := toRoleResponse(role)
+	return response.NewServiceResult(&roleResponse)
+}
+
+func (rs *roleService) UpdateRolePermissions(name string, permissionsDto dto.RolePermissionsRequestDto) *response.ServiceResult {
+	role := rs.roleRepo.GetRoleByName(name)
+	if role == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeRoleNotFound)
+	}
+
+	permissions, result := rs.findPermissions(permissionsDto.Permissions)
+	if result != nil {
+		return result
+	}
+
+	if err := rs.roleRepo.SetRolePermissions(role, permissions); err != nil {
+		global.Logger.Error("Failed to update role permissions: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	role.Permissions = permissions
+	roleResponse := toRoleResponse(role)
+	return response.NewServiceResult(&roleResponse)
+}
+
+// findPermissions loads the named permissions and fails if any of them doesn't exist
+func (rs *roleService) findPermissions(names []string) ([]model.Permission, *response.ServiceResult) {
+	permissions, err := rs.roleRepo.GetPermissionsByNames(names)
+	if err != nil {
+		global.Logger.Error("Failed to get permissions from repository: " + err.Error())
+		return nil, response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		found[p.Name] = true
+	}
+	for _, name := range names {
+		if !found[name] {
+			return nil, response.NewServiceErrorWithCode(422, response.ErrCodePermissionNotFound)
+		}
+	}
+	return permissions, nil
+}
+
+func toRoleResponse(role *model.Role) dto.RoleResponseDto {
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		permissions = append(permissions, p.Name)
+	}
+	return dto.RoleResponseDto{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+	}
+}
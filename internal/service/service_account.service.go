@@ -0,0 +1,201 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/response"
+	"fmt"
+)
+
+type IServiceAccountService interface {
+	GetListServiceAccount() *response.ServiceResult
+	CreateServiceAccount(actor *Actor, accountDto dto.ServiceAccountRequestDto) *response.ServiceResult
+	UpdateServiceAccount(actor *Actor, id uint, accountDto dto.ServiceAccountUpdateRequestDto) *response.ServiceResult
+	CreateServiceAccountKey(id uint) *response.ServiceResult
+	DeleteServiceAccountKey(id uint, keyID string) *response.ServiceResult
+}
+
+type serviceAccountService struct {
+	serviceAccountRepo repo.IServiceAccountRepository
+	signedRequestRepo  repo.ISignedRequestRepository
+	roleRepo           repo.IRoleRepository
+}
+
+func NewServiceAccountService(
+	serviceAccountRepo repo.IServiceAccountRepository,
+	signedRequestRepo repo.ISignedRequestRepository,
+	roleRepo repo.IRoleRepository,
+) IServiceAccountService {
+	return &serviceAccountService{
+		serviceAccountRepo: serviceAccountRepo,
+		signedRequestRepo:  signedRequestRepo,
+		roleRepo:           roleRepo,
+	}
+}
+
+func (ss *serviceAccountService) GetListServiceAccount() *response.ServiceResult {
+	accounts, err := ss.serviceAccountRepo.GetListServiceAccount()
+	if err != nil {
+		global.Logger.Error("Failed to get service accounts from repository: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	accountDTOs := make([]dto.ServiceAccountResponseDto, 0, len(accounts))
+	for i := range accounts {
+		accountDTOs = append(accountDTOs, ss.toServiceAccountResponse(&accounts[i]))
+	}
+	return response.NewServiceResult(accountDTOs)
+}
+
+func (ss *serviceAccountService) CreateServiceAccount(actor *Actor, accountDto dto.ServiceAccountRequestDto) *response.ServiceResult {
+	if actor == nil {
+		return response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+	if ss.serviceAccountRepo.GetServiceAccountByName(accountDto.Name) != nil {
+		return response.NewServiceErrorWithCode(409, response.ErrCodeServiceAccountHasExists)
+	}
+	if result := ss.checkRoleGrantable(actor, accountDto.Role); result != nil {
+		return result
+	}
+
+	account := &model.ServiceAccount{
+		Name:        accountDto.Name,
+		Description: accountDto.Description,
+		Role:        accountDto.Role,
+		OwnerUserID: actor.UserID,
+		IsActive:    true,
+	}
+	if err := ss.serviceAccountRepo.CreateServiceAccount(account); err != nil {
+		global.Logger.Error("Failed to create service account: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	global.Logger.Info(fmt.Sprintf("User %d created service account %d with role %s", actor.UserID, account.ID, account.Role))
+	accountResponse := ss.toServiceAccountResponse(account)
+	return response.NewServiceResult(&accountResponse)
+}
+
+func (ss *serviceAccountService) UpdateServiceAccount(actor *Actor, id uint, accountDto dto.ServiceAccountUpdateRequestDto) *response.ServiceResult {
+	if actor == nil {
+		return response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+	account := ss.serviceAccountRepo.GetServiceAccountByID(id)
+	if account == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeServiceAccountNotFound)
+	}
+
+	if accountDto.Role != "" && accountDto.Role != account.Role {
+		if result := ss.checkRoleGrantable(actor, accountDto.Role); result != nil {
+			return result
+		}
+		account.Role = accountDto.Role
+	}
+	if accountDto.Description != nil {
+		account.Description = *accountDto.Description
+	}
+	if accountDto.IsActive != nil {
+		account.IsActive = *accountDto.IsActive
+	}
+	if err := ss.serviceAccountRepo.UpdateServiceAccount(account); err != nil {
+		global.Logger.Error("Failed to update service account: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	global.Logger.Info(fmt.Sprintf("User %d updated service account %d (role %s, active %t)", actor.UserID, account.ID, account.Role, account.IsActive))
+	accountResponse := ss.toServiceAccountResponse(account)
+	return response.NewServiceResult(&accountResponse)
+}
+
+func (ss *serviceAccountService) CreateServiceAccountKey(id uint) *response.ServiceResult {
+	account := ss.serviceAccountRepo.GetServiceAccountByID(id)
+	if account == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeServiceAccountNotFound)
+	}
+
+	key := &model.ServiceAccountKey{ServiceAccountID: account.ID}
+	secret, err := ss.serviceAccountRepo.CreateServiceAccountKey(key)
+	if err != nil {
+		global.Logger.Error("Failed to create service account key: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	global.Logger.Info(fmt.Sprintf("Created key %s for service account %d", key.KeyID, account.ID))
+	return response.NewServiceResult(&dto.ServiceAccountKeyCreatedResponseDto{
+		Secret:                       secret,
+		ServiceAccountKeyResponseDto: ss.toServiceAccountKeyResponse(key),
+	})
+}
+
+func (ss *serviceAccountService) DeleteServiceAccountKey(id uint, keyID string) *response.ServiceResult {
+	deleted, err := ss.serviceAccountRepo.DeleteServiceAccountKey(id, keyID)
+	if err != nil {
+		global.Logger.Error("Failed to delete service account key: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !deleted {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeApiKeyNotFound)
+	}
+	if err := ss.signedRequestRepo.DeleteKeyUsage(keyID); err != nil {
+		global.Logger.Warn("Failed to delete service account key usage: " + err.Error())
+	}
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "API key has been revoked"})
+}
+
+// checkRoleGrantable - the role must exist, and a service account can't be given
+// permissions the admin creating it doesn't have
+func (ss *serviceAccountService) checkRoleGrantable(actor *Actor, role string) *response.ServiceResult {
+	if ss.roleRepo.GetRoleByName(role) == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeRoleNotFound)
+	}
+	permissions, err := ss.roleRepo.GetRolePermissions(role)
+	if err != nil {
+		global.Logger.Error("Failed to load role permissions: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	for _, permission := range permissions {
+		if !actor.Can(permission) {
+			return response.NewServiceErrorWithCode(403, response.ErrCodeAccessDenied)
+		}
+	}
+	return nil
+}
+
+func (ss *serviceAccountService) toServiceAccountResponse(account *model.ServiceAccount) dto.ServiceAccountResponseDto {
+	keys := make([]dto.ServiceAccountKeyResponseDto, 0, len(account.Keys))
+	for i := range account.Keys {
+		keys = append(keys, ss.toServiceAccountKeyResponse(&account.Keys[i]))
+	}
+	var ownerUserID string
+	if account.Owner != nil {
+		ownerUserID = account.Owner.PublicID
+	}
+	return dto.ServiceAccountResponseDto{
+		Id:          account.ID,
+		Name:        account.Name,
+		Description: account.Description,
+		Role:        account.Role,
+		OwnerUserId: ownerUserID,
+		IsActive:    account.IsActive,
+		Keys:        keys,
+		CreatedAt:   account.CreatedAt,
+	}
+}
+
+// toServiceAccountKeyResponse adds the usage counted in Redis, left at 0 if unavailable
+func (ss *serviceAccountService) toServiceAccountKeyResponse(key *model.ServiceAccountKey) dto.ServiceAccountKeyResponseDto {
+	keyResponse := dto.ServiceAccountKeyResponseDto{
+		KeyId:      key.KeyID,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+	usage, err := ss.signedRequestRepo.GetKeyUsage(key.KeyID)
+	if err != nil {
+		global.Logger.Warn("Failed to get service account key usage: " + err.Error())
+		return keyResponse
+	}
+	keyResponse.RequestCount = usage.Total
+	keyResponse.RequestsToday = usage.Today
+	return keyResponse
+}
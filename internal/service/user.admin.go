@@ -0,0 +1,250 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/pkg/response"
+	"fmt"
+	"time"
+)
+
+// GetUserDetail returns everything about a user an admin may need, account status included
+func (us *userService) GetUserDetail(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult {
+	user, denied := us.adminTarget(actor, publicID)
+	if denied != nil {
+		return denied
+	}
+
+	us.audit(actor.UserID, model.AuditActionUserView, &user.ID, nil, client)
+
+	mfa := us.mfaRepo.GetUserMfa(user.ID)
+	return response.NewServiceResult(&dto.AdminUserDetailDto{
+		Id:              user.PublicID,
+		Email:           user.Email,
+		Username:        user.Username,
+		Role:            user.Role,
+		IsActive:        user.IsActive,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		MfaEnabled:      mfa != nil && mfa.IsEnabled(),
+		CreatedAt:       user.CreatedAt,
+	})
+}
+
+// DeactivateUser disables the account and logs it out everywhere. Logins and the
+// tokens already issued are refused until it is reactivated
+func (us *userService) DeactivateUser(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult {
+	user, denied := us.adminTarget(actor, publicID)
+	if denied != nil {
+		return denied
+	}
+	// An admin deactivating themselves would have nobody left to undo it
+	if user.ID == actor.UserID {
+		return response.NewServiceErrorWithCode(403, response.ErrCodeAccessDenied)
+	}
+
+	if err := us.userRepo.SetUserActive(user.ID, false); err != nil {
+		global.Logger.Error("Failed to deactivate user: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if err := us.revokeAllSessions(user.ID); err != nil {
+		global.Logger.Error("Failed to revoke user sessions: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	us.audit(actor.UserID, model.AuditActionUserDeactivate, &user.ID, nil, client)
+	global.Logger.Info(fmt.Sprintf("User %d deactivated user %d", actor.UserID, user.ID))
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "User has been deactivated"})
+}
+
+func (us *userService) ReactivateUser(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult {
+	user, denied := us.adminTarget(actor, publicID)
+	if denied != nil {
+		return denied
+	}
+
+	if err := us.userRepo.SetUserActive(user.ID, true); err != nil {
+		global.Logger.Error("Failed to reactivate user: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	us.audit(actor.UserID, model.AuditActionUserReactivate, &user.ID, nil, client)
+	global.Logger.Info(fmt.Sprintf("User %d reactivated user %d", actor.UserID, user.ID))
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "User has been reactivated"})
+}
+
+// ChangeUserRole gives the user another role. Tokens carry the role, so the user is
+// logged out to pick up the new one
+func (us *userService) ChangeUserRole(actor *Actor, publicID string, role string, client dto.ClientInfoDto) *response.ServiceResult {
+	user, denied := us.adminTarget(actor, publicID)
+	if denied != nil {
+		return denied
+	}
+	if user.ID == actor.UserID {
+		return response.NewServiceErrorWithCode(403, response.ErrCodeAccessDenied)
+	}
+	if us.roleRepo.GetRoleByName(role) == nil {
+		return response.NewServiceErrorWithCode(422, response.ErrCodeRoleNotFound)
+	}
+	if denied := us.checkRoleWithin(actor, role); denied != nil {
+		return denied
+	}
+	if role == user.Role {
+		return response.NewServiceResult(newUserResponse(user))
+	}
+
+	updatedUser, err := us.userRepo.UpdateUser(user.ID, &model.User{Role: role})
+	if err != nil {
+		global.Logger.Error("Failed to change user role: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if err := us.revokeAllSessions(user.ID); err != nil {
+		global.Logger.Error("Failed to revoke user sessions: " + err.Error())
+	}
+
+	us.audit(actor.UserID, model.AuditActionUserRoleChange, &user.ID, map[string]any{
+		"from": user.Role,
+		"to":   role,
+	}, client)
+	global.Logger.Info(fmt.Sprintf("User %d changed the role of user %d from %s to %s", actor.UserID, user.ID, user.Role, role))
+	return response.NewServiceResult(newUserResponse(updatedUser))
+}
+
+// ForcePasswordReset replaces the password with one nobody knows, logs the user out
+// everywhere and emails them a reset link, e.g. when the password may have leaked
+func (us *userService) ForcePasswordReset(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult {
+	user, denied := us.adminTarget(actor, publicID)
+	if denied != nil {
+		return denied
+	}
+
+	password, err := unusablePassword()
+	if err != nil {
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if _, err := us.userRepo.UpdateUser(user.ID, &model.User{Password: password}); err != nil {
+		global.Logger.Error("Failed to clear password: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if err := us.revokeAllSessions(user.ID); err != nil {
+		global.Logger.Error("Failed to revoke user sessions: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if err := us.sendPasswordReset(user); err != nil {
+		global.Logger.Error("Failed to send password reset: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	us.audit(actor.UserID, model.AuditActionUserPasswordReset, &user.ID, nil, client)
+	global.Logger.Info(fmt.Sprintf("User %d forced a password reset of user %d", actor.UserID, user.ID))
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "Password has been cleared and a reset link sent to the user"})
+}
+
+// DeleteUser soft deletes the user: they can't log in, are logged out everywhere and
+// disappear from lookups, but can be restored until purged after the retention period
+func (us *userService) DeleteUser(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult {
+	user, denied := us.adminTarget(actor, publicID)
+	if denied != nil {
+		return denied
+	}
+	if user.ID == actor.UserID {
+		return response.NewServiceErrorWithCode(403, response.ErrCodeAccessDenied)
+	}
+
+	if err := us.userRepo.DeleteUser(user.ID); err != nil {
+		global.Logger.Error("Failed to delete user: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if err := us.revokeAllSessions(user.ID); err != nil {
+		global.Logger.Error("Failed to revoke user sessions: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	us.audit(actor.UserID, model.AuditActionUserDelete, &user.ID, nil, client)
+	global.Logger.Info(fmt.Sprintf("User %d deleted user %d", actor.UserID, user.ID))
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "User has been deleted"})
+}
+
+// RestoreUser brings back a deleted user that wasn't purged yet, unless their email or
+// username has been taken again in the meantime
+func (us *userService) RestoreUser(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult {
+	if actor == nil {
+		return response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+	user := us.userRepo.GetDeletedUserByPublicID(publicID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+	}
+	if denied := us.checkRoleWithin(actor, user.Role); denied != nil {
+		return denied
+	}
+	if us.userRepo.GetUserByEmail(user.Email) != nil {
+		return response.NewServiceErrorWithCode(409, response.ErrCodeUserHasExists)
+	}
+
+	if err := us.userRepo.RestoreUser(user.ID); err != nil {
+		// The username being taken again surfaces as a unique violation
+		global.Logger.Error("Failed to restore user: " + err.Error())
+		return response.NewServiceErrorWithCode(409, response.ErrCodeUserHasExists)
+	}
+
+	us.audit(actor.UserID, model.AuditActionUserRestore, &user.ID, nil, client)
+	global.Logger.Info(fmt.Sprintf("User %d restored user %d", actor.UserID, user.ID))
+	return response.NewServiceResult(newUserResponse(user))
+}
+
+// PurgeDeletedUsers removes for good the users deleted more than retention ago and
+// returns how many. Their products are kept without an owner, their service accounts
+// are removed. A user failing to purge is logged and retried on the next run, without
+// holding back the others
+func (us *userService) PurgeDeletedUsers(retention time.Duration) (int, error) {
+	users, err := us.userRepo.GetUsersDeletedBefore(time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, user := range users {
+		if err := us.userRepo.PurgeUser(user.ID); err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to purge user %d: %s", user.ID, err.Error()))
+			continue
+		}
+		purged++
+		global.Logger.Info(fmt.Sprintf("Deleted user %d has been purged", user.ID))
+	}
+	return purged, nil
+}
+
+// adminTarget resolves the user an admin action is about. Admin actions need user:manage,
+// and admins can only act on users whose role has no permission they lack themselves
+func (us *userService) adminTarget(actor *Actor, publicID string) (*model.User, *response.ServiceResult) {
+	if actor == nil {
+		return nil, response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+	if !actor.Can(model.PermissionUserManage) {
+		return nil, response.NewServiceErrorWithCode(403, response.ErrCodeAccessDenied)
+	}
+	user, notFound := us.userByPublicID(publicID)
+	if notFound != nil {
+		return nil, notFound
+	}
+	if denied := us.checkRoleWithin(actor, user.Role); denied != nil {
+		return nil, denied
+	}
+	return user, nil
+}
+
+// checkRoleWithin tells whether every permission of the role is one the actor has,
+// nil meaning yes
+func (us *userService) checkRoleWithin(actor *Actor, role string) *response.ServiceResult {
+	permissions, err := us.roleRepo.GetRolePermissions(role)
+	if err != nil {
+		global.Logger.Error("Failed to load role permissions: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	for _, permission := range permissions {
+		if !actor.Can(permission) {
+			return response.NewServiceErrorWithCode(403, response.ErrCodeAccessDenied)
+		}
+	}
+	return nil
+}
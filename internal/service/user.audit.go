@@ -0,0 +1,24 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"fmt"
+)
+
+// audit records a sensitive action in the audit log. A failure is logged but doesn't
+// undo the action, which already happened
+func (us *userService) audit(actorUserID uint, action string, targetUserID *uint, details map[string]any, client dto.ClientInfoDto) {
+	entry := &model.AuditLog{
+		ActorUserID:  actorUserID,
+		Action:       action,
+		TargetUserID: targetUserID,
+		Details:      details,
+		IP:           client.IP,
+		UserAgent:    client.UserAgent,
+	}
+	if err := us.auditLogRepo.CreateAuditLog(entry); err != nil {
+		global.Logger.Error(fmt.Sprintf("Failed to record audit log %s by user %d: %s", action, actorUserID, err.Error()))
+	}
+}
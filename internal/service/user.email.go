@@ -0,0 +1,106 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/response"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	emailChangeLimit  = 3
+	emailChangeWindow = time.Hour
+)
+
+// RequestEmailChange emails a confirmation link to the new address and a notice to the
+// current one. The account keeps its email until the link is opened
+func (us *userService) RequestEmailChange(userID uint, changeDto dto.ChangeEmailRequestDto, client dto.ClientInfoDto) *response.ServiceResult {
+	user := us.userRepo.GetUserByID(userID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+	}
+	if denied := us.verifyCurrentPassword(user, changeDto.Password, client); denied != nil {
+		return denied
+	}
+	if changeDto.Email == user.Email {
+		return response.NewServiceErrorWithCode(422, response.ErrCodeInvalidParams)
+	}
+
+	// Each request emails an address of the user's choosing, so they are throttled
+	allowed, err := us.rateLimitRepo.Allow("email_change:"+strconv.FormatUint(uint64(userID), 10), emailChangeLimit, emailChangeWindow)
+	if err != nil {
+		global.Logger.Error("Failed to check email change rate limit: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !allowed {
+		return response.NewServiceErrorWithCode(429, response.ErrCodeTooManyRequests)
+	}
+
+	if us.userRepo.GetUserByEmail(changeDto.Email) != nil {
+		return response.NewServiceErrorWithCode(409, response.ErrCodeUserHasExists)
+	}
+
+	// A new request invalidates the link of the previous one
+	ttlMinutes := global.Config.Auth.EmailVerificationTTL
+	token, err := us.tokenRepo.CreateOneTimeToken(repo.PurposeEmailChange,
+		repo.OneTimeToken{UserID: user.ID, Data: changeDto.Email}, time.Duration(ttlMinutes)*time.Minute)
+	if err != nil {
+		global.Logger.Error("Failed to create email change token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	sendEmailChangeMail(changeDto.Email, user.Username, frontendLink("/confirm-email", token), ttlMinutes)
+	sendEmailChangeNoticeMail(user.Email, user.Username, maskEmail(changeDto.Email))
+
+	global.Logger.Info(fmt.Sprintf("User %d requested an email change", user.ID))
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "A confirmation link has been sent to the new email"})
+}
+
+// ConfirmEmailChange moves the account to the address the token was sent to, which
+// opening the link also verifies
+func (us *userService) ConfirmEmailChange(token string) *response.ServiceResult {
+	changeToken, err := us.tokenRepo.ConsumeOneTimeToken(repo.PurposeEmailChange, token)
+	if err != nil {
+		global.Logger.Error("Failed to consume email change token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if changeToken == nil {
+		return response.NewServiceErrorWithCode(400, response.ErrInvalidToken)
+	}
+
+	user := us.userRepo.GetUserByID(changeToken.UserID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(400, response.ErrInvalidToken)
+	}
+
+	// The address may have been registered since the link was sent
+	newEmail := changeToken.Data
+	if existing := us.userRepo.GetUserByEmail(newEmail); existing != nil && existing.ID != user.ID {
+		return response.NewServiceErrorWithCode(409, response.ErrCodeUserHasExists)
+	}
+
+	now := time.Now()
+	if _, err := us.userRepo.UpdateUser(user.ID, &model.User{Email: newEmail, EmailVerifiedAt: &now}); err != nil {
+		// Registered in the meantime, caught by the unique index
+		global.Logger.Error("Failed to change email: " + err.Error())
+		return response.NewServiceErrorWithCode(409, response.ErrCodeUserHasExists)
+	}
+
+	global.Logger.Info(fmt.Sprintf("Email of user %d has been changed", user.ID))
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "Email has been changed"})
+}
+
+// maskEmail hides most of the local part, e.g. "jo***@example.com", so the notice to the
+// old address doesn't hand the new one to whoever reads it
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	visible := min(2, at/2)
+	return email[:visible] + "***" + email[at:]
+}
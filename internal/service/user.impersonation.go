@@ -0,0 +1,118 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/jwt"
+	"base_go_be/pkg/response"
+	"fmt"
+	"time"
+)
+
+// ImpersonateUser issues a short-lived access token acting as the target user, for
+// support staff to reproduce their issues. The token names the admin, so the API can
+// refuse sensitive actions and mark the requests, and start and end are audited
+func (us *userService) ImpersonateUser(actor *Actor, targetPublicID string, reason string, client dto.ClientInfoDto) *response.ServiceResult {
+	if actor == nil {
+		return response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+	if actor.IsImpersonated() {
+		return response.NewServiceErrorWithCode(403, response.ErrCodeImpersonationForbidden)
+	}
+
+	target, notFound := us.userByPublicID(targetPublicID)
+	if notFound != nil {
+		return notFound
+	}
+	if actor.UserID == target.ID {
+		return response.NewServiceErrorWithCode(403, response.ErrCodeAccessDenied)
+	}
+	impersonator := us.userRepo.GetUserByID(actor.UserID)
+	if impersonator == nil {
+		return response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+	if statusResult := checkAccountStatus(target); statusResult != nil {
+		return statusResult
+	}
+
+	// Acting as a user must not give the admin permissions they don't have
+	if denied := us.checkRoleWithin(actor, target.Role); denied != nil {
+		return denied
+	}
+
+	ttl := time.Duration(global.Config.Auth.ImpersonationTTL) * time.Minute
+	session := &repo.Session{
+		UserID:         target.ID,
+		Device:         deviceName(client.UserAgent),
+		UserAgent:      client.UserAgent,
+		IP:             client.IP,
+		ImpersonatorID: actor.UserID,
+	}
+	if err := us.sessionRepo.CreateSession(session, ttl); err != nil {
+		global.Logger.Error("Failed to create session: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	expiresAt := session.CreatedAt.Add(ttl)
+	// Tracked so that an impersonation left to expire gets its end recorded too
+	if err := us.sessionRepo.TrackImpersonation(session, expiresAt); err != nil {
+		global.Logger.Error("Failed to track impersonation: " + err.Error())
+		_ = us.sessionRepo.DeleteSession(target.ID, session.ID)
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	token, err := jwt.GenerateImpersonationToken(target.PublicID, target.Email, target.Role, session.ID, impersonator.PublicID, ttl)
+	if err != nil {
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	us.audit(actor.UserID, model.AuditActionImpersonationStart, &target.ID, map[string]any{
+		"reason":     reason,
+		"session_id": session.ID,
+		"expires_at": expiresAt,
+	}, client)
+	global.Logger.Info(fmt.Sprintf("User %d started impersonating user %d", actor.UserID, target.ID))
+
+	return response.NewServiceResult(&dto.ImpersonationResponseDto{
+		Token:          token,
+		ExpiresAt:      expiresAt,
+		ImpersonatorID: impersonator.PublicID,
+		User:           *newUserResponse(target),
+	})
+}
+
+// endImpersonation records the end of the impersonation of the session, once whether
+// the admin logged out or it expired
+func (us *userService) endImpersonation(sessionID string, expired bool, client dto.ClientInfoDto) (bool, error) {
+	session, err := us.sessionRepo.EndImpersonation(sessionID)
+	if err != nil || session == nil {
+		return false, err
+	}
+	us.audit(session.ImpersonatorID, model.AuditActionImpersonationEnd, &session.UserID,
+		map[string]any{"session_id": sessionID, "expired": expired}, client)
+	global.Logger.Info(fmt.Sprintf("User %d stopped impersonating user %d", session.ImpersonatorID, session.UserID))
+	return true, nil
+}
+
+// EndExpiredImpersonations records the end of the impersonations whose token expired
+// without the admin logging out
+func (us *userService) EndExpiredImpersonations() (int, error) {
+	sessionIDs, err := us.sessionRepo.ExpiredImpersonations(time.Now())
+	if err != nil {
+		return 0, err
+	}
+	ended := 0
+	for _, sessionID := range sessionIDs {
+		ok, err := us.endImpersonation(sessionID, true, dto.ClientInfoDto{})
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to end impersonation of session %s: %s", sessionID, err.Error()))
+			continue
+		}
+		if ok {
+			ended++
+		}
+	}
+	return ended, nil
+}
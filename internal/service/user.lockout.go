@@ -0,0 +1,93 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/pkg/passwd"
+	"base_go_be/pkg/response"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	loginDelayBase = 250 * time.Millisecond
+	loginDelayMax  = 4 * time.Second
+)
+
+// dummyPasswordHash is compared against when the email is unknown, so a login for a
+// missing account takes as long as one with a wrong password
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := passwd.Hash("dummy-password")
+	return hash
+})
+
+// checkLoginAllowed refuses the attempt when the email is locked or the IP has failed
+// too often, before any password is checked
+func (us *userService) checkLoginAllowed(email string, client dto.ClientInfoDto) *response.ServiceResult {
+	lockTTL, err := us.loginAttemptRepo.GetLockTTL(email)
+	if err != nil {
+		global.Logger.Error("Failed to check login lock: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if lockTTL > 0 {
+		return response.NewServiceErrorWithCode(423, response.ErrCodeAccountLocked)
+	}
+
+	ipFailures, err := us.loginAttemptRepo.GetIPFailures(client.IP)
+	if err != nil {
+		global.Logger.Error("Failed to check login failures of IP: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if ipFailures >= int64(global.Config.Auth.LoginIPMaxAttempts) {
+		return response.NewServiceErrorWithCode(429, response.ErrCodeTooManyRequests)
+	}
+	return nil
+}
+
+// recordLoginFailure counts the failure, locks the email once it reached the threshold
+// and slows the response down a little more after each failure
+func (us *userService) recordLoginFailure(email string, client dto.ClientInfoDto) {
+	auth := global.Config.Auth
+	failures, err := us.loginAttemptRepo.RecordFailure(email, client.IP, time.Duration(auth.LoginAttemptWindow)*time.Minute)
+	if err != nil {
+		global.Logger.Error("Failed to record login failure: " + err.Error())
+		return
+	}
+
+	if failures >= int64(auth.LoginMaxAttempts) {
+		if err := us.loginAttemptRepo.Lock(email, time.Duration(auth.LoginLockoutDuration)*time.Minute); err != nil {
+			global.Logger.Error("Failed to lock login: " + err.Error())
+		}
+		global.Logger.Warn(fmt.Sprintf("Login locked after %d failed attempts, last one from %s", failures, client.IP))
+	}
+
+	time.Sleep(loginDelay(failures))
+}
+
+// loginDelay doubles with each failure: 250ms, 500ms, 1s... up to loginDelayMax
+func loginDelay(failures int64) time.Duration {
+	delay := loginDelayBase
+	for i := int64(1); i < failures && delay < loginDelayMax; i++ {
+		delay *= 2
+	}
+	return min(delay, loginDelayMax)
+}
+
+func (us *userService) UnlockLogin(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult {
+	user, denied := us.adminTarget(actor, publicID)
+	if denied != nil {
+		return denied
+	}
+
+	if err := us.loginAttemptRepo.Reset(user.Email); err != nil {
+		global.Logger.Error("Failed to unlock login: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	us.audit(actor.UserID, model.AuditActionUserLoginUnlock, &user.ID, nil, client)
+
+	global.Logger.Info(fmt.Sprintf("Login of user %d has been unlocked", user.ID))
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "User login has been unlocked"})
+}
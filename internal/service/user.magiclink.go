@@ -0,0 +1,98 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/pkg/jwt"
+	"base_go_be/pkg/response"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	magicLinkLimit  = 5
+	magicLinkWindow = time.Hour
+)
+
+// RequestMagicLink emails a signed, single-use login link to the account's address
+func (us *userService) RequestMagicLink(email string) *response.ServiceResult {
+	// Same answer whether the account exists or not, so this can't be used to probe emails
+	result := response.NewServiceResult(&dto.MessageResponseDto{
+		Message: "If an account exists for this email, a login link has been sent",
+	})
+
+	// Throttled per address, known or not, so the limit doesn't reveal accounts either
+	allowed, err := us.rateLimitRepo.Allow("magic_link:"+strings.ToLower(email), magicLinkLimit, magicLinkWindow)
+	if err != nil {
+		global.Logger.Error("Failed to check magic link rate limit: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !allowed {
+		return response.NewServiceErrorWithCode(429, response.ErrCodeTooManyRequests)
+	}
+
+	user := us.userRepo.GetUserByEmail(email)
+	if user == nil || !user.IsActive {
+		return result
+	}
+
+	ttlMinutes := global.Config.Auth.MagicLinkTTL
+	token, err := jwt.GenerateToken(user.PublicID, user.Email, user.Role, "", jwt.TokenTypeMagicLink, time.Duration(ttlMinutes)*time.Minute)
+	if err != nil {
+		global.Logger.Error("Failed to generate magic link token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	sendMagicLinkMail(user.Email, user.Username, frontendLink("/auth/magic", token), ttlMinutes)
+	return result
+}
+
+// LoginMagicLink redeems the token of a magic link. It logs the user in like a
+// password login, 2FA challenge included, and proves the email is theirs
+func (us *userService) LoginMagicLink(token string, client dto.ClientInfoDto) *response.ServiceResult {
+	claims, err := jwt.ValidateToken(token, jwt.TokenTypeMagicLink)
+	if err != nil {
+		return response.NewServiceErrorWithCode(400, response.ErrInvalidToken)
+	}
+
+	user := us.userRepo.GetUserByPublicID(claims.UserID)
+	// The link is bound to the address it was sent to, in case the email changed since
+	if user == nil || user.Email != claims.Email {
+		return response.NewServiceErrorWithCode(400, response.ErrInvalidToken)
+	}
+
+	// A logout everywhere or password reset also voids the links sent before it
+	revoked, err := us.tokenRepo.IsTokenRevoked(claims.ID, user.ID, claims.IssuedAt.Time)
+	if err != nil {
+		global.Logger.Error("Failed to check token revocation: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if revoked {
+		return response.NewServiceErrorWithCode(400, response.ErrInvalidToken)
+	}
+	consumed, err := us.tokenRepo.ConsumeToken(claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		global.Logger.Error("Failed to consume magic link token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !consumed {
+		return response.NewServiceErrorWithCode(400, response.ErrInvalidToken)
+	}
+
+	if !user.IsEmailVerified() {
+		now := time.Now()
+		if _, err := us.userRepo.UpdateUser(user.ID, &model.User{EmailVerifiedAt: &now}); err != nil {
+			global.Logger.Error("Failed to mark email as verified: " + err.Error())
+			return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+		}
+		user.EmailVerifiedAt = &now
+		global.Logger.Info(fmt.Sprintf("Email of user %d has been verified by magic link", user.ID))
+	}
+
+	if statusResult := checkAccountStatus(user); statusResult != nil {
+		return statusResult
+	}
+	return us.completeLogin(user, client)
+}
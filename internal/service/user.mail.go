@@ -0,0 +1,96 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/pkg/mailer"
+	"fmt"
+	"net/url"
+)
+
+// sendMail delivers the email in the background: callers never wait on the mail
+// server, and response times don't reveal whether an email was actually sent
+func sendMail(to string, subject string, body string) {
+	if global.Mailer == nil {
+		global.Logger.Warn("Mailer is not initialized, email to " + to + " dropped")
+		return
+	}
+	go func() {
+		err := global.Mailer.Send(mailer.Message{To: []string{to}, Subject: subject, Body: body})
+		if err != nil {
+			global.Logger.Error("Failed to send email to " + to + ": " + err.Error())
+		}
+	}()
+}
+
+// frontendLink builds a link to a frontend page carrying a one-time token
+func frontendLink(path string, token string) string {
+	return fmt.Sprintf("%s%s?token=%s", global.Config.Server.FrontendURL, path, url.QueryEscape(token))
+}
+
+func sendPasswordResetMail(to string, username string, link string, ttlMinutes int) {
+	body := fmt.Sprintf(`Hi %s,
+
+We received a request to reset your password. Open the link below to choose a new one:
+
+%s
+
+The link expires in %d minutes and can only be used once. If you didn't ask for this, you can ignore this email.
+`, username, link, ttlMinutes)
+	sendMail(to, "Reset your password", body)
+}
+
+func sendVerificationMail(to string, username string, link string, ttlMinutes int) {
+	body := fmt.Sprintf(`Hi %s,
+
+Please confirm your email address by opening the link below:
+
+%s
+
+The link expires in %d minutes. If you didn't create an account, you can ignore this email.
+`, username, link, ttlMinutes)
+	sendMail(to, "Verify your email address", body)
+}
+
+func sendPasswordChangedMail(to string, username string, device string, ip string) {
+	body := fmt.Sprintf(`Hi %s,
+
+The password of your account was changed from %s (IP %s). Every other device has been logged out.
+
+If this wasn't you, reset your password right away with "Forgot password" on the login page.
+`, username, device, ip)
+	sendMail(to, "Your password was changed", body)
+}
+
+func sendEmailChangeMail(to string, username string, link string, ttlMinutes int) {
+	body := fmt.Sprintf(`Hi %s,
+
+We received a request to use this address for your account. Open the link below to confirm it:
+
+%s
+
+The link expires in %d minutes and can only be used once. Until then your account keeps its current email. If you didn't ask for this, you can ignore this email.
+`, username, link, ttlMinutes)
+	sendMail(to, "Confirm your new email address", body)
+}
+
+func sendEmailChangeNoticeMail(to string, username string, newEmail string) {
+	body := fmt.Sprintf(`Hi %s,
+
+We received a request to change the email of your account to %s. The change takes effect once confirmed from that address.
+
+If you didn't ask for this, change your password right away: someone else may know it.
+`, username, newEmail)
+	sendMail(to, "Your email address is being changed", body)
+}
+
+func sendMagicLinkMail(to string, username string, link string, ttlMinutes int) {
+	body := fmt.Sprintf(`Hi %s,
+
+Open the link below to log in to your account:
+
+%s
+
+The link expires in %d minutes and can only be used once. If you didn't ask for this, you can ignore this email.
+`, username, link, ttlMinutes)
+	sendMail(to, "Your login link", body)
+}
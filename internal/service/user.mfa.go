@@ -0,0 +1,359 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/pkg/config"
+	"base_go_be/pkg/jwt"
+	"base_go_be/pkg/response"
+	"base_go_be/pkg/secretbox"
+	"base_go_be/pkg/totp"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	recoveryCodeCount = 10
+	mfaAttemptLimit   = 5 // wrong codes per user within config.JWT.MFAExpiry
+)
+
+// mfaRequiredForRole tells whether the role policy forces 2FA on the role
+func mfaRequiredForRole(role string) bool {
+	return slices.Contains(global.Config.Auth.MFARequiredRoles, role)
+}
+
+// completeLogin finishes a login whose first factor was verified: users with 2FA, or
+// whose role requires it, get a challenge token instead of the real tokens
+func (us *userService) completeLogin(user *model.User, client dto.ClientInfoDto) *response.ServiceResult {
+	mfa := us.mfaRepo.GetUserMfa(user.ID)
+	enabled := mfa != nil && mfa.IsEnabled()
+	if !enabled && !mfaRequiredForRole(user.Role) {
+		return us.generateAuthResponse(user, client)
+	}
+
+	mfaToken, err := jwt.GenerateToken(user.PublicID, user.Email, user.Role, "", jwt.TokenTypeMFA, config.JWT.MFAExpiry)
+	if err != nil {
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	return response.NewServiceResult(&dto.MfaChallengeResponseDto{
+		MfaRequired:           enabled,
+		MfaEnrollmentRequired: !enabled,
+		MfaToken:              mfaToken,
+		ExpiresIn:             int(config.JWT.MFAExpiry.Seconds()),
+	})
+}
+
+func (us *userService) LoginMfa(mfaToken string, code string, client dto.ClientInfoDto) *response.ServiceResult {
+	user, claims, denied := us.userFromMfaToken(mfaToken)
+	if denied != nil {
+		return denied
+	}
+	if denied := us.checkMfaAttempts(user.ID); denied != nil {
+		return denied
+	}
+
+	// The challenge token is spent before the code is checked, so of two concurrent
+	// requests only one can log in. A wrong code means logging in again
+	consumed, err := us.tokenRepo.ConsumeToken(claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		global.Logger.Error("Failed to consume MFA token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !consumed {
+		return response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+
+	mfa := us.mfaRepo.GetUserMfa(user.ID)
+	if mfa == nil {
+		return response.NewServiceErrorWithCode(400, response.ErrCodeMfaNotEnabled)
+	}
+
+	var recoveryCodes []string
+	if mfa.IsEnabled() {
+		ok, err := us.verifyMfaCode(mfa, code)
+		if err != nil {
+			global.Logger.Error("Failed to verify MFA code: " + err.Error())
+			return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+		}
+		if !ok {
+			return response.NewServiceErrorWithCode(401, response.ErrCodeInvalidMfaCode)
+		}
+	} else {
+		// The challenge was an enrollment: the first valid code confirms it
+		codes, result := us.confirmMfaEnrollment(mfa, code)
+		if result != nil {
+			return result
+		}
+		recoveryCodes = codes
+	}
+
+	result := us.generateAuthResponse(user, client)
+	if authResponse, ok := result.Data.(*dto.AuthResponseDto); ok {
+		authResponse.RecoveryCodes = recoveryCodes
+	}
+	return result
+}
+
+func (us *userService) SetupMfaForLogin(mfaToken string) *response.ServiceResult {
+	user, _, denied := us.userFromMfaToken(mfaToken)
+	if denied != nil {
+		return denied
+	}
+	return us.startMfaEnrollment(user)
+}
+
+func (us *userService) SetupMfa(userID uint) *response.ServiceResult {
+	user := us.userRepo.GetUserByID(userID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+	}
+	return us.startMfaEnrollment(user)
+}
+
+func (us *userService) ConfirmMfa(userID uint, code string) *response.ServiceResult {
+	if denied := us.checkMfaAttempts(userID); denied != nil {
+		return denied
+	}
+
+	mfa := us.mfaRepo.GetUserMfa(userID)
+	if mfa == nil {
+		return response.NewServiceErrorWithCode(400, response.ErrCodeMfaNotEnabled)
+	}
+	if mfa.IsEnabled() {
+		return response.NewServiceErrorWithCode(409, response.ErrCodeMfaAlreadyEnabled)
+	}
+
+	recoveryCodes, result := us.confirmMfaEnrollment(mfa, code)
+	if result != nil {
+		return result
+	}
+	return response.NewServiceResult(&dto.MfaRecoveryCodesResponseDto{RecoveryCodes: recoveryCodes})
+}
+
+func (us *userService) DisableMfa(userID uint, code string) *response.ServiceResult {
+	user := us.userRepo.GetUserByID(userID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+	}
+	if mfaRequiredForRole(user.Role) {
+		return response.NewServiceErrorWithCode(403, response.ErrCodeMfaRequired)
+	}
+
+	if result := us.verifyEnabledMfa(userID, code); result != nil {
+		return result
+	}
+
+	if err := us.mfaRepo.DeleteUserMfa(userID); err != nil {
+		global.Logger.Error("Failed to disable MFA: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	global.Logger.Info(fmt.Sprintf("Two-factor authentication of user %d has been disabled", userID))
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "Two-factor authentication has been disabled"})
+}
+
+func (us *userService) RegenerateRecoveryCodes(userID uint, code string) *response.ServiceResult {
+	if result := us.verifyEnabledMfa(userID, code); result != nil {
+		return result
+	}
+
+	recoveryCodes, err := us.newRecoveryCodes(userID)
+	if err != nil {
+		global.Logger.Error("Failed to create recovery codes: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	return response.NewServiceResult(&dto.MfaRecoveryCodesResponseDto{RecoveryCodes: recoveryCodes})
+}
+
+// ResetMfa removes the 2FA of a user who lost both the authenticator and the recovery codes.
+// If the role requires 2FA, the next login asks to enroll again
+func (us *userService) ResetMfa(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult {
+	user, denied := us.adminTarget(actor, publicID)
+	if denied != nil {
+		return denied
+	}
+
+	if err := us.mfaRepo.DeleteUserMfa(user.ID); err != nil {
+		global.Logger.Error("Failed to reset MFA: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	us.audit(actor.UserID, model.AuditActionUserMfaReset, &user.ID, nil, client)
+
+	global.Logger.Info(fmt.Sprintf("Two-factor authentication of user %d has been reset", user.ID))
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "Two-factor authentication has been reset"})
+}
+
+// userFromMfaToken resolves the user of a challenge token that wasn't used yet
+func (us *userService) userFromMfaToken(mfaToken string) (*model.User, *jwt.JWTClaims, *response.ServiceResult) {
+	claims, err := jwt.ValidateToken(mfaToken, jwt.TokenTypeMFA)
+	if err != nil {
+		return nil, nil, response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+
+	user := us.userRepo.GetUserByPublicID(claims.UserID)
+	if user == nil {
+		return nil, nil, response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+
+	revoked, err := us.tokenRepo.IsTokenRevoked(claims.ID, user.ID, claims.IssuedAt.Time)
+	if err != nil {
+		global.Logger.Error("Failed to check token revocation: " + err.Error())
+		return nil, nil, response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if revoked {
+		return nil, nil, response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+	if statusResult := checkAccountStatus(user); statusResult != nil {
+		return nil, nil, statusResult
+	}
+	return user, claims, nil
+}
+
+// checkMfaAttempts caps the codes a user can try, 6 digits being easy to brute force otherwise
+func (us *userService) checkMfaAttempts(userID uint) *response.ServiceResult {
+	allowed, err := us.rateLimitRepo.Allow("mfa:"+strconv.FormatUint(uint64(userID), 10), mfaAttemptLimit, config.JWT.MFAExpiry)
+	if err != nil {
+		global.Logger.Error("Failed to check MFA rate limit: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !allowed {
+		return response.NewServiceErrorWithCode(429, response.ErrCodeTooManyRequests)
+	}
+	return nil
+}
+
+// startMfaEnrollment stores a new, not yet confirmed, TOTP secret for the user
+func (us *userService) startMfaEnrollment(user *model.User) *response.ServiceResult {
+	if mfa := us.mfaRepo.GetUserMfa(user.ID); mfa != nil && mfa.IsEnabled() {
+		return response.NewServiceErrorWithCode(409, response.ErrCodeMfaAlreadyEnabled)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	encrypted, err := secretbox.Encrypt(global.Config.Security.EncryptionKey, secret)
+	if err != nil {
+		global.Logger.Error("Failed to encrypt TOTP secret: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	if err := us.mfaRepo.SaveUserMfa(&model.UserMfa{UserID: user.ID, TotpSecret: encrypted}); err != nil {
+		global.Logger.Error("Failed to save MFA enrollment: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	return response.NewServiceResult(&dto.MfaSetupResponseDto{
+		Secret:     secret,
+		OtpauthURI: totp.KeyURI(global.Config.Auth.MFAIssuer, user.Email, secret),
+	})
+}
+
+// confirmMfaEnrollment enables the pending enrollment if code matches its secret, and
+// returns the first recovery codes
+func (us *userService) confirmMfaEnrollment(mfa *model.UserMfa, code string) ([]string, *response.ServiceResult) {
+	ok, err := us.verifyTotpCode(mfa, code)
+	if err != nil {
+		global.Logger.Error("Failed to verify TOTP code: " + err.Error())
+		return nil, response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !ok {
+		return nil, response.NewServiceErrorWithCode(401, response.ErrCodeInvalidMfaCode)
+	}
+
+	now := time.Now()
+	mfa.EnabledAt = &now
+	if err := us.mfaRepo.SaveUserMfa(mfa); err != nil {
+		global.Logger.Error("Failed to enable MFA: " + err.Error())
+		return nil, response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	recoveryCodes, err := us.newRecoveryCodes(mfa.UserID)
+	if err != nil {
+		global.Logger.Error("Failed to create recovery codes: " + err.Error())
+		return nil, response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	global.Logger.Info(fmt.Sprintf("Two-factor authentication of user %d has been enabled", mfa.UserID))
+	return recoveryCodes, nil
+}
+
+// verifyEnabledMfa checks a code of a user who must have 2FA enabled
+func (us *userService) verifyEnabledMfa(userID uint, code string) *response.ServiceResult {
+	if denied := us.checkMfaAttempts(userID); denied != nil {
+		return denied
+	}
+
+	mfa := us.mfaRepo.GetUserMfa(userID)
+	if mfa == nil || !mfa.IsEnabled() {
+		return response.NewServiceErrorWithCode(400, response.ErrCodeMfaNotEnabled)
+	}
+
+	ok, err := us.verifyMfaCode(mfa, code)
+	if err != nil {
+		global.Logger.Error("Failed to verify MFA code: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !ok {
+		return response.NewServiceErrorWithCode(401, response.ErrCodeInvalidMfaCode)
+	}
+	return nil
+}
+
+// verifyMfaCode accepts either a TOTP code or an unused recovery code
+func (us *userService) verifyMfaCode(mfa *model.UserMfa, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return us.verifyTotpCode(mfa, code)
+	}
+	return us.mfaRepo.UseRecoveryCode(mfa.UserID, hashRecoveryCode(code))
+}
+
+// verifyTotpCode checks the code against the secret, each time step being usable once
+func (us *userService) verifyTotpCode(mfa *model.UserMfa, code string) (bool, error) {
+	secret, err := secretbox.Decrypt(global.Config.Security.EncryptionKey, mfa.TotpSecret)
+	if err != nil {
+		return false, err
+	}
+	step, ok := totp.Validate(secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return false, nil
+	}
+	return us.mfaRepo.UseTotpStep(mfa.UserID, step)
+}
+
+// newRecoveryCodes replaces the recovery codes of the user and returns them in clear
+func (us *userService) newRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(base32.StdEncoding.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashRecoveryCode(raw))
+	}
+
+	if err := us.mfaRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode ignores case and dashes, so codes can be typed loosely
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
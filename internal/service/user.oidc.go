@@ -0,0 +1,233 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/oidc"
+	"base_go_be/pkg/passwd"
+	"base_go_be/pkg/response"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// oidcStateTTL is how long the user has to log in at the provider
+	oidcStateTTL = 10 * time.Minute
+	// oidcTimeout bounds the calls made to the provider
+	oidcTimeout = 10 * time.Second
+	// maxUsernameLength is the size of the username column
+	maxUsernameLength = 100
+	// usernameSuffixAttempts bounds the random suffixes tried on a taken username
+	usernameSuffixAttempts = 5
+)
+
+// OidcAuthorize starts an external login: it remembers a state, nonce and PKCE verifier
+// and returns the provider URL to send the user to
+func (us *userService) OidcAuthorize(providerName string) *response.ServiceResult {
+	provider := oidc.GetProvider(providerName)
+	if provider == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeOidcProviderNotFound)
+	}
+
+	nonce, err := randomSecret()
+	if err != nil {
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	state := &repo.OidcState{Provider: provider.Name(), Nonce: nonce, Verifier: oidc.GenerateVerifier()}
+	stateID, err := us.identityRepo.CreateOidcState(state, oidcStateTTL)
+	if err != nil {
+		global.Logger.Error("Failed to store OIDC state: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcTimeout)
+	defer cancel()
+	authURL, err := provider.AuthCodeURL(ctx, stateID, state.Nonce, state.Verifier)
+	if err != nil {
+		global.Logger.Error("Failed to reach OIDC provider: " + err.Error())
+		return response.NewServiceErrorWithCode(502, response.ErrCodeOidcLoginFailed)
+	}
+	return response.NewServiceResult(&dto.OidcAuthorizeResponseDto{AuthorizationURL: authURL})
+}
+
+// OidcCallback completes an external login with the code the provider sent back, then
+// logs in the linked user like a password login would
+func (us *userService) OidcCallback(providerName string, code string, stateID string, client dto.ClientInfoDto) *response.ServiceResult {
+	provider := oidc.GetProvider(providerName)
+	if provider == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeOidcProviderNotFound)
+	}
+
+	state, err := us.identityRepo.ConsumeOidcState(stateID)
+	if err != nil {
+		global.Logger.Error("Failed to consume OIDC state: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if state == nil || state.Provider != provider.Name() {
+		return response.NewServiceErrorWithCode(400, response.ErrCodeInvalidOidcState)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcTimeout)
+	defer cancel()
+	identity, err := provider.Exchange(ctx, code, state.Verifier, state.Nonce)
+	if err != nil {
+		global.Logger.Warn(fmt.Sprintf("OIDC login with %s failed: %s", provider.Name(), err.Error()))
+		return response.NewServiceErrorWithCode(401, response.ErrCodeOidcLoginFailed)
+	}
+
+	user, result := us.findOidcUser(provider.Name(), identity)
+	if result != nil {
+		return result
+	}
+	if statusResult := checkAccountStatus(user); statusResult != nil {
+		return statusResult
+	}
+	return us.completeLogin(user, client)
+}
+
+// findOidcUser returns the user linked to the identity. An identity seen for the first
+// time is linked to the user with the same email, only if the provider verified it,
+// or to a new user when auto registration is on
+func (us *userService) findOidcUser(providerName string, identity *oidc.Identity) (*model.User, *response.ServiceResult) {
+	if linked := us.identityRepo.GetUserIdentity(providerName, identity.Subject); linked != nil {
+		user := us.userRepo.GetUserByID(linked.UserID)
+		if user == nil {
+			return nil, response.NewServiceErrorWithCode(401, response.ErrCodeOidcLoginFailed)
+		}
+		return user, nil
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, response.NewServiceErrorWithCode(403, response.ErrCodeOidcEmailNotVerified)
+	}
+	email := identity.Email
+
+	user := us.userRepo.GetUserByEmail(email)
+	switch {
+	case user == nil:
+		if !global.Config.Auth.OIDCAutoRegister {
+			return nil, response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+		}
+		created, denied := us.createOidcUser(email, identity.Name)
+		if denied != nil {
+			return nil, denied
+		}
+		user = created
+	case !user.IsEmailVerified():
+		// Whoever registered this email without verifying it may not own it: the
+		// provider proved who does, so their password and sessions are dropped
+		if err := us.claimUnverifiedUser(user); err != nil {
+			global.Logger.Error("Failed to claim unverified user: " + err.Error())
+			return nil, response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+		}
+	}
+
+	err := us.identityRepo.CreateUserIdentity(&model.UserIdentity{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  identity.Subject,
+		Email:    email,
+	})
+	if err != nil {
+		global.Logger.Error("Failed to link OIDC identity: " + err.Error())
+		return nil, response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	global.Logger.Info(fmt.Sprintf("Linked %s identity to user %d", providerName, user.ID))
+	return user, nil
+}
+
+// createOidcUser registers a user from a verified external identity, named after it.
+// It gets a random password, a real one can be set with the forgot password flow
+func (us *userService) createOidcUser(email string, name string) (*model.User, *response.ServiceResult) {
+	password, err := unusablePassword()
+	if err != nil {
+		return nil, response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	username, err := us.availableUsername(name)
+	if err != nil {
+		global.Logger.Error("Failed to pick a username for OIDC login: " + err.Error())
+		return nil, response.NewServiceErrorWithCode(409, response.ErrCodeUserHasExists)
+	}
+	now := time.Now()
+	user := &model.User{
+		Username:        username,
+		Email:           email,
+		Password:        password,
+		IsActive:        true,
+		Role:            model.RoleUser,
+		EmailVerifiedAt: &now,
+	}
+	if _, err := us.userRepo.CreateUser(user); err != nil {
+		// Another request took the email or username since they were checked
+		if us.userRepo.GetUserByEmail(email) != nil || us.userRepo.GetUserByUsername(username) != nil {
+			return nil, response.NewServiceErrorWithCode(409, response.ErrCodeUserHasExists)
+		}
+		global.Logger.Error("Failed to create user from OIDC login: " + err.Error())
+		return nil, response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	return user, nil
+}
+
+// availableUsername returns name, or name with a random suffix when another user has
+// it already, cut to fit the username column
+func (us *userService) availableUsername(name string) (string, error) {
+	const suffixLength = len("-") + 6
+	base := []rune(name)
+	if len(base) > maxUsernameLength-suffixLength {
+		base = base[:maxUsernameLength-suffixLength]
+	}
+	username := string(base)
+	for attempt := 0; attempt < usernameSuffixAttempts; attempt++ {
+		if us.userRepo.GetUserByUsername(username) == nil {
+			return username, nil
+		}
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", err
+		}
+		username = string(base) + "-" + hex.EncodeToString(suffix)
+	}
+	return "", fmt.Errorf("username %q and %d variants taken", string(base), usernameSuffixAttempts-1)
+}
+
+func (us *userService) claimUnverifiedUser(user *model.User) error {
+	password, err := unusablePassword()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if _, err := us.userRepo.UpdateUser(user.ID, &model.User{Password: password, EmailVerifiedAt: &now}); err != nil {
+		return err
+	}
+	user.EmailVerifiedAt = &now
+	return us.revokeAllSessions(user.ID)
+}
+
+// unusablePassword returns the hash of a random password nobody knows
+func unusablePassword() (string, error) {
+	secret, err := randomSecret()
+	if err != nil {
+		return "", err
+	}
+	return passwd.Hash(secret)
+}
+
+// randomSecret returns a URL-safe random string with 256 bits of entropy
+func randomSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/passkey"
+	"base_go_be/pkg/response"
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// passkeyCeremonyTTL is how long the user has to answer the authenticator prompt
+const passkeyCeremonyTTL = 5 * time.Minute
+
+func (us *userService) BeginPasskeyRegistration(userID uint) *response.ServiceResult {
+	user := us.userRepo.GetUserByID(userID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+	}
+	passkeyUser, _, err := us.passkeyUser(user, passkey.UserHandle(user.PublicID))
+	if err != nil {
+		global.Logger.Error("Failed to load passkeys: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	rp, err := passkey.New(global.Config.WebAuthn)
+	if err != nil {
+		global.Logger.Error("Invalid WebAuthn configuration: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	options, session, err := rp.BeginRegistration(passkeyUser)
+	if err != nil {
+		global.Logger.Error("Failed to begin passkey registration: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	return us.startPasskeyCeremony(user.ID, session, options)
+}
+
+func (us *userService) FinishPasskeyRegistration(userID uint, registerDto dto.PasskeyRegisterRequestDto) *response.ServiceResult {
+	ceremony, result := us.consumePasskeyCeremony(registerDto.CeremonyID)
+	if result != nil {
+		return result
+	}
+	if ceremony.UserID != userID {
+		return response.NewServiceErrorWithCode(400, response.ErrCodeInvalidPasskeyCeremony)
+	}
+	user := us.userRepo.GetUserByID(userID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+	}
+	passkeyUser, _, err := us.passkeyUser(user, passkey.UserHandle(user.PublicID))
+	if err != nil {
+		global.Logger.Error("Failed to load passkeys: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	rp, err := passkey.New(global.Config.WebAuthn)
+	if err != nil {
+		global.Logger.Error("Invalid WebAuthn configuration: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	credential, err := rp.FinishRegistration(passkeyUser, ceremony.Session, registerDto.Credential)
+	if err != nil {
+		global.Logger.Warn(fmt.Sprintf("Passkey registration for user %d rejected: %s", userID, err.Error()))
+		return response.NewServiceErrorWithCode(400, response.ErrCodeInvalidPasskey)
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+	stored := &model.Passkey{
+		UserID:          userID,
+		Name:            registerDto.Name,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      transports,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+	if err := us.passkeyRepo.CreatePasskey(stored); err != nil {
+		global.Logger.Error("Failed to store passkey: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	global.Logger.Info(fmt.Sprintf("User %d registered passkey %d", userID, stored.ID))
+	passkeyResponse := toPasskeyResponse(stored)
+	return response.NewServiceResult(&passkeyResponse)
+}
+
+// BeginPasskeyLogin starts a login without username: the authenticator offers the
+// passkeys it holds for this site
+func (us *userService) BeginPasskeyLogin() *response.ServiceResult {
+	rp, err := passkey.New(global.Config.WebAuthn)
+	if err != nil {
+		global.Logger.Error("Invalid WebAuthn configuration: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	options, session, err := rp.BeginLogin()
+	if err != nil {
+		global.Logger.Error("Failed to begin passkey login: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	return us.startPasskeyCeremony(0, session, options)
+}
+
+// FinishPasskeyLogin verifies the assertion and logs the user in. A passkey with user
+// verification is already two factors, so no 2FA challenge follows
+func (us *userService) FinishPasskeyLogin(loginDto dto.PasskeyLoginRequestDto, client dto.ClientInfoDto) *response.ServiceResult {
+	ceremony, result := us.consumePasskeyCeremony(loginDto.CeremonyID)
+	if result != nil {
+		return result
+	}
+	if ceremony.UserID != 0 {
+		return response.NewServiceErrorWithCode(400, response.ErrCodeInvalidPasskeyCeremony)
+	}
+
+	rp, err := passkey.New(global.Config.WebAuthn)
+	if err != nil {
+		global.Logger.Error("Invalid WebAuthn configuration: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	var user *model.User
+	var passkeys []model.Passkey
+	_, credential, err := rp.FinishLogin(ceremony.Session, loginDto.Credential, func(handle []byte) (*passkey.User, error) {
+		if user = us.userByPasskeyHandle(handle); user == nil {
+			return nil, nil
+		}
+		passkeyUser, list, err := us.passkeyUser(user, handle)
+		passkeys = list
+		return passkeyUser, err
+	})
+	if err != nil {
+		global.Logger.Warn("Passkey login rejected: " + err.Error())
+		return response.NewServiceErrorWithCode(401, response.ErrCodeInvalidPasskey)
+	}
+
+	for i := range passkeys {
+		if bytes.Equal(passkeys[i].CredentialID, credential.ID) {
+			passkeys[i].SignCount = credential.Authenticator.SignCount
+			passkeys[i].BackupState = credential.Flags.BackupState
+			if err := us.passkeyRepo.UpdatePasskeyUsage(&passkeys[i]); err != nil {
+				global.Logger.Error("Failed to update passkey: " + err.Error())
+				return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+			}
+		}
+	}
+
+	if statusResult := checkAccountStatus(user); statusResult != nil {
+		return statusResult
+	}
+	return us.generateAuthResponse(user, client)
+}
+
+func (us *userService) ListPasskeys(userID uint) *response.ServiceResult {
+	passkeys, err := us.passkeyRepo.GetListPasskey(userID)
+	if err != nil {
+		global.Logger.Error("Failed to get passkeys from repository: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	passkeyDTOs := make([]dto.PasskeyResponseDto, 0, len(passkeys))
+	for i := range passkeys {
+		passkeyDTOs = append(passkeyDTOs, toPasskeyResponse(&passkeys[i]))
+	}
+	return response.NewServiceResult(passkeyDTOs)
+}
+
+func (us *userService) DeletePasskey(userID uint, id uint) *response.ServiceResult {
+	deleted, err := us.passkeyRepo.DeletePasskey(userID, id)
+	if err != nil {
+		global.Logger.Error("Failed to delete passkey: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !deleted {
+		return response.NewServiceErrorWithCode(404, response.ErrCodePasskeyNotFound)
+	}
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "Passkey has been removed"})
+}
+
+func (us *userService) startPasskeyCeremony(userID uint, session *webauthn.SessionData, options any) *response.ServiceResult {
+	ceremonyID, err := us.passkeyRepo.CreatePasskeyCeremony(&repo.PasskeyCeremony{UserID: userID, Session: *session}, passkeyCeremonyTTL)
+	if err != nil {
+		global.Logger.Error("Failed to store passkey ceremony: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	return response.NewServiceResult(&dto.PasskeyOptionsResponseDto{CeremonyID: ceremonyID, Options: options})
+}
+
+func (us *userService) consumePasskeyCeremony(ceremonyID string) (*repo.PasskeyCeremony, *response.ServiceResult) {
+	ceremony, err := us.passkeyRepo.ConsumePasskeyCeremony(ceremonyID)
+	if err != nil {
+		global.Logger.Error("Failed to consume passkey ceremony: " + err.Error())
+		return nil, response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if ceremony == nil {
+		return nil, response.NewServiceErrorWithCode(400, response.ErrCodeInvalidPasskeyCeremony)
+	}
+	return ceremony, nil
+}
+
+// userByPasskeyHandle returns the user a discoverable login names, nil if unknown
+func (us *userService) userByPasskeyHandle(handle []byte) *model.User {
+	publicID := string(handle)
+	if !model.IsPublicID(publicID) {
+		return nil
+	}
+	return us.userRepo.GetUserByPublicID(publicID)
+}
+
+// passkeyUser loads the user's passkeys as WebAuthn credentials, with the handle they
+// name the user by
+func (us *userService) passkeyUser(user *model.User, handle []byte) (*passkey.User, []model.Passkey, error) {
+	passkeys, err := us.passkeyRepo.GetListPasskey(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	credentials := make([]webauthn.Credential, 0, len(passkeys))
+	for _, p := range passkeys {
+		transports := make([]protocol.AuthenticatorTransport, 0, len(p.Transports))
+		for _, transport := range p.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+		credentials = append(credentials, webauthn.Credential{
+			ID:              p.CredentialID,
+			PublicKey:       p.PublicKey,
+			AttestationType: p.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: p.BackupEligible,
+				BackupState:    p.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    p.AAGUID,
+				SignCount: p.SignCount,
+			},
+		})
+	}
+	return &passkey.User{
+		Handle:      handle,
+		Name:        user.Email,
+		DisplayName: user.Username,
+		Credentials: credentials,
+	}, passkeys, nil
+}
+
+func toPasskeyResponse(p *model.Passkey) dto.PasskeyResponseDto {
+	return dto.PasskeyResponseDto{
+		Id:         p.ID,
+		Name:       p.Name,
+		Synced:     p.BackupState,
+		LastUsedAt: p.LastUsedAt,
+		CreatedAt:  p.CreatedAt,
+	}
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/passwd"
+	"base_go_be/pkg/response"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	forgotPasswordLimit   = 3
+	forgotPasswordIPLimit = 20
+	forgotPasswordWindow  = time.Hour
+)
+
+func (us *userService) ForgotPassword(email string, client dto.ClientInfoDto) *response.ServiceResult {
+	// Same answer whether the account exists or not, so this can't be used to probe emails
+	result := response.NewServiceResult(&dto.MessageResponseDto{
+		Message: "If an account exists for this email, a password reset link has been sent",
+	})
+
+	// Throttled per address, known or not, so the limit doesn't reveal accounts either
+	allowed, err := us.rateLimitRepo.Allow("forgot_password:"+strings.ToLower(email), forgotPasswordLimit, forgotPasswordWindow)
+	if err != nil {
+		global.Logger.Error("Failed to check forgot password rate limit: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !allowed {
+		return response.NewServiceErrorWithCode(429, response.ErrCodeTooManyRequests)
+	}
+	// and per IP, so one client can't flood many addresses
+	allowed, err = us.rateLimitRepo.Allow("forgot_password_ip:"+client.IP, forgotPasswordIPLimit, forgotPasswordWindow)
+	if err != nil {
+		global.Logger.Error("Failed to check forgot password rate limit: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !allowed {
+		return response.NewServiceErrorWithCode(429, response.ErrCodeTooManyRequests)
+	}
+
+	user := us.userRepo.GetUserByEmail(email)
+	if user == nil {
+		return result
+	}
+
+	if err := us.sendPasswordReset(user); err != nil {
+		global.Logger.Error("Failed to create password reset token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	return result
+}
+
+// sendPasswordReset emails the user a single-use link to choose a new password
+func (us *userService) sendPasswordReset(user *model.User) error {
+	ttlMinutes := global.Config.Auth.PasswordResetTTL
+	token, err := us.tokenRepo.CreateOneTimeToken(repo.PurposePasswordReset, repo.OneTimeToken{UserID: user.ID}, time.Duration(ttlMinutes)*time.Minute)
+	if err != nil {
+		return err
+	}
+	sendPasswordResetMail(user.Email, user.Username, frontendLink("/reset-password", token), ttlMinutes)
+	return nil
+}
+
+func (us *userService) ResetPassword(resetDto dto.ResetPasswordRequestDto) *response.ServiceResult {
+	// Checked before the token is used up, so a rejected password doesn't cost the link.
+	// Only the rules about the account itself have to wait for the user
+	if policyResult := checkPasswordPolicy(resetDto.Password, "", ""); policyResult != nil {
+		return policyResult
+	}
+
+	resetToken, err := us.tokenRepo.ConsumeOneTimeToken(repo.PurposePasswordReset, resetDto.Token)
+	if err != nil {
+		global.Logger.Error("Failed to consume password reset token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if resetToken == nil {
+		return response.NewServiceErrorWithCode(400, response.ErrInvalidToken)
+	}
+
+	user := us.userRepo.GetUserByID(resetToken.UserID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(400, response.ErrInvalidToken)
+	}
+
+	if policyResult := checkPasswordPolicy(resetDto.Password, user.Email, user.Username); policyResult != nil {
+		return policyResult
+	}
+	hashedPassword, err := passwd.Hash(resetDto.Password)
+	if err != nil {
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if _, err := us.userRepo.UpdateUser(user.ID, &model.User{Password: hashedPassword}); err != nil {
+		global.Logger.Error("Failed to update password: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	// Proving ownership of the email is enough to lift a lockout
+	if err := us.loginAttemptRepo.Reset(user.Email); err != nil {
+		global.Logger.Error("Failed to reset login failures: " + err.Error())
+	}
+
+	// Whoever knew the old password may still hold tokens: log out every session
+	if err := us.revokeAllSessions(user.ID); err != nil {
+		global.Logger.Error("Failed to revoke user sessions: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	global.Logger.Info(fmt.Sprintf("Password of user %d has been reset", user.ID))
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "Password has been reset, please log in again"})
+}
+
+// ChangePassword sets a new password once the current one is confirmed. Every other
+// session is logged out, in case someone else knew the old password, and the user is
+// notified by email
+func (us *userService) ChangePassword(userID uint, currentSessionID string, changeDto dto.ChangePasswordRequestDto, client dto.ClientInfoDto) *response.ServiceResult {
+	user := us.userRepo.GetUserByID(userID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+	}
+	if denied := us.verifyCurrentPassword(user, changeDto.CurrentPassword, client); denied != nil {
+		return denied
+	}
+	if policyResult := checkPasswordPolicy(changeDto.NewPassword, user.Email, user.Username); policyResult != nil {
+		return policyResult
+	}
+
+	hashedPassword, err := passwd.Hash(changeDto.NewPassword)
+	if err != nil {
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if _, err := us.userRepo.UpdateUser(user.ID, &model.User{Password: hashedPassword}); err != nil {
+		global.Logger.Error("Failed to update password: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	if _, err := us.revokeOtherSessions(user.ID, currentSessionID); err != nil {
+		global.Logger.Error("Failed to revoke other sessions: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	sendPasswordChangedMail(user.Email, user.Username, deviceName(client.UserAgent), client.IP)
+
+	global.Logger.Info(fmt.Sprintf("Password of user %d has been changed", user.ID))
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "Password has been changed, other sessions have been logged out"})
+}
+
+// verifyCurrentPassword checks the password the signed-in user typed to confirm a
+// sensitive change, nil meaning it matches. Failures count like failed logins, so a
+// stolen access token can't be used to guess the password
+func (us *userService) verifyCurrentPassword(user *model.User, password string, client dto.ClientInfoDto) *response.ServiceResult {
+	if denied := us.checkLoginAllowed(user.Email, client); denied != nil {
+		return denied
+	}
+
+	match, rehash, err := passwd.Verify(user.Password, password)
+	if err != nil {
+		global.Logger.Error("Failed to verify password hash: " + err.Error())
+	}
+	if !match {
+		us.recordLoginFailure(user.Email, client)
+		return response.NewServiceErrorWithCode(401, response.ErrCodeInvalidLogin)
+	}
+	if rehash {
+		us.rehashPassword(user.ID, password)
+	}
+	return nil
+}
+
+// rehashPassword stores a fresh argon2id hash of the password. A failure only means
+// the old hash is kept until the next login
+func (us *userService) rehashPassword(userID uint, password string) {
+	hashedPassword, err := passwd.Hash(password)
+	if err == nil {
+		_, err = us.userRepo.UpdateUser(userID, &model.User{Password: hashedPassword})
+	}
+	if err != nil {
+		global.Logger.Error(fmt.Sprintf("Failed to rehash password of user %d: %s", userID, err.Error()))
+		return
+	}
+	global.Logger.Info(fmt.Sprintf("Password hash of user %d upgraded", userID))
+}
+
+// checkPasswordPolicy tells whether the password may be set for the account, nil
+// meaning yes. Every broken rule is listed so the user can fix them all at once
+func checkPasswordPolicy(password string, email string, username string) *response.ServiceResult {
+	violations := passwd.GetPolicy().Check(password, email, username)
+	if len(violations) == 0 {
+		return nil
+	}
+	errorDto := dto.PasswordPolicyErrorDto{Violations: make([]dto.PasswordViolationDto, 0, len(violations))}
+	for _, v := range violations {
+		errorDto.Violations = append(errorDto.Violations, dto.PasswordViolationDto{Rule: v.Rule, Message: v.Message})
+	}
+	return response.NewServiceErrorWithData(422, response.ErrCodeWeakPassword, &errorDto)
+}
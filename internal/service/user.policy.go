@@ -0,0 +1,67 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// Actor is the authenticated caller a service call is made on behalf of.
+// A nil *Actor is an anonymous caller, e.g. someone registering
+type Actor struct {
+	UserID      uint
+	Role        string
+	Permissions []string
+	// Admin acting as the user, when the request comes from an impersonation token
+	ImpersonatorID uint
+}
+
+// IsImpersonated reports whether an admin is acting as the user
+func (a *Actor) IsImpersonated() bool {
+	return a != nil && a.ImpersonatorID != 0
+}
+
+// logger returns the logger for what the actor does. Like request log lines, it marks
+// what an admin does while impersonating the user with the admin's ID
+func (a *Actor) logger() *zap.Logger {
+	if a.IsImpersonated() {
+		return global.Logger.With(zap.Bool("impersonated", true), zap.Uint("user_id", a.UserID), zap.Uint("impersonator_id", a.ImpersonatorID))
+	}
+	return global.Logger.Logger
+}
+
+// Can reports whether the actor's role grants the permission
+func (a *Actor) Can(permission string) bool {
+	return a != nil && slices.Contains(a.Permissions, permission)
+}
+
+// canListUsers - browsing other users needs user:read
+func canListUsers(actor *Actor) bool {
+	return actor.Can(model.PermissionUserRead)
+}
+
+// canCreateUser - anonymous callers can only register themselves as a plain user,
+// creating accounts needs user:create and giving them any other role user:manage
+func canCreateUser(actor *Actor, role string) bool {
+	if actor == nil {
+		return role == model.RoleUser
+	}
+	return actor.Can(model.PermissionUserCreate) && (role == model.RoleUser || actor.Can(model.PermissionUserManage))
+}
+
+// canUpdateUser - users can always edit themselves but never their own role, so an
+// admin can't lock themselves out either. Editing others needs user:update and
+// changing their role user:manage
+func canUpdateUser(actor *Actor, target uint, currentRole string, updateDto dto.UserUpdateRequestDto) bool {
+	if actor == nil {
+		return false
+	}
+	changesRole := updateDto.Role != "" && updateDto.Role != currentRole
+	if actor.UserID == target {
+		return !changesRole
+	}
+	return actor.Can(model.PermissionUserUpdate) && (!changesRole || actor.Can(model.PermissionUserManage))
+}
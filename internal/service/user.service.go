@@ -7,26 +7,95 @@ import (
 	"base_go_be/internal/repo"
 	"base_go_be/pkg/config"
 	"base_go_be/pkg/jwt"
+	"base_go_be/pkg/passwd"
 	"base_go_be/pkg/response"
-
-	"golang.org/x/crypto/bcrypt"
+	"fmt"
+	"time"
 )
 
 type IUserService interface {
 	GetUserByID(id uint) *response.ServiceResult
-	GetListUser(req dto.UserListRequestDto, userRole string) *response.ServiceResult
-	CreateUser(email string, username string, password string, role string) *response.ServiceResult
-	UpdateUser(id uint, updateDto dto.UserUpdateRequestDto) *response.ServiceResult
-	Login(email string, password string) *response.ServiceResult
-	Register(registerDto dto.RegisterRequestDto) *response.ServiceResult
+	GetPublicProfile(publicID string) *response.ServiceResult
+	GetListUser(actor *Actor, req dto.UserListRequestDto) *response.ServiceResult
+	CreateUser(actor *Actor, email string, username string, password string, role string) *response.ServiceResult
+	UpdateUser(actor *Actor, publicID string, updateDto dto.UserUpdateRequestDto) *response.ServiceResult
+	Login(email string, password string, client dto.ClientInfoDto) *response.ServiceResult
+	Register(registerDto dto.RegisterRequestDto, client dto.ClientInfoDto) *response.ServiceResult
+	RefreshToken(refreshToken string, client dto.ClientInfoDto) *response.ServiceResult
+	Logout(actor *Actor, claims *jwt.JWTClaims, client dto.ClientInfoDto) *response.ServiceResult
+	ForceLogout(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult
+	UnlockLogin(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult
+	LoginMfa(mfaToken string, code string, client dto.ClientInfoDto) *response.ServiceResult
+	SetupMfaForLogin(mfaToken string) *response.ServiceResult
+	SetupMfa(userID uint) *response.ServiceResult
+	ConfirmMfa(userID uint, code string) *response.ServiceResult
+	DisableMfa(userID uint, code string) *response.ServiceResult
+	RegenerateRecoveryCodes(userID uint, code string) *response.ServiceResult
+	ResetMfa(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult
+	ListSessions(userID uint, currentSessionID string) *response.ServiceResult
+	RevokeSession(userID uint, sessionID string) *response.ServiceResult
+	ListUserSessions(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult
+	RevokeUserSession(actor *Actor, publicID string, sessionID string, client dto.ClientInfoDto) *response.ServiceResult
+	RevokeOtherSessions(userID uint, currentSessionID string) *response.ServiceResult
+	CreateWebSocketTicket(accessToken string) *response.ServiceResult
+	ForgotPassword(email string, client dto.ClientInfoDto) *response.ServiceResult
+	ResetPassword(resetDto dto.ResetPasswordRequestDto) *response.ServiceResult
+	ChangePassword(userID uint, currentSessionID string, changeDto dto.ChangePasswordRequestDto, client dto.ClientInfoDto) *response.ServiceResult
+	VerifyEmail(token string) *response.ServiceResult
+	ResendVerification(email string) *response.ServiceResult
+	RequestEmailChange(userID uint, changeDto dto.ChangeEmailRequestDto, client dto.ClientInfoDto) *response.ServiceResult
+	ConfirmEmailChange(token string) *response.ServiceResult
+	RequestMagicLink(email string) *response.ServiceResult
+	LoginMagicLink(token string, client dto.ClientInfoDto) *response.ServiceResult
+	OidcAuthorize(providerName string) *response.ServiceResult
+	OidcCallback(providerName string, code string, stateID string, client dto.ClientInfoDto) *response.ServiceResult
+	BeginPasskeyRegistration(userID uint) *response.ServiceResult
+	FinishPasskeyRegistration(userID uint, registerDto dto.PasskeyRegisterRequestDto) *response.ServiceResult
+	BeginPasskeyLogin() *response.ServiceResult
+	FinishPasskeyLogin(loginDto dto.PasskeyLoginRequestDto, client dto.ClientInfoDto) *response.ServiceResult
+	ListPasskeys(userID uint) *response.ServiceResult
+	DeletePasskey(userID uint, id uint) *response.ServiceResult
+	ImpersonateUser(actor *Actor, targetPublicID string, reason string, client dto.ClientInfoDto) *response.ServiceResult
+	GetUserDetail(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult
+	DeactivateUser(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult
+	ReactivateUser(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult
+	ChangeUserRole(actor *Actor, publicID string, role string, client dto.ClientInfoDto) *response.ServiceResult
+	ForcePasswordReset(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult
+	DeleteUser(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult
+	RestoreUser(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult
+	PurgeDeletedUsers(retention time.Duration) (int, error)
+	EndExpiredImpersonations() (int, error)
 }
 
 type userService struct {
-	userRepo repo.IUserRepository
+	userRepo         repo.IUserRepository
+	tokenRepo        repo.ITokenRepository
+	roleRepo         repo.IRoleRepository
+	rateLimitRepo    repo.IRateLimitRepository
+	loginAttemptRepo repo.ILoginAttemptRepository
+	mfaRepo          repo.IMfaRepository
+	sessionRepo      repo.ISessionRepository
+	identityRepo     repo.IUserIdentityRepository
+	passkeyRepo      repo.IPasskeyRepository
+	auditLogRepo     repo.IAuditLogRepository
 }
 
-func NewUserService(userRepo repo.IUserRepository) IUserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo repo.IUserRepository, tokenRepo repo.ITokenRepository, roleRepo repo.IRoleRepository,
+	rateLimitRepo repo.IRateLimitRepository, loginAttemptRepo repo.ILoginAttemptRepository, mfaRepo repo.IMfaRepository,
+	sessionRepo repo.ISessionRepository, identityRepo repo.IUserIdentityRepository, passkeyRepo repo.IPasskeyRepository,
+	auditLogRepo repo.IAuditLogRepository) IUserService {
+	return &userService{
+		userRepo:         userRepo,
+		tokenRepo:        tokenRepo,
+		roleRepo:         roleRepo,
+		rateLimitRepo:    rateLimitRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		mfaRepo:          mfaRepo,
+		sessionRepo:      sessionRepo,
+		identityRepo:     identityRepo,
+		passkeyRepo:      passkeyRepo,
+		auditLogRepo:     auditLogRepo,
+	}
 }
 
 func (us *userService) GetUserByID(id uint) *response.ServiceResult {
@@ -34,36 +103,49 @@ func (us *userService) GetUserByID(id uint) *response.ServiceResult {
 	if result == nil {
 		return response.NewServiceErrorWithCode(422, response.ErrCodeUserNotFound)
 	}
-	userResponse := dto.UserResponseDto{
-		Id:       result.ID,
-		Email:    result.Email,
-		Username: result.Username,
-		Role:     result.Role,
+	return response.NewServiceResult(newUserResponse(result))
+}
+
+// GetPublicProfile returns what anyone may see of a user, which leaves out the email,
+// locale, timezone and notification preferences
+func (us *userService) GetPublicProfile(publicID string) *response.ServiceResult {
+	user := us.userRepo.GetUserByPublicID(publicID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+	}
+	return response.NewServiceResult(&dto.PublicProfileDto{
+		Id:        user.PublicID,
+		Username:  user.Username,
+		Fullname:  user.Fullname,
+		Bio:       user.Bio,
+		AvatarURL: avatarURL(user.AvatarKey),
+	})
+}
+
+// userByPublicID looks up the user a route's public ID refers to, or returns the 404 to answer
+func (us *userService) userByPublicID(publicID string) (*model.User, *response.ServiceResult) {
+	user := us.userRepo.GetUserByPublicID(publicID)
+	if user == nil {
+		return nil, response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
 	}
-	return response.NewServiceResult(&userResponse)
+	return user, nil
 }
 
-func (us *userService) GetListUser(req dto.UserListRequestDto, userRole string) *response.ServiceResult {
-	// Check authorization - only ADMIN can get user list
-	if userRole != "ADMIN" {
+func (us *userService) GetListUser(actor *Actor, req dto.UserListRequestDto) *response.ServiceResult {
+	if !canListUsers(actor) {
 		return response.NewServiceErrorWithCode(403, response.ErrCodeAccessDenied)
 	}
 
 	users, total, err := us.userRepo.GetListUser(req)
 	if err != nil {
-		global.Logger.Error("Failed to get users from repository: " + err.Error())
+		actor.logger().Error("Failed to get users from repository: " + err.Error())
 		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
 	}
 
 	// Convert to DTOs
 	userDTOs := make([]dto.UserResponseDto, 0, len(users))
 	for _, u := range users {
-		userDTOs = append(userDTOs, dto.UserResponseDto{
-			Id:       u.ID,
-			Email:    u.Email,
-			Username: u.Username,
-			Role:     u.Role,
-		})
+		userDTOs = append(userDTOs, *newUserResponse(u))
 	}
 
 	result := &dto.UserListResponseDto{
@@ -73,38 +155,70 @@ func (us *userService) GetListUser(req dto.UserListRequestDto, userRole string)
 	return response.NewServiceResult(result)
 }
 
-func (us *userService) CreateUser(email string, username string, password string, role string) *response.ServiceResult {
+func (us *userService) CreateUser(actor *Actor, email string, username string, password string, role string) *response.ServiceResult {
+	user, denied := us.createUser(actor, email, username, password, role)
+	if denied != nil {
+		return denied
+	}
+	return response.NewServiceResult(user.PublicID)
+}
+
+// createUser creates the account and sends its verification email. The caller decides
+// what to answer, only the public ID may be shown to clients
+func (us *userService) createUser(actor *Actor, email string, username string, password string, role string) (*model.User, *response.ServiceResult) {
+	if !canCreateUser(actor, role) {
+		return nil, response.NewServiceErrorWithCode(403, response.ErrCodeAccessDenied)
+	}
 
 	existingUser := us.userRepo.GetUserByEmail(email)
 	if existingUser != nil {
-		return response.NewServiceErrorWithCode(409, response.ErrCodeUserHasExists)
+		return nil, response.NewServiceErrorWithCode(409, response.ErrCodeUserHasExists)
+	}
+
+	if us.roleRepo.GetRoleByName(role) == nil {
+		return nil, response.NewServiceErrorWithCode(422, response.ErrCodeRoleNotFound)
+	}
+
+	if policyResult := checkPasswordPolicy(password, email, username); policyResult != nil {
+		return nil, policyResult
 	}
 
 	// Hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := passwd.Hash(password)
 	if err != nil {
-		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+		return nil, response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
 	}
 
 	user := &model.User{
 		Email:    email,
 		Username: username,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Role:     role,
 	}
 
 	userID, err := us.userRepo.CreateUser(user)
 	if err != nil {
-		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+		return nil, response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	// New accounts start unverified until the owner clicks the emailed link
+	user.ID = userID
+	if err := us.sendVerification(user); err != nil {
+		actor.logger().Error("Failed to send email verification: " + err.Error())
 	}
-	return response.NewServiceResult(userID)
+	return user, nil
 }
 
-func (us *userService) UpdateUser(id uint, updateDto dto.UserUpdateRequestDto) *response.ServiceResult {
+func (us *userService) UpdateUser(actor *Actor, publicID string, updateDto dto.UserUpdateRequestDto) *response.ServiceResult {
 
-	existingUser := us.userRepo.GetUserByID(id)
-	if existingUser == nil {
-		return response.NewServiceErrorWithCode(404, response.ErrCodeUserNotFound)
+	existingUser, notFound := us.userByPublicID(publicID)
+	if notFound != nil {
+		return notFound
+	}
+	id := existingUser.ID
+
+	if !canUpdateUser(actor, existingUser.ID, existingUser.Role, updateDto) {
+		return response.NewServiceErrorWithCode(403, response.ErrCodeAccessDenied)
 	}
 
 	updateUser := &model.User{}
@@ -112,16 +226,35 @@ func (us *userService) UpdateUser(id uint, updateDto dto.UserUpdateRequestDto) *
 	if updateDto.Username != "" {
 		updateUser.Username = updateDto.Username
 	}
-	if updateDto.Role != "" {
+	roleChanged := updateDto.Role != "" && updateDto.Role != existingUser.Role
+	if roleChanged {
+		if us.roleRepo.GetRoleByName(updateDto.Role) == nil {
+			return response.NewServiceErrorWithCode(422, response.ErrCodeRoleNotFound)
+		}
 		updateUser.Role = updateDto.Role
 	}
 
 	if updateDto.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(updateDto.Password), bcrypt.DefaultCost)
+		if actor.IsImpersonated() {
+			return response.NewServiceErrorWithCode(403, response.ErrCodeImpersonationForbidden)
+		}
+		// ChangePassword checks the current password, logs out the other sessions and
+		// notifies the user, which an update of oneself must not get around
+		if actor.UserID == existingUser.ID {
+			return response.NewServiceErrorWithCode(403, response.ErrCodeUseChangePassword)
+		}
+		username := existingUser.Username
+		if updateDto.Username != "" {
+			username = updateDto.Username
+		}
+		if policyResult := checkPasswordPolicy(updateDto.Password, existingUser.Email, username); policyResult != nil {
+			return policyResult
+		}
+		hashedPassword, err := passwd.Hash(updateDto.Password)
 		if err != nil {
 			return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
 		}
-		updateUser.Password = string(hashedPassword)
+		updateUser.Password = hashedPassword
 	}
 
 	updatedUser, err := us.userRepo.UpdateUser(id, updateUser)
@@ -129,88 +262,266 @@ func (us *userService) UpdateUser(id uint, updateDto dto.UserUpdateRequestDto) *
 		return response.NewServiceErrorWithCode(400, response.ErrCodeUserHasExists)
 	}
 
-	userResponse := dto.UserResponseDto{
-		Id:       updatedUser.ID,
-		Email:    updatedUser.Email,
-		Username: updatedUser.Username,
-		Role:     updatedUser.Role,
+	// Tokens carry the role, so make the user log in again to pick up the new one
+	if roleChanged {
+		if err := us.revokeAllSessions(id); err != nil {
+			actor.logger().Error("Failed to revoke user sessions: " + err.Error())
+		}
 	}
 
-	return response.NewServiceResult(&userResponse)
+	return response.NewServiceResult(newUserResponse(updatedUser))
 }
 
-func (us *userService) Login(email string, password string) *response.ServiceResult {
+func (us *userService) Login(email string, password string, client dto.ClientInfoDto) *response.ServiceResult {
+	if denied := us.checkLoginAllowed(email, client); denied != nil {
+		return denied
+	}
+
+	// Unknown emails go through the same hash comparison and failure counting as
+	// wrong passwords, so neither timing nor lockout tells them apart
 	user := us.userRepo.GetUserByEmail(email)
-	if user == nil {
-		return response.NewServiceErrorWithCode(401, response.ErrCodeInvalidLogin)
+	passwordHash := dummyPasswordHash()
+	if user != nil {
+		passwordHash = user.Password
 	}
 
 	// Compare password hash
-	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	match, rehash, err := passwd.Verify(passwordHash, password)
 	if err != nil {
+		global.Logger.Error("Failed to verify password hash: " + err.Error())
+	}
+	if !match || user == nil {
+		us.recordLoginFailure(email, client)
 		return response.NewServiceErrorWithCode(401, response.ErrCodeInvalidLogin)
 	}
 
-	// Generate JWT token
-	token, err := jwt.GenerateToken(user.ID, user.Email, user.Role, config.JWT.SecretKey, config.JWT.TokenExpiry)
+	// Hashes from before argon2id, or with an older cost, are upgraded while the
+	// plain password is at hand
+	if rehash {
+		us.rehashPassword(user.ID, password)
+	}
+
+	if err := us.loginAttemptRepo.Reset(email); err != nil {
+		global.Logger.Error("Failed to reset login failures: " + err.Error())
+	}
+
+	// Checked only after the password, so the account state isn't disclosed to guessers
+	if statusResult := checkAccountStatus(user); statusResult != nil {
+		return statusResult
+	}
+
+	return us.completeLogin(user, client)
+}
+
+func (us *userService) Register(registerDto dto.RegisterRequestDto, client dto.ClientInfoDto) *response.ServiceResult {
+	role := registerDto.Role
+	if role == "" {
+		role = model.RoleUser
+	}
+
+	// Registration is anonymous, so the policy only lets it create plain users
+	created, denied := us.createUser(nil, registerDto.Email, registerDto.Username, registerDto.Password, role)
+	if denied != nil {
+		return denied
+	}
+
+	// Read back with the column defaults, such as is_active, filled in
+	user := us.userRepo.GetUserByID(created.ID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	// No tokens until the email is verified, the client has to wait for the link
+	if global.Config.Auth.RequireEmailVerification {
+		return response.NewServiceResult(&dto.MessageResponseDto{
+			Message: "Registration successful, please check your email to verify your account",
+		})
+	}
+
+	return us.completeLogin(user, client)
+}
+
+func (us *userService) RefreshToken(refreshToken string, client dto.ClientInfoDto) *response.ServiceResult {
+	claims, err := jwt.ValidateToken(refreshToken, jwt.TokenTypeRefresh)
+	if err != nil {
+		return response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+
+	familyID, err := us.tokenRepo.GetRefreshFamilyID(refreshToken)
+	if err != nil {
+		global.Logger.Error("Failed to get refresh token family: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if familyID == "" {
+		return response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+
+	user := us.userRepo.GetUserByPublicID(claims.UserID)
+	if user == nil {
+		return response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
+
+	revoked, err := us.tokenRepo.IsTokenRevoked(claims.ID, user.ID, claims.IssuedAt.Time)
 	if err != nil {
+		global.Logger.Error("Failed to check token revocation: " + err.Error())
 		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
 	}
+	if revoked {
+		_ = us.tokenRepo.RevokeRefreshFamily(familyID)
+		return response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
 
-	// Generate refresh token with longer expiry
-	refreshToken, err := jwt.GenerateToken(user.ID, user.Email, user.Role, config.JWT.SecretKey, config.JWT.RefreshExpiry)
+	// The session was ended (logout, revoked from another device...) or expired
+	session, err := us.sessionRepo.GetSession(familyID)
 	if err != nil {
+		global.Logger.Error("Failed to get session: " + err.Error())
 		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
 	}
+	if session == nil || session.UserID != user.ID {
+		_ = us.tokenRepo.RevokeRefreshFamily(familyID)
+		return response.NewServiceErrorWithCode(401, response.ErrInvalidToken)
+	}
 
-	authResponse := &dto.AuthResponseDto{
-		Token:        token,
-		RefreshToken: refreshToken,
-		User: dto.UserResponseDto{
-			Id:       user.ID,
-			Email:    user.Email,
-			Username: user.Username,
-			Role:     user.Role,
-		},
+	if statusResult := checkAccountStatus(user); statusResult != nil {
+		return statusResult
+	}
+
+	token, newRefreshToken, err := generateTokenPair(user, familyID)
+	if err != nil {
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
 	}
 
-	return response.NewServiceResult(authResponse)
+	rotated, err := us.tokenRepo.RotateRefreshToken(familyID, refreshToken, newRefreshToken, config.JWT.RefreshExpiry)
+	if err != nil {
+		global.Logger.Error("Failed to rotate refresh token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !rotated {
+		// The token was already exchanged (or its family revoked): treat it as stolen
+		// and kill the whole family so the legitimate holder has to log in again
+		if err := us.tokenRepo.RevokeRefreshFamily(familyID); err != nil {
+			global.Logger.Error("Failed to revoke refresh token family: " + err.Error())
+		}
+		global.Logger.Warn(fmt.Sprintf("Refresh token reuse detected for user %d, family %s revoked", user.ID, familyID))
+		return response.NewServiceErrorWithCode(401, response.ErrCodeTokenReused)
+	}
+
+	if err := us.sessionRepo.TouchSession(session, client.IP, config.JWT.RefreshExpiry); err != nil {
+		global.Logger.Error("Failed to update session: " + err.Error())
+	}
+
+	return response.NewServiceResult(newAuthResponse(user, token, newRefreshToken))
 }
 
-func (us *userService) Register(registerDto dto.RegisterRequestDto) *response.ServiceResult {
-	createResult := us.CreateUser(registerDto.Email, registerDto.Username, registerDto.Password, registerDto.Role)
-	if createResult.Error != nil {
-		return createResult // Return CreateUser
+func (us *userService) Logout(actor *Actor, claims *jwt.JWTClaims, client dto.ClientInfoDto) *response.ServiceResult {
+	if err := us.tokenRepo.RevokeToken(claims.ID, claims.ExpiresAt.Time); err != nil {
+		actor.logger().Error("Failed to revoke access token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
 	}
 
-	userID := createResult.Data.(uint)
-	user := us.userRepo.GetUserByID(userID)
-	if user == nil {
+	// Ending the session also revokes the refresh tokens issued with it
+	if claims.SessionID != "" {
+		if err := us.endSession(actor.UserID, claims.SessionID); err != nil {
+			actor.logger().Error("Failed to end session: " + err.Error())
+			return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+		}
+	}
+
+	// Logging out of an impersonation token is how the admin ends it
+	if actor.IsImpersonated() {
+		if _, err := us.endImpersonation(claims.SessionID, false, client); err != nil {
+			actor.logger().Error("Failed to end impersonation: " + err.Error())
+		}
+	}
+
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "Logged out successfully"})
+}
+
+func (us *userService) ForceLogout(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult {
+	user, denied := us.adminTarget(actor, publicID)
+	if denied != nil {
+		return denied
+	}
+
+	if err := us.revokeAllSessions(user.ID); err != nil {
+		global.Logger.Error("Failed to revoke user sessions: " + err.Error())
 		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
 	}
 
-	// Generate JWT token
-	token, err := jwt.GenerateToken(user.ID, user.Email, user.Role, config.JWT.SecretKey, config.JWT.TokenExpiry)
+	us.audit(actor.UserID, model.AuditActionUserSessionsRevoke, &user.ID, nil, client)
+
+	global.Logger.Info(fmt.Sprintf("All sessions of user %d have been revoked", user.ID))
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "User has been logged out from all devices"})
+}
+
+func (us *userService) CreateWebSocketTicket(accessToken string) *response.ServiceResult {
+	ticket, err := us.tokenRepo.CreateWebSocketTicket(accessToken, config.JWT.TicketExpiry)
 	if err != nil {
+		global.Logger.Error("Failed to create websocket ticket: " + err.Error())
 		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
 	}
 
-	// Generate refresh token with longer expiry
-	refreshToken, err := jwt.GenerateToken(user.ID, user.Email, user.Role, config.JWT.SecretKey, config.JWT.RefreshExpiry)
+	return response.NewServiceResult(&dto.WebSocketTicketResponseDto{
+		Ticket:    ticket,
+		ExpiresIn: int(config.JWT.TicketExpiry.Seconds()),
+	})
+}
+
+// generateAuthResponse starts a new login session for the user and issues its first token pair
+func (us *userService) generateAuthResponse(user *model.User, client dto.ClientInfoDto) *response.ServiceResult {
+	session := &repo.Session{
+		UserID:    user.ID,
+		Device:    deviceName(client.UserAgent),
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+	}
+	if err := us.sessionRepo.CreateSession(session, config.JWT.RefreshExpiry); err != nil {
+		global.Logger.Error("Failed to create session: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	token, refreshToken, err := generateTokenPair(user, session.ID)
 	if err != nil {
 		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
 	}
 
-	authResponse := &dto.AuthResponseDto{
+	if err := us.tokenRepo.CreateRefreshFamily(session.ID, refreshToken, config.JWT.RefreshExpiry); err != nil {
+		global.Logger.Error("Failed to store refresh token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	return response.NewServiceResult(newAuthResponse(user, token, refreshToken))
+}
+
+func generateTokenPair(user *model.User, sessionID string) (string, string, error) {
+	// Generate JWT token
+	token, err := jwt.GenerateToken(user.PublicID, user.Email, user.Role, sessionID, jwt.TokenTypeAccess, config.JWT.TokenExpiry)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Generate refresh token with longer expiry
+	refreshToken, err := jwt.GenerateToken(user.PublicID, user.Email, user.Role, sessionID, jwt.TokenTypeRefresh, config.JWT.RefreshExpiry)
+	if err != nil {
+		return "", "", err
+	}
+	return token, refreshToken, nil
+}
+
+func newAuthResponse(user *model.User, token string, refreshToken string) *dto.AuthResponseDto {
+	return &dto.AuthResponseDto{
 		Token:        token,
 		RefreshToken: refreshToken,
-		User: dto.UserResponseDto{
-			Id:       user.ID,
-			Email:    user.Email,
-			Username: user.Username,
-			Role:     user.Role,
-		},
+		User:         *newUserResponse(user),
 	}
+}
 
-	return response.NewServiceResult(authResponse)
+// newUserResponse describes the user to clients, by public ID
+func newUserResponse(user *model.User) *dto.UserResponseDto {
+	return &dto.UserResponseDto{
+		Id:       user.PublicID,
+		Email:    user.Email,
+		Username: user.Username,
+		Role:     user.Role,
+	}
 }
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/pkg/config"
+	"base_go_be/pkg/response"
+	"fmt"
+	"strings"
+)
+
+func (us *userService) ListSessions(userID uint, currentSessionID string) *response.ServiceResult {
+	sessions, err := us.sessionRepo.ListUserSessions(userID)
+	if err != nil {
+		global.Logger.Error("Failed to list sessions: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+
+	sessionDTOs := make([]dto.SessionResponseDto, 0, len(sessions))
+	for _, session := range sessions {
+		sessionDTO := dto.SessionResponseDto{
+			Id:         session.ID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == currentSessionID,
+		}
+		if session.ImpersonatorID != 0 {
+			if impersonator := us.userRepo.GetUserByID(session.ImpersonatorID); impersonator != nil {
+				sessionDTO.ImpersonatorID = impersonator.PublicID
+			}
+		}
+		sessionDTOs = append(sessionDTOs, sessionDTO)
+	}
+	return response.NewServiceResult(sessionDTOs)
+}
+
+// ListUserSessions lists the sessions of the user with the public ID, for admins
+func (us *userService) ListUserSessions(actor *Actor, publicID string, client dto.ClientInfoDto) *response.ServiceResult {
+	user, denied := us.adminTarget(actor, publicID)
+	if denied != nil {
+		return denied
+	}
+	result := us.ListSessions(user.ID, "")
+	if result.Error == nil {
+		us.audit(actor.UserID, model.AuditActionUserSessionsView, &user.ID, nil, client)
+	}
+	return result
+}
+
+func (us *userService) RevokeSession(userID uint, sessionID string) *response.ServiceResult {
+	session, err := us.sessionRepo.GetSession(sessionID)
+	if err != nil {
+		global.Logger.Error("Failed to get session: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	// Sessions of other users look exactly like missing ones
+	if session == nil || session.UserID != userID {
+		return response.NewServiceErrorWithCode(404, response.ErrCodeSessionNotFound)
+	}
+
+	if err := us.endSession(userID, sessionID); err != nil {
+		global.Logger.Error("Failed to end session: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "Session has been revoked"})
+}
+
+// RevokeUserSession ends a session of the user with the public ID, for admins
+func (us *userService) RevokeUserSession(actor *Actor, publicID string, sessionID string, client dto.ClientInfoDto) *response.ServiceResult {
+	user, denied := us.adminTarget(actor, publicID)
+	if denied != nil {
+		return denied
+	}
+	result := us.RevokeSession(user.ID, sessionID)
+	if result.Error == nil {
+		us.audit(actor.UserID, model.AuditActionUserSessionRevoke, &user.ID, map[string]any{"session_id": sessionID}, client)
+	}
+	return result
+}
+
+func (us *userService) RevokeOtherSessions(userID uint, currentSessionID string) *response.ServiceResult {
+	revoked, err := us.revokeOtherSessions(userID, currentSessionID)
+	if err != nil {
+		global.Logger.Error("Failed to revoke other sessions: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: fmt.Sprintf("%d other session(s) have been revoked", revoked)})
+}
+
+// revokeOtherSessions ends every session of the user but the current one and returns
+// how many were ended
+func (us *userService) revokeOtherSessions(userID uint, currentSessionID string) (int, error) {
+	sessions, err := us.sessionRepo.ListUserSessions(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == currentSessionID {
+			continue
+		}
+		if err := us.endSession(userID, session.ID); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// endSession deletes the session and revokes its refresh tokens. Its access tokens
+// stop working too, since AuthMiddleware requires the session to exist
+func (us *userService) endSession(userID uint, sessionID string) error {
+	if err := us.sessionRepo.DeleteSession(userID, sessionID); err != nil {
+		return err
+	}
+	return us.tokenRepo.RevokeRefreshFamily(sessionID)
+}
+
+// revokeAllSessions logs the user out everywhere
+func (us *userService) revokeAllSessions(userID uint) error {
+	if err := us.tokenRepo.RevokeUserTokens(userID, config.JWT.RefreshExpiry); err != nil {
+		return err
+	}
+	sessions, err := us.sessionRepo.ListUserSessions(userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if err := us.endSession(userID, session.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deviceName gives a readable name like "Chrome on Windows" from a User-Agent
+func deviceName(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	for _, b := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Chrome/", "Chrome"},
+		{"CriOS/", "Chrome"},
+		{"Firefox/", "Firefox"},
+		{"FxiOS/", "Firefox"},
+		{"Safari/", "Safari"},
+		{"PostmanRuntime/", "Postman"},
+		{"curl/", "curl"},
+	} {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	platform := ""
+	for _, o := range []struct{ token, name string }{
+		{"Windows", "Windows"},
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, o.token) {
+			platform = o.name
+			break
+		}
+	}
+
+	if platform == "" {
+		return browser
+	}
+	return browser + " on " + platform
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"base_go_be/pkg/response"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	resendVerificationLimit  = 3
+	resendVerificationWindow = time.Hour
+)
+
+func (us *userService) VerifyEmail(token string) *response.ServiceResult {
+	verifyToken, err := us.tokenRepo.ConsumeOneTimeToken(repo.PurposeEmailVerification, token)
+	if err != nil {
+		global.Logger.Error("Failed to consume email verification token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if verifyToken == nil {
+		return response.NewServiceErrorWithCode(400, response.ErrInvalidToken)
+	}
+
+	user := us.userRepo.GetUserByID(verifyToken.UserID)
+	// The token is bound to the address it was sent to, in case the email changed since
+	if user == nil || user.Email != verifyToken.Data {
+		return response.NewServiceErrorWithCode(400, response.ErrInvalidToken)
+	}
+
+	if !user.IsEmailVerified() {
+		now := time.Now()
+		if _, err := us.userRepo.UpdateUser(user.ID, &model.User{EmailVerifiedAt: &now}); err != nil {
+			global.Logger.Error("Failed to mark email as verified: " + err.Error())
+			return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+		}
+		global.Logger.Info(fmt.Sprintf("Email of user %d has been verified", user.ID))
+	}
+
+	return response.NewServiceResult(&dto.MessageResponseDto{Message: "Email has been verified"})
+}
+
+func (us *userService) ResendVerification(email string) *response.ServiceResult {
+	// Same answer whether the account exists or not, so this can't be used to probe emails
+	result := response.NewServiceResult(&dto.MessageResponseDto{
+		Message: "If an unverified account exists for this email, a verification link has been sent",
+	})
+
+	// Throttled per address, known or not, so the limit doesn't reveal accounts either
+	allowed, err := us.rateLimitRepo.Allow("resend_verification:"+strings.ToLower(email), resendVerificationLimit, resendVerificationWindow)
+	if err != nil {
+		global.Logger.Error("Failed to check resend verification rate limit: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	if !allowed {
+		return response.NewServiceErrorWithCode(429, response.ErrCodeTooManyRequests)
+	}
+
+	user := us.userRepo.GetUserByEmail(email)
+	if user == nil || user.IsEmailVerified() || !user.IsActive {
+		return result
+	}
+
+	if err := us.sendVerification(user); err != nil {
+		global.Logger.Error("Failed to create email verification token: " + err.Error())
+		return response.NewServiceErrorWithCode(500, response.ErrCodeInternalError)
+	}
+	return result
+}
+
+// sendVerification emails the user a fresh verification link, invalidating older ones
+func (us *userService) sendVerification(user *model.User) error {
+	ttlMinutes := global.Config.Auth.EmailVerificationTTL
+	token, err := us.tokenRepo.CreateOneTimeToken(repo.PurposeEmailVerification,
+		repo.OneTimeToken{UserID: user.ID, Data: user.Email}, time.Duration(ttlMinutes)*time.Minute)
+	if err != nil {
+		return err
+	}
+
+	sendVerificationMail(user.Email, user.Username, frontendLink("/verify-email", token), ttlMinutes)
+	return nil
+}
+
+// checkAccountStatus tells whether the user may be issued tokens, nil meaning yes
+func checkAccountStatus(user *model.User) *response.ServiceResult {
+	if !user.IsActive {
+		return response.NewServiceErrorWithCode(403, response.ErrCodeAccountInactive)
+	}
+	if global.Config.Auth.RequireEmailVerification && !user.IsEmailVerified() {
+		return response.NewServiceErrorWithCode(403, response.ErrCodeEmailNotVerified)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build wireinject
+
+package wire
+
+import (
+	"base_go_be/internal/controller"
+	"base_go_be/internal/repo"
+	"base_go_be/internal/service"
+	"github.com/google/wire"
+)
+
+func InitAuditLogRouterHandler() (*controller.AuditLogController, error) {
+	wire.Build(
+		repo.NewAuditLogRepository,
+		service.NewAuditLogService,
+		controller.NewAuditLogController,
+	)
+	return new(controller.AuditLogController), nil
+}
@@ -0,0 +1,19 @@
+//go:build wireinject
+
+package wire
+
+import (
+	"base_go_be/internal/controller"
+	"base_go_be/internal/repo"
+	"base_go_be/internal/service"
+	"github.com/google/wire"
+)
+
+func InitPersonalTokenRouterHandler() (*controller.PersonalTokenController, error) {
+	wire.Build(
+		repo.NewPersonalTokenRepository,
+		service.NewPersonalTokenService,
+		controller.NewPersonalTokenController,
+	)
+	return new(controller.PersonalTokenController), nil
+}
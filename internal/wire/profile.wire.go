@@ -0,0 +1,19 @@
+//go:build wireinject
+
+package wire
+
+import (
+	"base_go_be/internal/controller"
+	"base_go_be/internal/repo"
+	"base_go_be/internal/service"
+	"github.com/google/wire"
+)
+
+func InitProfileRouterHandler() (*controller.ProfileController, error) {
+	wire.Build(
+		repo.NewUserRepository,
+		service.NewProfileService,
+		controller.NewProfileController,
+	)
+	return new(controller.ProfileController), nil
+}
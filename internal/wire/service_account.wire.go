@@ -0,0 +1,21 @@
+//go:build wireinject
+
+package wire
+
+import (
+	"base_go_be/internal/controller"
+	"base_go_be/internal/repo"
+	"base_go_be/internal/service"
+	"github.com/google/wire"
+)
+
+func InitServiceAccountRouterHandler() (*controller.ServiceAccountController, error) {
+	wire.Build(
+		repo.NewServiceAccountRepository,
+		repo.NewSignedRequestRepository,
+		repo.NewRoleRepository,
+		service.NewServiceAccountService,
+		controller.NewServiceAccountController,
+	)
+	return new(controller.ServiceAccountController), nil
+}
@@ -12,8 +12,35 @@ import (
 func InitUserRouterHandler() (*controller.UserController, error) {
 	wire.Build(
 		repo.NewUserRepository,
+		repo.NewTokenRepository,
+		repo.NewRateLimitRepository,
+		repo.NewLoginAttemptRepository,
+		repo.NewMfaRepository,
+		repo.NewSessionRepository,
+		repo.NewUserIdentityRepository,
+		repo.NewPasskeyRepository,
+		repo.NewRoleRepository,
+		repo.NewAuditLogRepository,
 		service.NewUserService,
 		controller.NewUserController,
 	)
 	return new(controller.UserController), nil
 }
+
+// InitUserService builds the user service for background jobs
+func InitUserService() (service.IUserService, error) {
+	wire.Build(
+		repo.NewUserRepository,
+		repo.NewTokenRepository,
+		repo.NewRateLimitRepository,
+		repo.NewLoginAttemptRepository,
+		repo.NewMfaRepository,
+		repo.NewSessionRepository,
+		repo.NewUserIdentityRepository,
+		repo.NewPasskeyRepository,
+		repo.NewRoleRepository,
+		repo.NewAuditLogRepository,
+		service.NewUserService,
+	)
+	return nil, nil
+}
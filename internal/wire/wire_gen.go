@@ -12,6 +12,24 @@ import (
 	"base_go_be/internal/service"
 )
 
+// Injectors from audit_log.wire.go:
+
+func InitAuditLogRouterHandler() (*controller.AuditLogController, error) {
+	iAuditLogRepository := repo.NewAuditLogRepository()
+	iAuditLogService := service.NewAuditLogService(iAuditLogRepository)
+	auditLogController := controller.NewAuditLogController(iAuditLogService)
+	return auditLogController, nil
+}
+
+// Injectors from personal_token.wire.go:
+
+func InitPersonalTokenRouterHandler() (*controller.PersonalTokenController, error) {
+	iPersonalTokenRepository := repo.NewPersonalTokenRepository()
+	iPersonalTokenService := service.NewPersonalTokenService(iPersonalTokenRepository)
+	personalTokenController := controller.NewPersonalTokenController(iPersonalTokenService)
+	return personalTokenController, nil
+}
+
 // Injectors from product.wire.go:
 
 func InitProductRouterHandler() (*controller.ProductController, error) {
@@ -21,11 +39,65 @@ func InitProductRouterHandler() (*controller.ProductController, error) {
 	return productController, nil
 }
 
+// Injectors from profile.wire.go:
+
+func InitProfileRouterHandler() (*controller.ProfileController, error) {
+	iUserRepository := repo.NewUserRepository()
+	iProfileService := service.NewProfileService(iUserRepository)
+	profileController := controller.NewProfileController(iProfileService)
+	return profileController, nil
+}
+
+// Injectors from role.wire.go:
+
+func InitRoleRouterHandler() (*controller.RoleController, error) {
+	iRoleRepository := repo.NewRoleRepository()
+	iRoleService := service.NewRoleService(iRoleRepository)
+	roleController := controller.NewRoleController(iRoleService)
+	return roleController, nil
+}
+
+// Injectors from service_account.wire.go:
+
+func InitServiceAccountRouterHandler() (*controller.ServiceAccountController, error) {
+	iServiceAccountRepository := repo.NewServiceAccountRepository()
+	iSignedRequestRepository := repo.NewSignedRequestRepository()
+	iRoleRepository := repo.NewRoleRepository()
+	iServiceAccountService := service.NewServiceAccountService(iServiceAccountRepository, iSignedRequestRepository, iRoleRepository)
+	serviceAccountController := controller.NewServiceAccountController(iServiceAccountService)
+	return serviceAccountController, nil
+}
+
 // Injectors from user.wire.go:
 
 func InitUserRouterHandler() (*controller.UserController, error) {
 	iUserRepository := repo.NewUserRepository()
-	iUserService := service.NewUserService(iUserRepository)
+	iTokenRepository := repo.NewTokenRepository()
+	iRoleRepository := repo.NewRoleRepository()
+	iRateLimitRepository := repo.NewRateLimitRepository()
+	iLoginAttemptRepository := repo.NewLoginAttemptRepository()
+	iMfaRepository := repo.NewMfaRepository()
+	iSessionRepository := repo.NewSessionRepository()
+	iUserIdentityRepository := repo.NewUserIdentityRepository()
+	iPasskeyRepository := repo.NewPasskeyRepository()
+	iAuditLogRepository := repo.NewAuditLogRepository()
+	iUserService := service.NewUserService(iUserRepository, iTokenRepository, iRoleRepository, iRateLimitRepository, iLoginAttemptRepository, iMfaRepository, iSessionRepository, iUserIdentityRepository, iPasskeyRepository, iAuditLogRepository)
 	userController := controller.NewUserController(iUserService)
 	return userController, nil
 }
+
+// InitUserService builds the user service for background jobs
+func InitUserService() (service.IUserService, error) {
+	iUserRepository := repo.NewUserRepository()
+	iTokenRepository := repo.NewTokenRepository()
+	iRoleRepository := repo.NewRoleRepository()
+	iRateLimitRepository := repo.NewRateLimitRepository()
+	iLoginAttemptRepository := repo.NewLoginAttemptRepository()
+	iMfaRepository := repo.NewMfaRepository()
+	iSessionRepository := repo.NewSessionRepository()
+	iUserIdentityRepository := repo.NewUserIdentityRepository()
+	iPasskeyRepository := repo.NewPasskeyRepository()
+	iAuditLogRepository := repo.NewAuditLogRepository()
+	iUserService := service.NewUserService(iUserRepository, iTokenRepository, iRoleRepository, iRateLimitRepository, iLoginAttemptRepository, iMfaRepository, iSessionRepository, iUserIdentityRepository, iPasskeyRepository, iAuditLogRepository)
+	return iUserService, nil
+}
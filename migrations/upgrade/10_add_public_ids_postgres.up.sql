@@ -0,0 +1,27 @@
+-- Opaque public identifiers for users and products, so clients can't enumerate them by
+-- counting. Integer ids stay the internal keys used by joins
+
+-- UUIDv7 for the given time (ms precision): sortable like the ids they replace
+CREATE OR REPLACE FUNCTION uuid_v7(ts TIMESTAMPTZ) RETURNS UUID AS $$
+    SELECT encode(
+        set_bit(
+            set_bit(
+                overlay(uuid_send(gen_random_uuid())
+                        PLACING substring(int8send(floor(extract(epoch FROM ts) * 1000)::BIGINT) FROM 3)
+                        FROM 1 FOR 6),
+                52, 1),
+            53, 1),
+        'hex')::UUID;
+$$ LANGUAGE SQL VOLATILE;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS public_id UUID;
+UPDATE users SET public_id = uuid_v7(COALESCE(created_at, CURRENT_TIMESTAMP)) WHERE public_id IS NULL;
+ALTER TABLE users ALTER COLUMN public_id SET DEFAULT uuid_v7(clock_timestamp());
+ALTER TABLE users ALTER COLUMN public_id SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_public_id ON users(public_id);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS public_id UUID;
+UPDATE products SET public_id = uuid_v7(COALESCE(created_at, CURRENT_TIMESTAMP)) WHERE public_id IS NULL;
+ALTER TABLE products ALTER COLUMN public_id SET DEFAULT uuid_v7(clock_timestamp());
+ALTER TABLE products ALTER COLUMN public_id SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_public_id ON products(public_id);
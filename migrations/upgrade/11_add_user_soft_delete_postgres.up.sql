@@ -0,0 +1,13 @@
+-- Deleted users are kept, hidden, until the purge job removes them after the retention period
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
+
+-- Email and username only have to be unique among users that aren't deleted, so they
+-- can be registered again
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_live ON users(email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_live ON users(username) WHERE deleted_at IS NULL;
+
+-- Products of purged users are kept without an owner
+ALTER TABLE products ALTER COLUMN user_id DROP NOT NULL;
@@ -0,0 +1,9 @@
+-- Profile of users. fullname and updated_at came with the table but weren't used yet
+UPDATE users SET fullname = '' WHERE fullname IS NULL;
+ALTER TABLE users ALTER COLUMN fullname SET DEFAULT '';
+ALTER TABLE users ALTER COLUMN fullname SET NOT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS bio VARCHAR(500) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key VARCHAR(255) NOT NULL DEFAULT ''; -- storage key, '' without avatar
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(35) NOT NULL DEFAULT ''; -- BCP 47 tag, e.g. vi-VN
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT ''; -- IANA name, e.g. Asia/Ho_Chi_Minh
+ALTER TABLE users ADD COLUMN IF NOT EXISTS notification_prefs JSONB NOT NULL DEFAULT '{"product_updates": true, "newsletter": false}';
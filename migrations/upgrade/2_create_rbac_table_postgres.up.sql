@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    name VARCHAR(100) PRIMARY KEY,
+    description VARCHAR(255)
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_name VARCHAR(50) NOT NULL REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    permission_name VARCHAR(100) NOT NULL REFERENCES permissions(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (role_name, permission_name)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('ADMIN', 'Administrator'),
+    ('USER', 'Regular user')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('user:read', 'View other users'),
+    ('user:create', 'Create users'),
+    ('user:update', 'Update other users'),
+    ('user:manage', 'Assign roles and run admin actions on users'),
+    ('role:manage', 'Manage roles and their permissions'),
+    ('product:read', 'View products'),
+    ('product:write', 'Create products'),
+    ('product:delete', 'Delete products')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_name, permission_name)
+SELECT 'ADMIN', name FROM permissions
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('USER', 'product:read'),
+    ('USER', 'product:write')
+ON CONFLICT DO NOTHING;
+
+-- users.role becomes a reference to roles instead of a fixed enum
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(50) USING role::text;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'USER';
+ALTER TABLE users ALTER COLUMN role SET NOT NULL;
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+DROP TYPE IF EXISTS user_role;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS service_accounts (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description VARCHAR(255),
+    role VARCHAR(50) NOT NULL REFERENCES roles(name) ON UPDATE CASCADE,
+    owner_user_id INTEGER NOT NULL REFERENCES users(id), -- owns what the account creates
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS service_account_keys (
+    id SERIAL PRIMARY KEY,
+    service_account_id INTEGER NOT NULL REFERENCES service_accounts(id) ON DELETE CASCADE,
+    key_id VARCHAR(40) NOT NULL UNIQUE,
+    secret TEXT NOT NULL, -- encrypted with APP_ENCRYPTION_KEY, HMAC needs it in clear
+    last_used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_account_keys_service_account_id ON service_account_keys(service_account_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('service_account:manage', 'Manage service accounts and their API keys')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('ADMIN', 'service_account:manage')
+ON CONFLICT DO NOTHING;
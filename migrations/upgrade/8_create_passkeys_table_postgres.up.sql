@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS passkeys (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    credential_id BYTEA NOT NULL UNIQUE,
+    public_key BYTEA NOT NULL, -- COSE encoded
+    attestation_type VARCHAR(32) NOT NULL DEFAULT '',
+    transports JSONB NOT NULL DEFAULT '[]',
+    aaguid BYTEA,
+    sign_count BIGINT NOT NULL DEFAULT 0, -- a counter going backwards means a cloned authenticator
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+    backup_state BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_passkeys_user_id ON passkeys(user_id);
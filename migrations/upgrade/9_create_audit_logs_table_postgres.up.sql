@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_user_id INTEGER NOT NULL, -- no foreign key, entries outlive the users they name
+    action VARCHAR(64) NOT NULL,
+    target_user_id INTEGER NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_user_id ON audit_logs(actor_user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target_user_id ON audit_logs(target_user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action);
+
+INSERT INTO permissions (name, description) VALUES
+    ('user:impersonate', 'Log in as another user to reproduce their issues'),
+    ('audit_log:read', 'Read the audit log')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('ADMIN', 'user:impersonate'),
+    ('ADMIN', 'audit_log:read')
+ON CONFLICT DO NOTHING;
@@ -4,12 +4,30 @@ import "time"
 
 type JWTConfig struct {
 	SecretKey     string
+	Issuer        string
+	Audience      string
 	TokenExpiry   time.Duration
 	RefreshExpiry time.Duration
+	TicketExpiry  time.Duration // one-time WebSocket handshake tickets
+	MFAExpiry     time.Duration // challenge tokens between the password and the 2FA step
+	// Asymmetric signing. When neither KeysDir nor PrivateKey is set, tokens are
+	// signed with SecretKey (HS256), otherwise SecretKey only verifies the tokens it
+	// signed before
+	ActiveKeyID string // kid used to sign new tokens
+	KeysDir     string // directory of <kid>.pem files, public-only files are kept for verification
+	PrivateKey  string // PEM private key for ActiveKeyID, typically injected through env
 }
 
+// DefaultSecretKey is the placeholder secret shipped with the code. Being public, it
+// isn't kept for verification once keys are configured
+const DefaultSecretKey = "your-secret-key-change-in-production"
+
 var JWT = JWTConfig{
-	SecretKey:     "your-secret-key-change-in-production", // Change this in production
-	TokenExpiry:   time.Hour * 24,                         // 24 hours
-	RefreshExpiry: time.Hour * 24 * 7,                     // 7 days
+	SecretKey:     DefaultSecretKey,   // Change this in production
+	Issuer:        "kado",             // Overridden by JWT_ISSUER
+	Audience:      "kado-api",         // Overridden by JWT_AUDIENCE
+	TokenExpiry:   time.Hour * 24,     // 24 hours
+	RefreshExpiry: time.Hour * 24 * 7, // 7 days
+	TicketExpiry:  time.Second * 30,   // 30 seconds
+	MFAExpiry:     time.Minute * 5,    // 5 minutes
 }
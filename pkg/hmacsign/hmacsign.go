@@ -0,0 +1,41 @@
+package hmacsign
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Headers of a signed request
+const (
+	HeaderKeyID     = "X-Kado-Key-Id"
+	HeaderTimestamp = "X-Kado-Timestamp" // unix seconds
+	HeaderSignature = "X-Kado-Signature" // hex HMAC-SHA256 of StringToSign
+)
+
+// StringToSign builds the canonical string of a request:
+//
+//	METHOD \n PATH?QUERY \n TIMESTAMP \n hex(sha256(body))
+func StringToSign(method string, requestURI string, timestamp string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		requestURI,
+		timestamp,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// Sign returns the signature clients send in HeaderSignature
+func Sign(secret string, method string, requestURI string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(StringToSign(method, requestURI, timestamp, body)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature in constant time
+func Verify(secret string, signature string, method string, requestURI string, timestamp string, body []byte) bool {
+	expected := Sign(secret, method, requestURI, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
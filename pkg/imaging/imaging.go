@@ -0,0 +1,82 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+var (
+	ErrUnsupportedImage = errors.New("image format not supported")
+	ErrImageTooLarge    = errors.New("image dimensions too large")
+)
+
+// Decode reads a JPEG, PNG or GIF image. Its dimensions are checked before decoding,
+// so a small file claiming a huge image can't exhaust memory
+func Decode(r io.Reader, maxPixels int) (image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	return img, nil
+}
+
+// Square crops the centered square of img and scales it to size x size. Each pixel
+// is the average of the source pixels it covers, which keeps downscaled photos smooth
+func Square(img image.Image, size int) *image.NRGBA {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	left := bounds.Min.X + (bounds.Dx()-side)/2
+	top := bounds.Min.Y + (bounds.Dy()-side)/2
+
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		y0, y1 := span(y, size, side)
+		for x := 0; x < size; x++ {
+			x0, x1 := span(x, size, side)
+			// Colors are summed premultiplied, so transparent pixels don't darken edges
+			var r, g, b, a, n uint64
+			for sy := top + y0; sy < top+y1; sy++ {
+				for sx := left + x0; sx < left+x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			if a == 0 {
+				continue
+			}
+			dst.Pix[i+0] = uint8(r * 0xff / a)
+			dst.Pix[i+1] = uint8(g * 0xff / a)
+			dst.Pix[i+2] = uint8(b * 0xff / a)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
+
+// span is the range of source pixels, out of side, covered by pixel i of size
+func span(i int, size int, side int) (int, int) {
+	start := i * side / size
+	end := (i + 1) * side / size
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
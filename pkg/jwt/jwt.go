@@ -1,6 +1,9 @@
 package jwt
 
 import (
+	"base_go_be/pkg/config"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -8,31 +11,100 @@ import (
 )
 
 var (
-	ErrInvalidToken = errors.New("invalid token")
-	ErrExpiredToken = errors.New("token expired")
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrExpiredToken     = errors.New("token expired")
+	ErrInvalidTokenType = errors.New("invalid token type")
 )
 
+// TokenType tells what a token was issued for, so a token issued for one purpose
+// (e.g. refresh) can't be used in place of another (e.g. access)
+type TokenType string
+
+const (
+	TokenTypeAccess    TokenType = "access"
+	TokenTypeRefresh   TokenType = "refresh"
+	TokenTypeMFA       TokenType = "mfa_challenge" // password checked, second factor still due
+	TokenTypeMagicLink TokenType = "magic_link"    // emailed passwordless login link, single use
+)
+
+// DefaultKeyID is the kid of the HS256 key built from config.JWT.SecretKey
+const DefaultKeyID = "default"
+
+var keySet *KeySet
+
+// SetKeySet replaces the keys used to sign and verify tokens
+func SetKeySet(ks *KeySet) {
+	keySet = ks
+}
+
+// currentKeySet falls back to the shared HS256 secret when no key set was configured
+func currentKeySet() *KeySet {
+	if keySet != nil {
+		return keySet
+	}
+	key := NewHMACKey(DefaultKeyID, []byte(config.JWT.SecretKey))
+	return &KeySet{active: key, keys: map[string]*Key{key.ID: key}}
+}
+
+// PublicJWKS returns the public keys of the current key set
+func PublicJWKS() JWKS {
+	return currentKeySet().JWKS()
+}
+
+// JWTClaims name users by their public ID, tokens being readable by anyone holding
+// them. The server resolves it to the internal ID
 type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	SessionID string    `json:"sid,omitempty"` // login session (refresh token family) the token belongs to
+	TokenType TokenType `json:"token_type"`
+	// Admin acting as the user, set on impersonation tokens only
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// Generate JWT token
-func GenerateToken(userID uint, email, role string, secretKey string, expireTime time.Duration) (string, error) {
-	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expireTime)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+// Generate JWT token. sessionID is empty for tokens issued outside of a login session
+func GenerateToken(userID string, email, role, sessionID string, tokenType TokenType, expireTime time.Duration) (string, error) {
+	return generateToken(JWTClaims{
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
+		TokenType: tokenType,
+	}, expireTime)
+}
+
+// GenerateImpersonationToken issues an access token for the user that also names the
+// admin acting as them
+func GenerateImpersonationToken(userID string, email, role, sessionID string, impersonatorID string, expireTime time.Duration) (string, error) {
+	return generateToken(JWTClaims{
+		UserID:         userID,
+		Email:          email,
+		Role:           role,
+		SessionID:      sessionID,
+		TokenType:      TokenTypeAccess,
+		ImpersonatorID: impersonatorID,
+	}, expireTime)
+}
+
+func generateToken(claims JWTClaims, expireTime time.Duration) (string, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(secretKey))
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        tokenID,
+		Subject:   claims.UserID,
+		Issuer:    config.JWT.Issuer,
+		Audience:  jwt.ClaimStrings{config.JWT.Audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(expireTime)),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+
+	tokenString, err := currentKeySet().sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -40,11 +112,9 @@ func GenerateToken(userID uint, email, role string, secretKey string, expireTime
 	return tokenString, nil
 }
 
-// Parse and validate JWT token
-func ValidateToken(tokenString, secretKey string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secretKey), nil
-	})
+// Parse and validate JWT token, only accepting tokens of the expected type
+func ValidateToken(tokenString string, expectedType TokenType) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, currentKeySet().keyFunc)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -58,5 +128,21 @@ func ValidateToken(tokenString, secretKey string) (*JWTClaims, error) {
 		return nil, ErrInvalidToken
 	}
 
+	if claims.ID == "" || !claims.VerifyIssuer(config.JWT.Issuer, true) || !claims.VerifyAudience(config.JWT.Audience, true) {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.TokenType != expectedType {
+		return nil, ErrInvalidTokenType
+	}
+
 	return claims, nil
 }
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
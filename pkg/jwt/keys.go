@@ -0,0 +1,187 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const minRSAKeyBits = 2048
+
+// Key is a key identified by its kid. It can always verify tokens, and can also
+// sign them when the private part is loaded
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// CanSign reports whether the key holds a private (or shared) secret
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// NewHMACKey creates a HS256 key from a shared secret
+func NewHMACKey(kid string, secret []byte) *Key {
+	return &Key{ID: kid, Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+}
+
+// NewHMACVerifyKey creates a HS256 key that verifies tokens signed with the shared
+// secret but never signs new ones, for a secret being retired
+func NewHMACVerifyKey(kid string, secret []byte) *Key {
+	return &Key{ID: kid, Method: jwt.SigningMethodHS256, verifyKey: secret}
+}
+
+// ParseKeyPEM loads a RSA (RS256) or Ed25519 (EdDSA) key from PEM. Private keys can
+// sign and verify, public keys only verify which is enough for retired keys
+func ParseKeyPEM(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: no PEM block found", kid)
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %s: unsupported PEM block type %q", kid, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", kid, err)
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("key %s: RSA keys must be at least %d bits", kid, minRSAKeyBits)
+		}
+		return &Key{ID: kid, Method: jwt.SigningMethodRS256, signKey: k, verifyKey: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("key %s: RSA keys must be at least %d bits", kid, minRSAKeyBits)
+		}
+		return &Key{ID: kid, Method: jwt.SigningMethodRS256, verifyKey: k}, nil
+	case ed25519.PrivateKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodEdDSA, signKey: k, verifyKey: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodEdDSA, verifyKey: k}, nil
+	default:
+		return nil, fmt.Errorf("key %s: unsupported key type %T", kid, parsed)
+	}
+}
+
+// KeySet holds the key used to sign new tokens and every key still accepted for
+// verification. Keeping retired keys in the set lets tokens signed before a
+// rotation stay valid until they expire
+type KeySet struct {
+	active *Key
+	keys   map[string]*Key
+}
+
+func NewKeySet(activeKID string, keys ...*Key) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*Key, len(keys))}
+	for _, key := range keys {
+		if _, exists := ks.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %s", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	active, ok := ks.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("active key %s not found", activeKID)
+	}
+	if !active.CanSign() {
+		return nil, fmt.Errorf("active key %s has no private key", activeKID)
+	}
+	ks.active = active
+	return ks, nil
+}
+
+// ActiveKeyID returns the kid new tokens are signed with
+func (ks *KeySet) ActiveKeyID() string {
+	return ks.active.ID
+}
+
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.Method, claims)
+	token.Header["kid"] = ks.active.ID
+	return token.SignedString(ks.active.signKey)
+}
+
+// keyFunc picks the verification key from the token's kid and refuses tokens whose
+// alg doesn't match that key, so a public key can never be used as a HMAC secret
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key := ks.active
+	if kid != "" {
+		key = ks.keys[kid]
+	}
+	if key == nil || token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+	return key.verifyKey, nil
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public part of every asymmetric key in the set.
+// HMAC secrets are never published
+func (ks *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid < jwks.Keys[j].Kid })
+	return jwks
+}
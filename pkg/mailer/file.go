@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer drops every email as an .eml file in a directory, so it can be opened
+// with a mail client or read by tests
+type FileMailer struct {
+	from string
+	dir  string
+}
+
+func NewFileMailer(from string, dir string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileMailer{from: from, dir: dir}, nil
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+	return os.WriteFile(filepath.Join(m.dir, name), buildMessage(m.from, msg), 0o644)
+}
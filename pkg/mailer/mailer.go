@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"base_go_be/pkg/setting"
+	"bytes"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails. Drivers: smtp for real delivery, log and file for local testing
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// NewMailer creates the mailer selected by config.Driver
+func NewMailer(config setting.MailSetting, logger *zap.Logger) (Mailer, error) {
+	switch config.Driver {
+	case "smtp", "":
+		return NewSMTPMailer(config), nil
+	case "file":
+		return NewFileMailer(config.From, config.FileDir)
+	case "log":
+		return NewLogMailer(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", config.Driver)
+	}
+}
+
+// buildMessage renders msg as a RFC 5322 message
+func buildMessage(from string, msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return buf.Bytes()
+}
@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"base_go_be/pkg/setting"
+	"fmt"
+	"net/smtp"
+)
+
+// SMTPMailer sends emails through an SMTP server
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(config setting.MailSetting) *SMTPMailer {
+	var auth smtp.Auth
+	if config.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", config.SMTPUsername, config.SMTPPassword, config.SMTPHost)
+	}
+	return &SMTPMailer{
+		addr: fmt.Sprintf("%s:%d", config.SMTPHost, config.SMTPPort),
+		from: config.From,
+		auth: auth,
+	}
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	return smtp.SendMail(m.addr, m.auth, m.from, msg.To, buildMessage(m.from, msg))
+}
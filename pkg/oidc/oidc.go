@@ -0,0 +1,162 @@
+package oidc
+
+import (
+	"base_go_be/pkg/setting"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrInvalidIDToken = errors.New("invalid id token")
+	ErrNonceMismatch  = errors.New("id token nonce mismatch")
+)
+
+// DefaultScopes are requested when a provider doesn't configure any
+var DefaultScopes = []string{gooidc.ScopeOpenID, "email", "profile"}
+
+// Identity is the user as asserted by the provider's ID token
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider runs the authorization code flow with PKCE against an OpenID Connect
+// provider. Discovery happens on first use and is retried until it succeeds, so a
+// provider being down doesn't prevent the server from starting
+type Provider struct {
+	config   setting.OIDCProviderSetting
+	mu       sync.Mutex
+	provider *gooidc.Provider
+}
+
+func NewProvider(config setting.OIDCProviderSetting) *Provider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = DefaultScopes
+	}
+	return &Provider{config: config}
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+func (p *Provider) discover(ctx context.Context) (*gooidc.Provider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.provider == nil {
+		provider, err := gooidc.NewProvider(ctx, p.config.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("oidc discovery for %s: %w", p.config.Name, err)
+		}
+		p.provider = provider
+	}
+	return p.provider, nil
+}
+
+func (p *Provider) oauth2Config(provider *gooidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.config.Scopes,
+	}
+}
+
+// AuthCodeURL returns the provider's login page URL. state, nonce and verifier must
+// be kept by the caller to complete the login with Exchange
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, verifier string) (string, error) {
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return p.oauth2Config(provider).AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Exchange redeems the authorization code and validates the ID token: signature
+// against the provider's JWKS, issuer, audience, expiry and nonce
+func (p *Provider) Exchange(ctx context.Context, code string, verifier string, nonce string) (*Identity, error) {
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.oauth2Config(provider).Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, err
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, ErrInvalidIDToken
+	}
+
+	idToken, err := provider.Verifier(&gooidc.Config{ClientID: p.config.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified any    `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	return &Identity{
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: isTrue(claims.EmailVerified),
+		Name:          claims.Name,
+	}, nil
+}
+
+// isTrue reads email_verified, which some providers send as a string
+func isTrue(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
+}
+
+// GenerateVerifier returns a PKCE code verifier
+func GenerateVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]*Provider{}
+)
+
+// SetProviders replaces the providers users can log in with
+func SetProviders(list ...*Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers = make(map[string]*Provider, len(list))
+	for _, p := range list {
+		providers[p.Name()] = p
+	}
+}
+
+// GetProvider returns the configured provider, nil if there is none by that name
+func GetProvider(name string) *Provider {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	return providers[name]
+}
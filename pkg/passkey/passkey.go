@@ -0,0 +1,134 @@
+package passkey
+
+import (
+	"base_go_be/pkg/setting"
+	"bytes"
+	"errors"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+var (
+	ErrInvalidUserHandle = errors.New("invalid user handle")
+	// ErrCloneDetected means the sign counter went backwards, so two copies of the
+	// authenticator's key may exist
+	ErrCloneDetected = errors.New("authenticator sign counter regressed")
+)
+
+// User is an account as seen by the WebAuthn ceremonies
+type User struct {
+	// Handle names the user in their passkeys, see UserHandle
+	Handle      []byte
+	Name        string
+	DisplayName string
+	Credentials []webauthn.Credential
+}
+
+var _ webauthn.User = (*User)(nil)
+
+func (u *User) WebAuthnID() []byte {
+	return u.Handle
+}
+
+func (u *User) WebAuthnName() string {
+	return u.Name
+}
+
+func (u *User) WebAuthnDisplayName() string {
+	return u.DisplayName
+}
+
+func (u *User) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *User) WebAuthnCredentials() []webauthn.Credential {
+	return u.Credentials
+}
+
+// UserHandle is what new passkeys store to name the user, which discoverable logins
+// return: the public ID, as the handle can be read by anyone holding the authenticator
+func UserHandle(publicID string) []byte {
+	return []byte(publicID)
+}
+
+// RelyingParty runs the registration and login ceremonies. Passkeys are discoverable
+// credentials and user verification is required, so a login needs no username and
+// counts as multi-factor
+type RelyingParty struct {
+	webAuthn *webauthn.WebAuthn
+}
+
+func New(config setting.WebAuthnSetting) (*RelyingParty, error) {
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          config.RPID,
+		RPDisplayName: config.RPDisplayName,
+		RPOrigins:     config.RPOrigins,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RelyingParty{webAuthn: webAuthn}, nil
+}
+
+// BeginRegistration returns the options for navigator.credentials.create() and the
+// session data to keep until FinishRegistration. Existing passkeys are excluded
+func (rp *RelyingParty) BeginRegistration(user *User) (*protocol.CredentialCreation, *webauthn.SessionData, error) {
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.Credentials))
+	for _, credential := range user.Credentials {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+	return rp.webAuthn.BeginRegistration(user,
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+		webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationRequired,
+		}),
+		webauthn.WithExclusions(exclusions),
+	)
+}
+
+// FinishRegistration verifies the authenticator's response (the JSON of the
+// PublicKeyCredential) and returns the credential to store
+func (rp *RelyingParty) FinishRegistration(user *User, session webauthn.SessionData, response []byte) (*webauthn.Credential, error) {
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(response))
+	if err != nil {
+		return nil, err
+	}
+	return rp.webAuthn.CreateCredential(user, session, parsed)
+}
+
+// BeginLogin returns the options for navigator.credentials.get() and the session data
+// to keep until FinishLogin
+func (rp *RelyingParty) BeginLogin() (*protocol.CredentialAssertion, *webauthn.SessionData, error) {
+	return rp.webAuthn.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+}
+
+// FinishLogin verifies the assertion against the passkey of the user its handle names,
+// loaded with findUser, and returns the user and the credential with its new sign counter
+func (rp *RelyingParty) FinishLogin(session webauthn.SessionData, response []byte, findUser func(handle []byte) (*User, error)) (*User, *webauthn.Credential, error) {
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(response))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var user *User
+	credential, err := rp.webAuthn.ValidateDiscoverableLogin(func(_, userHandle []byte) (webauthn.User, error) {
+		var err error
+		if user, err = findUser(userHandle); err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrInvalidUserHandle
+		}
+		return user, nil
+	}, session, parsed)
+	if err != nil {
+		return nil, nil, err
+	}
+	if credential.Authenticator.CloneWarning {
+		return nil, nil, ErrCloneDetected
+	}
+	return user, credential, nil
+}
@@ -0,0 +1,119 @@
+package passwd
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// Params are the argon2id cost parameters new hashes are made with
+type Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultParams follow the OWASP recommendation for argon2id
+var DefaultParams = Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+var (
+	paramsMu sync.RWMutex
+	params   = DefaultParams
+)
+
+// SetParams changes the cost of new hashes. Hashes made with other parameters still
+// verify, and are reported as needing a rehash
+func SetParams(p Params) {
+	paramsMu.Lock()
+	defer paramsMu.Unlock()
+	params = p
+}
+
+func currentParams() Params {
+	paramsMu.RLock()
+	defer paramsMu.RUnlock()
+	return params
+}
+
+// Hash returns the argon2id hash of the password in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func Hash(password string) (string, error) {
+	p := currentParams()
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify checks the password against an argon2id or a legacy bcrypt hash. rehash is
+// true when the password matches but the hash isn't argon2id with the current
+// parameters, so the caller should store a new one
+func Verify(hash string, password string) (match bool, rehash bool, err error) {
+	if strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$") {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		return err == nil, err == nil, err
+	}
+
+	p, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return false, false, err
+	}
+	computed := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return false, false, nil
+	}
+	current := currentParams()
+	rehash = p.Memory != current.Memory || p.Iterations != current.Iterations ||
+		p.Parallelism != current.Parallelism || p.KeyLength != current.KeyLength || p.SaltLength != current.SaltLength
+	return true, rehash, nil
+}
+
+func decodeArgon2id(hash string) (Params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Params{}, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Params{}, nil, nil, ErrUnknownHash
+	}
+	var p Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return Params{}, nil, nil, ErrUnknownHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Params{}, nil, nil, ErrUnknownHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Params{}, nil, nil, ErrUnknownHash
+	}
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	return p, salt, key, nil
+}
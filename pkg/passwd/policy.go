@@ -0,0 +1,146 @@
+package passwd
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Rules a password can break, returned in Violation.Rule
+const (
+	RuleMinLength        = "min_length"
+	RuleMaxLength        = "max_length"
+	RuleUppercase        = "uppercase"
+	RuleLowercase        = "lowercase"
+	RuleDigit            = "digit"
+	RuleSymbol           = "symbol"
+	RuleBreached         = "breached"
+	RuleContainsEmail    = "contains_email"
+	RuleContainsUsername = "contains_username"
+)
+
+// minIdentifierLength keeps short usernames (e.g. "al") from banning every password
+// that happens to contain them
+const minIdentifierLength = 3
+
+//go:embed breached.txt
+var defaultBreached string
+
+// Violation is one policy rule the password doesn't meet
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Policy is what a new password must satisfy
+type Policy struct {
+	MinLength     int
+	MaxLength     int // 0 means no limit
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	breached      map[string]struct{}
+}
+
+// DefaultPolicy checks against the embedded list of most common leaked passwords
+func DefaultPolicy() *Policy {
+	p := &Policy{MinLength: 8, MaxLength: 128, RequireUpper: true, RequireLower: true, RequireDigit: true}
+	_ = p.AddBreached(strings.NewReader(defaultBreached))
+	return p
+}
+
+// AddBreached adds the passwords of a list, one per line, to those refused as breached.
+// Matching ignores case
+func (p *Policy) AddBreached(r io.Reader) error {
+	if p.breached == nil {
+		p.breached = make(map[string]struct{})
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			p.breached[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	return scanner.Err()
+}
+
+// Check returns every rule the password breaks, none meaning it is accepted. email and
+// username are those of the account the password is for
+func (p *Policy) Check(password string, email string, username string) []Violation {
+	var violations []Violation
+	length := len([]rune(password))
+	if length < p.MinLength {
+		violations = append(violations, Violation{RuleMinLength, fmt.Sprintf("Password must be at least %d characters long", p.MinLength)})
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, Violation{RuleMaxLength, fmt.Sprintf("Password must be at most %d characters long", p.MaxLength)})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, Violation{RuleUppercase, "Password must contain an uppercase letter"})
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, Violation{RuleLowercase, "Password must contain a lowercase letter"})
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, Violation{RuleDigit, "Password must contain a digit"})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, Violation{RuleSymbol, "Password must contain a symbol"})
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := p.breached[lower]; ok {
+		violations = append(violations, Violation{RuleBreached, "Password is too common and appears in known data breaches"})
+	}
+	localPart, _, _ := strings.Cut(strings.ToLower(email), "@")
+	if containsIdentifier(lower, localPart) {
+		violations = append(violations, Violation{RuleContainsEmail, "Password must not contain your email"})
+	}
+	if containsIdentifier(lower, strings.ToLower(username)) {
+		violations = append(violations, Violation{RuleContainsUsername, "Password must not contain your username"})
+	}
+	return violations
+}
+
+func containsIdentifier(password string, identifier string) bool {
+	identifier = strings.TrimSpace(identifier)
+	return len([]rune(identifier)) >= minIdentifierLength && strings.Contains(password, identifier)
+}
+
+var (
+	policyMu sync.RWMutex
+	policy   = DefaultPolicy()
+)
+
+// SetPolicy replaces the policy new passwords are checked against
+func SetPolicy(p *Policy) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	policy = p
+}
+
+// GetPolicy returns the current policy
+func GetPolicy() *Policy {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return policy
+}
@@ -1,25 +1,93 @@
 package response
 
 const (
-	ErrCodeSuccess       = 2001  //Success
-	ErrCodeInvalidParams = 2002  //Email invalid
-	ErrInvalidToken      = 3001  //Token invalid
-	ErrCodeUserHasExists = 50001 // User already exist
-	ErrCodeUserNotFound  = 4000  // User not found
-	ErrCodeInvalidLogin  = 4001  // Invalid login credentials
-	ErrCodeAccessDenied  = 4003  // Access denied
-	ErrCodeInternalError = 5000  // Internal server error
+	ErrCodeSuccess                 = 2001  //Success
+	ErrCodeInvalidParams           = 2002  //Email invalid
+	ErrInvalidToken                = 3001  //Token invalid
+	ErrCodeTokenReused             = 3002  // Refresh token already used
+	ErrCodeInvalidSignature        = 3003  // Request signature invalid
+	ErrCodeRequestExpired          = 3004  // Request timestamp outside the allowed window
+	ErrCodeRequestReplayed         = 3005  // Signed request already received
+	ErrCodeUserHasExists           = 50001 // User already exist
+	ErrCodeUserNotFound            = 4000  // User not found
+	ErrCodeInvalidLogin            = 4001  // Invalid login credentials
+	ErrCodeAccessDenied            = 4003  // Access denied
+	ErrCodeRoleNotFound            = 4004  // Role not found
+	ErrCodePermissionNotFound      = 4005  // Permission not found
+	ErrCodeAccountInactive         = 4006  // Account deactivated
+	ErrCodeEmailNotVerified        = 4007  // Email not verified
+	ErrCodeTooManyRequests         = 4008  // Too many requests
+	ErrCodeAccountLocked           = 4009  // Too many failed logins
+	ErrCodeInvalidMfaCode          = 4010  // Wrong 2FA code
+	ErrCodeMfaAlreadyEnabled       = 4011  // 2FA already enabled
+	ErrCodeMfaNotEnabled           = 4012  // 2FA not enabled
+	ErrCodeMfaRequired             = 4013  // 2FA can't be turned off for the role
+	ErrCodeSessionNotFound         = 4014  // Session not found
+	ErrCodeInvalidScope            = 4015  // Scope not granted to the user
+	ErrCodePersonalTokenNotFound   = 4016  // Personal access token not found
+	ErrCodeServiceAccountNotFound  = 4017  // Service account not found
+	ErrCodeApiKeyNotFound          = 4018  // Service account key not found
+	ErrCodeOidcProviderNotFound    = 4019  // External login provider not configured
+	ErrCodeInvalidOidcState        = 4020  // External login state unknown or expired
+	ErrCodeOidcLoginFailed         = 4021  // External login rejected by the provider or invalid
+	ErrCodeOidcEmailNotVerified    = 4022  // Provider didn't verify the email
+	ErrCodePasskeyNotFound         = 4023  // Passkey not found
+	ErrCodeInvalidPasskey          = 4024  // Passkey registration or assertion rejected
+	ErrCodeInvalidPasskeyCeremony  = 4025  // Passkey ceremony unknown or expired
+	ErrCodeWeakPassword            = 4026  // Password doesn't meet the password policy
+	ErrCodeImpersonationForbidden  = 4027  // Action not allowed while impersonating, or user can't be impersonated
+	ErrCodeInvalidImage            = 4028  // Upload isn't a supported image or is too large
+	ErrCodeInvalidLocale           = 4029  // Locale isn't a valid BCP 47 tag
+	ErrCodeInvalidTimezone         = 4030  // Timezone isn't a known IANA name
+	ErrCodeUseChangePassword       = 4031  // Own password changed outside of the password change endpoint
+	ErrCodeRoleHasExists           = 50002 // Role already exist
+	ErrCodeServiceAccountHasExists = 50003 // Service account already exist
+	ErrCodeInternalError           = 5000  // Internal server error
 )
 
 var msg = map[int]string{
-	ErrCodeSuccess:       "Success",
-	ErrInvalidToken:      "Token invalid",
-	ErrCodeInvalidParams: "Email invalid",
-	ErrCodeUserHasExists: "User already exist",
-	ErrCodeUserNotFound:  "User not found",
-	ErrCodeInvalidLogin:  "Invalid login credentials",
-	ErrCodeAccessDenied:  "Access denied",
-	ErrCodeInternalError: "Internal server error",
+	ErrCodeSuccess:                 "Success",
+	ErrInvalidToken:                "Token invalid",
+	ErrCodeTokenReused:             "Refresh token already used",
+	ErrCodeInvalidSignature:        "Request signature invalid",
+	ErrCodeRequestExpired:          "Request timestamp outside the allowed window",
+	ErrCodeRequestReplayed:         "Request already received",
+	ErrCodeInvalidParams:           "Email invalid",
+	ErrCodeUserHasExists:           "User already exist",
+	ErrCodeUserNotFound:            "User not found",
+	ErrCodeInvalidLogin:            "Invalid login credentials",
+	ErrCodeAccessDenied:            "Access denied",
+	ErrCodeRoleNotFound:            "Role not found",
+	ErrCodePermissionNotFound:      "Permission not found",
+	ErrCodeAccountInactive:         "Account is deactivated",
+	ErrCodeEmailNotVerified:        "Email not verified",
+	ErrCodeTooManyRequests:         "Too many requests, please try again later",
+	ErrCodeAccountLocked:           "Account temporarily locked after too many failed login attempts",
+	ErrCodeInvalidMfaCode:          "Invalid two-factor authentication code",
+	ErrCodeMfaAlreadyEnabled:       "Two-factor authentication is already enabled",
+	ErrCodeMfaNotEnabled:           "Two-factor authentication is not enabled",
+	ErrCodeMfaRequired:             "Two-factor authentication is required for your role",
+	ErrCodeSessionNotFound:         "Session not found",
+	ErrCodeInvalidScope:            "Scope is not granted to your role",
+	ErrCodePersonalTokenNotFound:   "Personal access token not found",
+	ErrCodeServiceAccountNotFound:  "Service account not found",
+	ErrCodeApiKeyNotFound:          "API key not found",
+	ErrCodeOidcProviderNotFound:    "Login provider not found",
+	ErrCodeInvalidOidcState:        "Login request expired, please try again",
+	ErrCodeOidcLoginFailed:         "External login failed",
+	ErrCodeOidcEmailNotVerified:    "Email not verified by the login provider",
+	ErrCodePasskeyNotFound:         "Passkey not found",
+	ErrCodeInvalidPasskey:          "Passkey verification failed",
+	ErrCodeInvalidPasskeyCeremony:  "Passkey request expired, please try again",
+	ErrCodeWeakPassword:            "Password does not meet the password policy",
+	ErrCodeImpersonationForbidden:  "Not allowed while impersonating a user",
+	ErrCodeInvalidImage:            "Image must be a JPEG, PNG or GIF of reasonable size",
+	ErrCodeInvalidLocale:           "Locale is not valid",
+	ErrCodeInvalidTimezone:         "Timezone is not valid",
+	ErrCodeUseChangePassword:       "Change your own password with POST /v1/user/me/password",
+	ErrCodeRoleHasExists:           "Role already exist",
+	ErrCodeServiceAccountHasExists: "Service account already exist",
+	ErrCodeInternalError:           "Internal server error",
 }
 
 // GetMessage - Get message from error code
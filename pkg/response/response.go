@@ -51,6 +51,16 @@ func NewServiceErrorWithCode(statusCode int, errorCode int) *ServiceResult {
 	}
 }
 
+// NewServiceErrorWithData - Create a ServiceResult error using error code, with details in data
+func NewServiceErrorWithData(statusCode int, errorCode int, data interface{}) *ServiceResult {
+	return &ServiceResult{
+		Data:       data,
+		Error:      fmt.Errorf("%s", GetMessage(errorCode)),
+		StatusCode: statusCode,
+		ErrorCode:  errorCode,
+	}
+}
+
 // DataDetailResponse - Return response with custom code, message, and data
 func DataDetailResponse(c *gin.Context, statusCode int, code int, data interface{}) {
 	c.JSON(statusCode, Response{
@@ -75,7 +85,7 @@ func HandleServiceResult(c *gin.Context, result *ServiceResult) {
 	if result.Error != nil {
 		if result.ErrorCode != 0 {
 			// Use DataDetailResponse for errors with error code
-			DataDetailResponse(c, result.StatusCode, result.ErrorCode, nil)
+			DataDetailResponse(c, result.StatusCode, result.ErrorCode, result.Data)
 		} else {
 			// Use ErrorResponse for normal errors
 			ErrorResponse(c, result.StatusCode, result.Error.Error())
@@ -7,16 +7,25 @@ import (
 )
 
 type Config struct {
-	Server   ServerSetting   `map_structure:"server"`
-	Mysql    MySQLSetting    `map_structure:"mysql"`
-	Postgres PostgresSetting `map_structure:"postgres"`
-	Redis    RedisSetting    `map_structure:"redis"`
-	Logger   LoggerSetting   `map_structure:"logger"`
+	Server   ServerSetting         `map_structure:"server"`
+	Mysql    MySQLSetting          `map_structure:"mysql"`
+	Postgres PostgresSetting       `map_structure:"postgres"`
+	Redis    RedisSetting          `map_structure:"redis"`
+	Logger   LoggerSetting         `map_structure:"logger"`
+	Mail     MailSetting           `map_structure:"mail"`
+	Auth     AuthSetting           `map_structure:"auth"`
+	Security SecuritySetting       `map_structure:"security"`
+	Password PasswordSetting       `map_structure:"password"`
+	OIDC     []OIDCProviderSetting `map_structure:"oidc"`
+	WebAuthn WebAuthnSetting       `map_structure:"webauthn"`
+	Storage  StorageSetting        `map_structure:"storage"`
 }
 
 type ServerSetting struct {
-	Port int    `map_structure:"port"`
-	Mode string `map_structure:"mode"`
+	Port           int      `map_structure:"port"`
+	Mode           string   `map_structure:"mode"`
+	FrontendURL    string   `map_structure:"frontend_url"`
+	TrustedProxies []string `map_structure:"trusted_proxies"`
 }
 
 type MySQLSetting struct {
@@ -58,8 +67,80 @@ type RedisSetting struct {
 	Database int    `map_structure:"database"`
 }
 
+type MailSetting struct {
+	Driver       string `map_structure:"driver"` // smtp, log or file
+	From         string `map_structure:"from"`
+	SMTPHost     string `map_structure:"smtp_host"`
+	SMTPPort     int    `map_structure:"smtp_port"`
+	SMTPUsername string `map_structure:"smtp_username"`
+	SMTPPassword string `map_structure:"smtp_password"`
+	FileDir      string `map_structure:"file_dir"`
+}
+
+type AuthSetting struct {
+	PasswordResetTTL         int      `map_structure:"password_reset_ttl"` // minutes
+	RequireEmailVerification bool     `map_structure:"require_email_verification"`
+	EmailVerificationTTL     int      `map_structure:"email_verification_ttl"` // minutes
+	LoginMaxAttempts         int      `map_structure:"login_max_attempts"`     // failures per email before lockout
+	LoginIPMaxAttempts       int      `map_structure:"login_ip_max_attempts"`  // failures per IP before refusing logins
+	LoginAttemptWindow       int      `map_structure:"login_attempt_window"`   // minutes
+	LoginLockoutDuration     int      `map_structure:"login_lockout_duration"` // minutes
+	MFAIssuer                string   `map_structure:"mfa_issuer"`             // shown in authenticator apps
+	MFARequiredRoles         []string `map_structure:"mfa_required_roles"`     // roles that must enroll TOTP
+	SignatureWindow          int      `map_structure:"signature_window"`       // seconds a signed request stays valid
+	OIDCAutoRegister         bool     `map_structure:"oidc_auto_register"`     // create users on first external login
+	MagicLinkTTL             int      `map_structure:"magic_link_ttl"`         // minutes
+	ImpersonationTTL         int      `map_structure:"impersonation_ttl"`      // minutes
+	DeletedUserRetention     int      `map_structure:"deleted_user_retention"` // days before deleted users are purged
+	UserPurgeInterval        int      `map_structure:"user_purge_interval"`    // minutes between purges, 0 disables them
+}
+
+type SecuritySetting struct {
+	EncryptionKey string `map_structure:"encryption_key"` // secret for values encrypted at rest
+}
+
+// PasswordSetting is the policy new passwords must meet and the cost of their hash
+type PasswordSetting struct {
+	MinLength         int    `map_structure:"min_length"`
+	MaxLength         int    `map_structure:"max_length"`
+	RequireUpper      bool   `map_structure:"require_upper"`
+	RequireLower      bool   `map_structure:"require_lower"`
+	RequireDigit      bool   `map_structure:"require_digit"`
+	RequireSymbol     bool   `map_structure:"require_symbol"`
+	BreachedListFile  string `map_structure:"breached_list_file"` // extra refused passwords, one per line
+	Argon2Memory      int    `map_structure:"argon2_memory"`      // KiB
+	Argon2Iterations  int    `map_structure:"argon2_iterations"`
+	Argon2Parallelism int    `map_structure:"argon2_parallelism"`
+}
+
+// OIDCProviderSetting is an external OpenID Connect provider users can log in with
+type OIDCProviderSetting struct {
+	Name         string   `map_structure:"name"`   // used in the login URLs, e.g. google
+	Issuer       string   `map_structure:"issuer"` // discovery is done from <issuer>/.well-known/openid-configuration
+	ClientID     string   `map_structure:"client_id"`
+	ClientSecret string   `map_structure:"client_secret"`
+	RedirectURL  string   `map_structure:"redirect_url"` // frontend page receiving the code and state
+	Scopes       []string `map_structure:"scopes"`
+}
+
+// WebAuthnSetting identifies this site (the relying party) to passkey authenticators
+type WebAuthnSetting struct {
+	RPID          string   `map_structure:"rp_id"`      // domain passkeys are bound to, e.g. kado.example.com
+	RPDisplayName string   `map_structure:"rp_name"`    // shown by the authenticator
+	RPOrigins     []string `map_structure:"rp_origins"` // frontend origins allowed to run the ceremonies
+}
+
+// StorageSetting is where uploaded files, like avatars, are kept
+type StorageSetting struct {
+	Driver        string `map_structure:"driver"`          // local
+	LocalDir      string `map_structure:"local_dir"`       // directory of the local driver
+	PublicURL     string `map_structure:"public_url"`      // prefix of file URLs, /uploads by default
+	AvatarMaxSize int    `map_structure:"avatar_max_size"` // KiB
+}
+
 type WebSocketManager interface {
 	Broadcast(message map[string]any)
+	// users are addressed by their public ID
 	SendToUser(userID string, message map[string]any) int
 	Connect(w http.ResponseWriter, r *http.Request, userID string) (*websocket.Conn, error)
 	Disconnect(ws *websocket.Conn)
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalRoute is the path the app serves the files of a LocalStorage under
+const LocalRoute = "/uploads"
+
+// LocalStorage keeps files in a directory, served by the app under LocalRoute
+type LocalStorage struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStorage stores files in dir. baseURL prefixes their URLs, LocalRoute when
+// empty, e.g. to go through a CDN
+func NewLocalStorage(dir string, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if baseURL == "" {
+		baseURL = LocalRoute
+	}
+	return &LocalStorage{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+// Dir is the directory the files are stored in
+func (s *LocalStorage) Dir() string {
+	return s.dir
+}
+
+// Put writes the file through a temporary one, so readers never see it half written
+func (s *LocalStorage) Put(key string, r io.Reader, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// path maps the key into the directory, refusing keys that would escape it
+func (s *LocalStorage) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." || strings.HasPrefix(part, ".") {
+			return "", ErrInvalidKey
+		}
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"base_go_be/pkg/setting"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage keeps uploaded files under keys like "avatars/<name>.png". Drivers: local
+// stores them in a directory the app serves itself
+type Storage interface {
+	Put(key string, r io.Reader, contentType string) error
+	// Delete removes the file, a missing one isn't an error
+	Delete(key string) error
+	// URL is where clients download the file
+	URL(key string) string
+}
+
+// NewStorage creates the storage selected by config.Driver
+func NewStorage(config setting.StorageSetting) (Storage, error) {
+	switch config.Driver {
+	case "local", "":
+		return NewLocalStorage(config.LocalDir, config.PublicURL)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", config.Driver)
+	}
+}
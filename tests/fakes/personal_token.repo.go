@@ -0,0 +1,80 @@
+package fakes
+
+import (
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"sync"
+	"time"
+)
+
+// PersonalTokenRepository keeps personal access tokens by their clear value
+type PersonalTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]*model.PersonalAccessToken
+	nextID uint
+}
+
+var _ repo.IPersonalTokenRepository = (*PersonalTokenRepository)(nil)
+
+func NewPersonalTokenRepository() *PersonalTokenRepository {
+	return &PersonalTokenRepository{tokens: make(map[string]*model.PersonalAccessToken)}
+}
+
+func (r *PersonalTokenRepository) CreatePersonalToken(token *model.PersonalAccessToken) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	token.ID = r.nextID
+	token.CreatedAt = time.Now()
+	plain := model.PersonalTokenPrefix + randomID()
+	token.TokenPrefix = plain[:len(model.PersonalTokenPrefix)+4]
+	copied := *token
+	r.tokens[plain] = &copied
+	return plain, nil
+}
+
+func (r *PersonalTokenRepository) GetPersonalTokenByToken(plainToken string) *model.PersonalAccessToken {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token, ok := r.tokens[plainToken]; ok {
+		copied := *token
+		return &copied
+	}
+	return nil
+}
+
+func (r *PersonalTokenRepository) GetListPersonalToken(userID uint) ([]model.PersonalAccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tokens []model.PersonalAccessToken
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, *token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *PersonalTokenRepository) DeletePersonalToken(userID uint, id uint) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for plain, token := range r.tokens {
+		if token.ID == id && token.UserID == userID {
+			delete(r.tokens, plain)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *PersonalTokenRepository) TouchPersonalToken(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.ID == id {
+			token.LastUsedAt = &now
+		}
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+package fakes
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"sync"
+	"time"
+)
+
+// RoleRepository maps role names to their permissions
+type RoleRepository struct {
+	mu    sync.Mutex
+	roles map[string][]string
+}
+
+// NewRoleRepository starts with the USER and ADMIN roles, ADMIN having permissions
+func NewRoleRepository(adminPermissions ...string) *RoleRepository {
+	return &RoleRepository{roles: map[string][]string{
+		model.RoleUser:  {},
+		model.RoleAdmin: adminPermissions,
+	}}
+}
+
+// SetRole creates or replaces a role, for test setup
+func (r *RoleRepository) SetRole(name string, permissions ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roles[name] = permissions
+}
+
+func (r *RoleRepository) GetListRole() ([]model.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	roles := make([]model.Role, 0, len(r.roles))
+	for name := range r.roles {
+		roles = append(roles, model.Role{Name: name})
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) GetRoleByName(name string) *model.Role {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	permissions, ok := r.roles[name]
+	if !ok {
+		return nil
+	}
+	role := &model.Role{Name: name}
+	for _, permission := range permissions {
+		role.Permissions = append(role.Permissions, model.Permission{Name: permission})
+	}
+	return role
+}
+
+func (r *RoleRepository) CreateRole(role *model.Role) error {
+	r.SetRole(role.Name)
+	return nil
+}
+
+func (r *RoleRepository) GetListPermission() ([]model.Permission, error) {
+	return nil, nil
+}
+
+func (r *RoleRepository) GetPermissionsByNames(names []string) ([]model.Permission, error) {
+	permissions := make([]model.Permission, 0, len(names))
+	for _, name := range names {
+		permissions = append(permissions, model.Permission{Name: name})
+	}
+	return permissions, nil
+}
+
+func (r *RoleRepository) SetRolePermissions(role *model.Role, permissions []model.Permission) error {
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+	r.SetRole(role.Name, names...)
+	return nil
+}
+
+func (r *RoleRepository) GetRolePermissions(roleName string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.roles[roleName]...), nil
+}
+
+// RateLimitRepository counts calls per key, ignoring the window
+type RateLimitRepository struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func NewRateLimitRepository() *RateLimitRepository {
+	return &RateLimitRepository{counts: make(map[string]int)}
+}
+
+func (r *RateLimitRepository) Allow(key string, limit int, window time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts[key]++
+	return r.counts[key] <= limit, nil
+}
+
+// LoginAttemptRepository counts failed logins per email and IP
+type LoginAttemptRepository struct {
+	mu       sync.Mutex
+	failures map[string]int64
+	ips      map[string]int64
+	locked   map[string]time.Duration
+}
+
+func NewLoginAttemptRepository() *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		failures: make(map[string]int64),
+		ips:      make(map[string]int64),
+		locked:   make(map[string]time.Duration),
+	}
+}
+
+func (r *LoginAttemptRepository) GetLockTTL(email string) (time.Duration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.locked[email], nil
+}
+
+func (r *LoginAttemptRepository) GetIPFailures(ip string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ips[ip], nil
+}
+
+func (r *LoginAttemptRepository) RecordFailure(email string, ip string, window time.Duration) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures[email]++
+	r.ips[ip]++
+	return r.failures[email], nil
+}
+
+func (r *LoginAttemptRepository) Lock(email string, duration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.locked[email] = duration
+	return nil
+}
+
+func (r *LoginAttemptRepository) Reset(email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.failures, email)
+	delete(r.locked, email)
+	return nil
+}
+
+// MfaRepository keeps 2FA enrollments and recovery code hashes
+type MfaRepository struct {
+	mu            sync.Mutex
+	mfa           map[uint]model.UserMfa
+	recoveryCodes map[uint][]string
+}
+
+func NewMfaRepository() *MfaRepository {
+	return &MfaRepository{mfa: make(map[uint]model.UserMfa), recoveryCodes: make(map[uint][]string)}
+}
+
+func (r *MfaRepository) GetUserMfa(userID uint) *model.UserMfa {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	mfa, ok := r.mfa[userID]
+	if !ok {
+		return nil
+	}
+	return &mfa
+}
+
+func (r *MfaRepository) SaveUserMfa(mfa *model.UserMfa) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mfa[mfa.UserID] = *mfa
+	return nil
+}
+
+func (r *MfaRepository) UseTotpStep(userID uint, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	mfa, ok := r.mfa[userID]
+	if !ok || step <= mfa.LastUsedStep {
+		return false, nil
+	}
+	mfa.LastUsedStep = step
+	r.mfa[userID] = mfa
+	return true, nil
+}
+
+func (r *MfaRepository) DeleteUserMfa(userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.mfa, userID)
+	delete(r.recoveryCodes, userID)
+	return nil
+}
+
+func (r *MfaRepository) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recoveryCodes[userID] = append([]string(nil), codeHashes...)
+	return nil
+}
+
+func (r *MfaRepository) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, hash := range r.recoveryCodes[userID] {
+		if hash == codeHash {
+			r.recoveryCodes[userID] = append(r.recoveryCodes[userID][:i], r.recoveryCodes[userID][i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// UserIdentityRepository keeps external login identities and OIDC states
+type UserIdentityRepository struct {
+	mu         sync.Mutex
+	identities []model.UserIdentity
+	states     map[string]repo.OidcState
+}
+
+func NewUserIdentityRepository() *UserIdentityRepository {
+	return &UserIdentityRepository{states: make(map[string]repo.OidcState)}
+}
+
+func (r *UserIdentityRepository) GetUserIdentity(provider string, subject string) *model.UserIdentity {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			found := identity
+			return &found
+		}
+	}
+	return nil
+}
+
+func (r *UserIdentityRepository) CreateUserIdentity(identity *model.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	identity.ID = uint(len(r.identities) + 1)
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *UserIdentityRepository) CreateOidcState(state *repo.OidcState, ttl time.Duration) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stateID := randomID()
+	r.states[stateID] = *state
+	return stateID, nil
+}
+
+func (r *UserIdentityRepository) ConsumeOidcState(stateID string) (*repo.OidcState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.states[stateID]
+	if !ok {
+		return nil, nil
+	}
+	delete(r.states, stateID)
+	return &state, nil
+}
+
+// PasskeyRepository keeps passkeys and ceremonies
+type PasskeyRepository struct {
+	mu         sync.Mutex
+	passkeys   []model.Passkey
+	ceremonies map[string]repo.PasskeyCeremony
+}
+
+func NewPasskeyRepository() *PasskeyRepository {
+	return &PasskeyRepository{ceremonies: make(map[string]repo.PasskeyCeremony)}
+}
+
+func (r *PasskeyRepository) GetListPasskey(userID uint) ([]model.Passkey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var passkeys []model.Passkey
+	for _, passkey := range r.passkeys {
+		if passkey.UserID == userID {
+			passkeys = append(passkeys, passkey)
+		}
+	}
+	return passkeys, nil
+}
+
+func (r *PasskeyRepository) CreatePasskey(passkey *model.Passkey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	passkey.ID = uint(len(r.passkeys) + 1)
+	r.passkeys = append(r.passkeys, *passkey)
+	return nil
+}
+
+func (r *PasskeyRepository) UpdatePasskeyUsage(passkey *model.Passkey) error {
+	return nil
+}
+
+func (r *PasskeyRepository) DeletePasskey(userID uint, id uint) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, passkey := range r.passkeys {
+		if passkey.UserID == userID && passkey.ID == id {
+			r.passkeys = append(r.passkeys[:i], r.passkeys[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *PasskeyRepository) CreatePasskeyCeremony(ceremony *repo.PasskeyCeremony, ttl time.Duration) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ceremonyID := randomID()
+	r.ceremonies[ceremonyID] = *ceremony
+	return ceremonyID, nil
+}
+
+func (r *PasskeyRepository) ConsumePasskeyCeremony(ceremonyID string) (*repo.PasskeyCeremony, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ceremony, ok := r.ceremonies[ceremonyID]
+	if !ok {
+		return nil, nil
+	}
+	delete(r.ceremonies, ceremonyID)
+	return &ceremony, nil
+}
+
+// AuditLogRepository records audit entries
+type AuditLogRepository struct {
+	mu      sync.Mutex
+	entries []model.AuditLog
+}
+
+func NewAuditLogRepository() *AuditLogRepository {
+	return &AuditLogRepository{}
+}
+
+func (r *AuditLogRepository) CreateAuditLog(entry *model.AuditLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry.ID = uint(len(r.entries) + 1)
+	entry.CreatedAt = time.Now()
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *AuditLogRepository) GetListAuditLog(req dto.AuditLogListRequestDto) ([]model.AuditLog, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]model.AuditLog(nil), r.entries...), int64(len(r.entries)), nil
+}
+
+// Actions returns the actions recorded, oldest first
+func (r *AuditLogRepository) Actions() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actions := make([]string, 0, len(r.entries))
+	for _, entry := range r.entries {
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
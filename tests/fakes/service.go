@@ -0,0 +1,37 @@
+package fakes
+
+import "base_go_be/internal/service"
+
+// UserServiceRepos are the fakes behind a user service built by NewUserService
+type UserServiceRepos struct {
+	Users         *UserRepository
+	Tokens        *TokenRepository
+	Roles         *RoleRepository
+	RateLimits    *RateLimitRepository
+	LoginAttempts *LoginAttemptRepository
+	Mfa           *MfaRepository
+	Sessions      *SessionRepository
+	Identities    *UserIdentityRepository
+	Passkeys      *PasskeyRepository
+	AuditLogs     *AuditLogRepository
+}
+
+// NewUserService builds the user service on fresh fakes. The ADMIN role gets the
+// admin permissions given
+func NewUserService(adminPermissions ...string) (service.IUserService, *UserServiceRepos) {
+	repos := &UserServiceRepos{
+		Users:         NewUserRepository(),
+		Tokens:        NewTokenRepository(),
+		Roles:         NewRoleRepository(adminPermissions...),
+		RateLimits:    NewRateLimitRepository(),
+		LoginAttempts: NewLoginAttemptRepository(),
+		Mfa:           NewMfaRepository(),
+		Sessions:      NewSessionRepository(),
+		Identities:    NewUserIdentityRepository(),
+		Passkeys:      NewPasskeyRepository(),
+		AuditLogs:     NewAuditLogRepository(),
+	}
+	userService := service.NewUserService(repos.Users, repos.Tokens, repos.Roles, repos.RateLimits,
+		repos.LoginAttempts, repos.Mfa, repos.Sessions, repos.Identities, repos.Passkeys, repos.AuditLogs)
+	return userService, repos
+}
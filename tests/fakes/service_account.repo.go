@@ -0,0 +1,157 @@
+package fakes
+
+import (
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"sync"
+	"time"
+)
+
+// ServiceAccountRepository keeps service accounts and their keys
+type ServiceAccountRepository struct {
+	mu       sync.Mutex
+	accounts map[uint]*model.ServiceAccount
+	keys     map[string]*model.ServiceAccountKey
+}
+
+func NewServiceAccountRepository() *ServiceAccountRepository {
+	return &ServiceAccountRepository{
+		accounts: make(map[uint]*model.ServiceAccount),
+		keys:     make(map[string]*model.ServiceAccountKey),
+	}
+}
+
+func (r *ServiceAccountRepository) GetListServiceAccount() ([]model.ServiceAccount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	accounts := make([]model.ServiceAccount, 0, len(r.accounts))
+	for _, account := range r.accounts {
+		accounts = append(accounts, *account)
+	}
+	return accounts, nil
+}
+
+func (r *ServiceAccountRepository) GetServiceAccountByID(id uint) *model.ServiceAccount {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if account, ok := r.accounts[id]; ok {
+		copied := *account
+		return &copied
+	}
+	return nil
+}
+
+func (r *ServiceAccountRepository) GetServiceAccountByName(name string) *model.ServiceAccount {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, account := range r.accounts {
+		if account.Name == name {
+			copied := *account
+			return &copied
+		}
+	}
+	return nil
+}
+
+func (r *ServiceAccountRepository) CreateServiceAccount(account *model.ServiceAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	account.ID = uint(len(r.accounts) + 1)
+	account.CreatedAt = time.Now()
+	copied := *account
+	r.accounts[account.ID] = &copied
+	return nil
+}
+
+func (r *ServiceAccountRepository) UpdateServiceAccount(account *model.ServiceAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *account
+	r.accounts[account.ID] = &copied
+	return nil
+}
+
+// CreateServiceAccountKey stores the key as given, its Secret already encrypted, and
+// returns its KeyID
+func (r *ServiceAccountRepository) CreateServiceAccountKey(key *model.ServiceAccountKey) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if key.KeyID == "" {
+		key.KeyID = "kado_sa_" + randomID()
+	}
+	key.ID = uint(len(r.keys) + 1)
+	copied := *key
+	r.keys[key.KeyID] = &copied
+	return key.KeyID, nil
+}
+
+func (r *ServiceAccountRepository) GetServiceAccountKey(keyID string) *model.ServiceAccountKey {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, ok := r.keys[keyID]
+	if !ok {
+		return nil
+	}
+	copied := *key
+	if account, ok := r.accounts[key.ServiceAccountID]; ok {
+		accountCopy := *account
+		copied.ServiceAccount = &accountCopy
+	}
+	return &copied
+}
+
+func (r *ServiceAccountRepository) DeleteServiceAccountKey(serviceAccountID uint, keyID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, ok := r.keys[keyID]
+	if !ok || key.ServiceAccountID != serviceAccountID {
+		return false, nil
+	}
+	delete(r.keys, keyID)
+	return true, nil
+}
+
+func (r *ServiceAccountRepository) TouchServiceAccountKey(id uint) error {
+	return nil
+}
+
+// SignedRequestRepository remembers the signatures seen, like SETNX does
+type SignedRequestRepository struct {
+	mu    sync.Mutex
+	seen  map[string]bool
+	usage map[string]int64
+}
+
+func NewSignedRequestRepository() *SignedRequestRepository {
+	return &SignedRequestRepository{seen: make(map[string]bool), usage: make(map[string]int64)}
+}
+
+func (r *SignedRequestRepository) MarkSignatureUsed(keyID string, signature string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seen[keyID+":"+signature] {
+		return false, nil
+	}
+	r.seen[keyID+":"+signature] = true
+	return true, nil
+}
+
+func (r *SignedRequestRepository) RecordKeyUsage(keyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.usage[keyID]++
+	return nil
+}
+
+func (r *SignedRequestRepository) GetKeyUsage(keyID string) (*repo.KeyUsage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &repo.KeyUsage{Total: r.usage[keyID], Today: r.usage[keyID]}, nil
+}
+
+func (r *SignedRequestRepository) DeleteKeyUsage(keyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.usage, keyID)
+	return nil
+}
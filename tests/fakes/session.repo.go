@@ -0,0 +1,120 @@
+package fakes
+
+import (
+	"base_go_be/internal/repo"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SessionRepository keeps sessions in memory. Touching a session that was deleted
+// doesn't bring it back, as with Redis SET XX
+type SessionRepository struct {
+	mu       sync.Mutex
+	sessions map[string]repo.Session
+	// impersonations not ended yet and when they expire
+	impersonations map[string]repo.Session
+	expiries       map[string]time.Time
+	// BeforeTouch runs when TouchSession is called, before it writes, to simulate a
+	// concurrent change
+	BeforeTouch func(session *repo.Session)
+}
+
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{
+		sessions:       make(map[string]repo.Session),
+		impersonations: make(map[string]repo.Session),
+		expiries:       make(map[string]time.Time),
+	}
+}
+
+func (r *SessionRepository) CreateSession(session *repo.Session, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	session.ID = randomID()
+	session.CreatedAt = now
+	session.LastSeenAt = now
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *SessionRepository) GetSession(sessionID string) (*repo.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) TouchSession(session *repo.Session, ip string, ttl time.Duration) error {
+	if r.BeforeTouch != nil {
+		r.BeforeTouch(session)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session.LastSeenAt = time.Now()
+	if ip != "" {
+		session.IP = ip
+	}
+	if _, ok := r.sessions[session.ID]; ok {
+		r.sessions[session.ID] = *session
+	}
+	return nil
+}
+
+func (r *SessionRepository) ListUserSessions(userID uint) ([]repo.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessions []repo.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	return sessions, nil
+}
+
+func (r *SessionRepository) DeleteSession(userID uint, sessionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if session, ok := r.sessions[sessionID]; ok && session.UserID == userID {
+		delete(r.sessions, sessionID)
+	}
+	return nil
+}
+
+func (r *SessionRepository) TrackImpersonation(session *repo.Session, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.impersonations[session.ID] = *session
+	r.expiries[session.ID] = expiresAt
+	return nil
+}
+
+func (r *SessionRepository) EndImpersonation(sessionID string) (*repo.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.impersonations[sessionID]
+	if !ok {
+		return nil, nil
+	}
+	delete(r.impersonations, sessionID)
+	delete(r.expiries, sessionID)
+	return &session, nil
+}
+
+func (r *SessionRepository) ExpiredImpersonations(before time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessionIDs []string
+	for sessionID, expiresAt := range r.expiries {
+		if !expiresAt.After(before) {
+			sessionIDs = append(sessionIDs, sessionID)
+		}
+	}
+	return sessionIDs, nil
+}
@@ -0,0 +1,147 @@
+package fakes
+
+import (
+	"base_go_be/internal/repo"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TokenRepository mirrors the Redis token store: refresh token families rotated
+// compare-and-swap, revocations and one-time tokens
+type TokenRepository struct {
+	mu            sync.Mutex
+	familyCurrent map[string]string // family ID -> current refresh token
+	tokenFamily   map[string]string // refresh token -> family ID
+	revoked       map[string]bool   // jti
+	revokedUsers  map[uint]int64    // user ID -> unix time before which tokens are revoked
+	tickets       map[string]string
+	oneTime       map[string]repo.OneTimeToken // purpose + token
+	oneTimeUser   map[string]string            // purpose + user ID -> outstanding token
+}
+
+func NewTokenRepository() *TokenRepository {
+	return &TokenRepository{
+		familyCurrent: make(map[string]string),
+		tokenFamily:   make(map[string]string),
+		revoked:       make(map[string]bool),
+		revokedUsers:  make(map[uint]int64),
+		tickets:       make(map[string]string),
+		oneTime:       make(map[string]repo.OneTimeToken),
+		oneTimeUser:   make(map[string]string),
+	}
+}
+
+func (r *TokenRepository) CreateRefreshFamily(familyID string, refreshToken string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.familyCurrent[familyID] = refreshToken
+	r.tokenFamily[refreshToken] = familyID
+	return nil
+}
+
+func (r *TokenRepository) GetRefreshFamilyID(refreshToken string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.tokenFamily[refreshToken], nil
+}
+
+func (r *TokenRepository) RotateRefreshToken(familyID string, oldToken string, newToken string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if current, ok := r.familyCurrent[familyID]; !ok || current != oldToken {
+		return false, nil
+	}
+	r.familyCurrent[familyID] = newToken
+	r.tokenFamily[newToken] = familyID
+	return true, nil
+}
+
+func (r *TokenRepository) RevokeRefreshFamily(familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.familyCurrent, familyID)
+	return nil
+}
+
+// FamilyRevoked tells whether the family has no current refresh token anymore
+func (r *TokenRepository) FamilyRevoked(familyID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.familyCurrent[familyID]
+	return !ok
+}
+
+func (r *TokenRepository) RevokeToken(tokenID string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revoked[tokenID] = true
+	return nil
+}
+
+func (r *TokenRepository) ConsumeToken(tokenID string, expiresAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.revoked[tokenID] || time.Until(expiresAt) <= 0 {
+		return false, nil
+	}
+	r.revoked[tokenID] = true
+	return true, nil
+}
+
+func (r *TokenRepository) RevokeUserTokens(userID uint, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revokedUsers[userID] = time.Now().Unix()
+	return nil
+}
+
+func (r *TokenRepository) IsTokenRevoked(tokenID string, userID uint, issuedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.revoked[tokenID] {
+		return true, nil
+	}
+	revokedAt, ok := r.revokedUsers[userID]
+	return ok && issuedAt.Unix() <= revokedAt, nil
+}
+
+func (r *TokenRepository) CreateWebSocketTicket(accessToken string, ttl time.Duration) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ticket := randomID()
+	r.tickets[ticket] = accessToken
+	return ticket, nil
+}
+
+func (r *TokenRepository) ConsumeWebSocketTicket(ticket string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	accessToken := r.tickets[ticket]
+	delete(r.tickets, ticket)
+	return accessToken, nil
+}
+
+func (r *TokenRepository) CreateOneTimeToken(purpose string, token repo.OneTimeToken, ttl time.Duration) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	userKey := purpose + ":user:" + strconv.FormatUint(uint64(token.UserID), 10)
+	if previous, ok := r.oneTimeUser[userKey]; ok {
+		delete(r.oneTime, purpose+":"+previous)
+	}
+	plain := randomID()
+	r.oneTime[purpose+":"+plain] = token
+	r.oneTimeUser[userKey] = plain
+	return plain, nil
+}
+
+func (r *TokenRepository) ConsumeOneTimeToken(purpose string, token string) (*repo.OneTimeToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	oneTimeToken, ok := r.oneTime[purpose+":"+token]
+	if !ok {
+		return nil, nil
+	}
+	delete(r.oneTime, purpose+":"+token)
+	return &oneTimeToken, nil
+}
@@ -0,0 +1,256 @@
+package fakes
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrDuplicate is returned where the database would report a unique violation
+var ErrDuplicate = errors.New("duplicate key value violates unique constraint")
+
+// UserRepository keeps users in memory, enforcing the unique email and username of
+// live users like the partial unique indexes do
+type UserRepository struct {
+	mu     sync.Mutex
+	users  map[uint]*model.User
+	nextID uint
+	// PurgeErrors makes PurgeUser fail for the user IDs listed
+	PurgeErrors map[uint]error
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{users: make(map[uint]*model.User), PurgeErrors: make(map[uint]error)}
+}
+
+// Add stores the user as is and returns its ID, for test setup
+func (r *UserRepository) Add(user *model.User) uint {
+	if _, err := r.CreateUser(user); err != nil {
+		panic(err)
+	}
+	return user.ID
+}
+
+// Get returns the stored user, deleted or not
+func (r *UserRepository) Get(id uint) *model.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok {
+		copied := *user
+		return &copied
+	}
+	return nil
+}
+
+func (r *UserRepository) find(match func(*model.User) bool, deleted bool) *model.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.DeletedAt.Valid == deleted && match(user) {
+			copied := *user
+			return &copied
+		}
+	}
+	return nil
+}
+
+func (r *UserRepository) GetUserByEmail(email string) *model.User {
+	return r.find(func(u *model.User) bool { return u.Email == email }, false)
+}
+
+func (r *UserRepository) GetUserByID(id uint) *model.User {
+	return r.find(func(u *model.User) bool { return u.ID == id }, false)
+}
+
+func (r *UserRepository) GetUserByPublicID(publicID string) *model.User {
+	return r.find(func(u *model.User) bool { return u.PublicID == publicID }, false)
+}
+
+func (r *UserRepository) GetUserByUsername(username string) *model.User {
+	return r.find(func(u *model.User) bool { return u.Username == username }, false)
+}
+
+func (r *UserRepository) GetListUser(req dto.UserListRequestDto) ([]*model.User, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*model.User
+	for _, user := range r.users {
+		if !user.DeletedAt.Valid && strings.Contains(user.Email, req.Email) {
+			copied := *user
+			users = append(users, &copied)
+		}
+	}
+	slices.SortFunc(users, func(a, b *model.User) int { return int(a.ID) - int(b.ID) })
+	return users, int64(len(users)), nil
+}
+
+func (r *UserRepository) CreateUser(user *model.User) (uint, error) {
+	if err := user.BeforeCreate(nil); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.taken(0, user.Email, user.Username) {
+		return 0, ErrDuplicate
+	}
+	// Column defaults
+	if !user.IsActive {
+		user.IsActive = true
+	}
+	if user.Role == "" {
+		user.Role = model.RoleUser
+	}
+	r.nextID++
+	user.ID = r.nextID
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	copied := *user
+	r.users[user.ID] = &copied
+	return user.ID, nil
+}
+
+// taken tells whether a live user other than id has the email or username
+func (r *UserRepository) taken(id uint, email string, username string) bool {
+	for _, other := range r.users {
+		if other.ID != id && !other.DeletedAt.Valid && (other.Email == email || other.Username == username) {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateUser saves the non-zero fields, as GORM's Updates with a struct does
+func (r *UserRepository) UpdateUser(id uint, user *model.User) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.users[id]
+	if !ok || existing.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	updated := *existing
+	if user.Username != "" {
+		updated.Username = user.Username
+	}
+	if user.Email != "" {
+		updated.Email = user.Email
+	}
+	if user.Password != "" {
+		updated.Password = user.Password
+	}
+	if user.Role != "" {
+		updated.Role = user.Role
+	}
+	if user.EmailVerifiedAt != nil {
+		updated.EmailVerifiedAt = user.EmailVerifiedAt
+	}
+	if user.IsActive {
+		updated.IsActive = true
+	}
+	if r.taken(id, updated.Email, updated.Username) {
+		return nil, ErrDuplicate
+	}
+	updated.UpdatedAt = time.Now()
+	r.users[id] = &updated
+	copied := updated
+	return &copied, nil
+}
+
+func (r *UserRepository) SetUserActive(id uint, active bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok {
+		user.IsActive = active
+	}
+	return nil
+}
+
+func (r *UserRepository) UpdateUserFields(id uint, user *model.User, fields ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.users[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for _, field := range fields {
+		switch field {
+		case "fullname":
+			existing.Fullname = user.Fullname
+		case "bio":
+			existing.Bio = user.Bio
+		case "avatar_key":
+			existing.AvatarKey = user.AvatarKey
+		case "locale":
+			existing.Locale = user.Locale
+		case "timezone":
+			existing.Timezone = user.Timezone
+		case "notification_prefs":
+			existing.NotificationPrefs = user.NotificationPrefs
+		}
+	}
+	existing.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *UserRepository) DeleteUser(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok {
+		user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	}
+	return nil
+}
+
+func (r *UserRepository) GetDeletedUserByPublicID(publicID string) *model.User {
+	return r.find(func(u *model.User) bool { return u.PublicID == publicID }, true)
+}
+
+func (r *UserRepository) RestoreUser(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if r.taken(id, user.Email, user.Username) {
+		return ErrDuplicate
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+func (r *UserRepository) GetUsersDeletedBefore(before time.Time) ([]*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*model.User
+	for _, user := range r.users {
+		if user.DeletedAt.Valid && user.DeletedAt.Time.Before(before) {
+			copied := *user
+			users = append(users, &copied)
+		}
+	}
+	slices.SortFunc(users, func(a, b *model.User) int { return int(a.ID) - int(b.ID) })
+	return users, nil
+}
+
+// SetDeletedAt backdates the deletion of a user, for purge tests
+func (r *UserRepository) SetDeletedAt(id uint, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[id].DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+}
+
+func (r *UserRepository) PurgeUser(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.PurgeErrors[id]; err != nil {
+		return err
+	}
+	delete(r.users, id)
+	return nil
+}
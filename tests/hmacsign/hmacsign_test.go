@@ -0,0 +1,35 @@
+package hmacsign
+
+import (
+	"base_go_be/pkg/hmacsign"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const secret = "test-secret"
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"name":"Pen"}`)
+	signature := hmacsign.Sign(secret, "POST", "/v1/product/create", "1700000000", body)
+
+	assert.True(t, hmacsign.Verify(secret, signature, "POST", "/v1/product/create", "1700000000", body))
+	assert.Len(t, signature, 64)
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	body := []byte(`{"name":"Pen"}`)
+	signature := hmacsign.Sign(secret, "POST", "/v1/product/create", "1700000000", body)
+
+	assert.False(t, hmacsign.Verify("other-secret", signature, "POST", "/v1/product/create", "1700000000", body))
+	assert.False(t, hmacsign.Verify(secret, signature, "PUT", "/v1/product/create", "1700000000", body))
+	assert.False(t, hmacsign.Verify(secret, signature, "POST", "/v1/product/list", "1700000000", body))
+	assert.False(t, hmacsign.Verify(secret, signature, "POST", "/v1/product/create", "1700000001", body))
+	assert.False(t, hmacsign.Verify(secret, signature, "POST", "/v1/product/create", "1700000000", []byte(`{"name":"Pan"}`)))
+}
+
+func TestStringToSign(t *testing.T) {
+	// sha256 of an empty body
+	want := "GET\n/v1/product/list?limit=10\n1700000000\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	assert.Equal(t, want, hmacsign.StringToSign("get", "/v1/product/list?limit=10", "1700000000", nil))
+}
@@ -0,0 +1,74 @@
+package imaging
+
+import (
+	"base_go_be/pkg/imaging"
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, img image.Image) *bytes.Buffer {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return &buf
+}
+
+func TestSquareCropsCenter(t *testing.T) {
+	// Red bands on the left and right are cropped away, leaving the blue center
+	src := image.NewNRGBA(image.Rect(0, 0, 300, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 300; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= 100 && x < 200 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			src.SetNRGBA(x, y, c)
+		}
+	}
+
+	dst := imaging.Square(src, 32)
+	assert.Equal(t, image.Rect(0, 0, 32, 32), dst.Bounds())
+	for _, p := range []image.Point{{0, 0}, {31, 31}, {16, 16}} {
+		assert.Equal(t, color.NRGBA{B: 255, A: 255}, dst.NRGBAAt(p.X, p.Y))
+	}
+}
+
+func TestSquareAveragesPixels(t *testing.T) {
+	// A black and white checkerboard scales down to grey
+	src := image.NewGray(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if (x+y)%2 == 0 {
+				src.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+
+	got := imaging.Square(src, 8).NRGBAAt(4, 4)
+	assert.InDelta(t, 127, int(got.R), 1)
+	assert.Equal(t, uint8(255), got.A)
+}
+
+func TestDecode(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 20, 10))
+	img, err := imaging.Decode(encodePNG(t, src), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 20, 10), img.Bounds())
+}
+
+func TestDecodeRejectsNonImages(t *testing.T) {
+	_, err := imaging.Decode(strings.NewReader("<svg></svg>"), 1000)
+	assert.ErrorIs(t, err, imaging.ErrUnsupportedImage)
+}
+
+func TestDecodeRejectsTooManyPixels(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 40, 40))
+	_, err := imaging.Decode(encodePNG(t, src), 1000)
+	assert.ErrorIs(t, err, imaging.ErrImageTooLarge)
+}
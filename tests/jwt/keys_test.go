@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"base_go_be/pkg/config"
+	"base_go_be/pkg/jwt"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ed25519Key(t *testing.T, kid string) *jwt.Key {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	key, err := jwt.ParseKeyPEM(kid, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+	return key
+}
+
+func TestSecretKeyOnlyVerifiesOnceKeysConfigured(t *testing.T) {
+	config.JWT.SecretKey = "old-secret"
+	jwt.SetKeySet(nil)
+	t.Cleanup(func() { jwt.SetKeySet(nil) })
+
+	old, err := jwt.GenerateToken("user", "user@example.com", "user", "session", jwt.TokenTypeAccess, time.Hour)
+	require.NoError(t, err)
+
+	keySet, err := jwt.NewKeySet("ed-1", ed25519Key(t, "ed-1"), jwt.NewHMACVerifyKey(jwt.DefaultKeyID, []byte("old-secret")))
+	require.NoError(t, err)
+	jwt.SetKeySet(keySet)
+
+	_, err = jwt.ValidateToken(old, jwt.TokenTypeAccess)
+	assert.NoError(t, err)
+
+	fresh, err := jwt.GenerateToken("user", "user@example.com", "user", "session", jwt.TokenTypeAccess, time.Hour)
+	require.NoError(t, err)
+	token, _, err := new(gojwt.Parser).ParseUnverified(fresh, &gojwt.RegisteredClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "ed-1", token.Header["kid"])
+	assert.Equal(t, gojwt.SigningMethodEdDSA.Alg(), token.Method.Alg())
+}
+
+func TestHMACVerifyKeyCannotSign(t *testing.T) {
+	key := jwt.NewHMACVerifyKey(jwt.DefaultKeyID, []byte("old-secret"))
+	assert.False(t, key.CanSign())
+
+	_, err := jwt.NewKeySet(jwt.DefaultKeyID, key)
+	assert.Error(t, err)
+}
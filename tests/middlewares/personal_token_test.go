@@ -0,0 +1,95 @@
+package middlewares
+
+import (
+	"base_go_be/internal/middlewares"
+	"base_go_be/internal/model"
+	"base_go_be/tests/fakes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenServer serves a route per product permission behind the auth middleware, and
+// an account route requiring a login session. The user's role reads and writes products
+type tokenServer struct {
+	*gin.Engine
+	userID uint
+	tokens *fakes.PersonalTokenRepository
+}
+
+func newTokenServer(t *testing.T) *tokenServer {
+	fakes.Setup()
+	gin.SetMode(gin.TestMode)
+
+	users := fakes.NewUserRepository()
+	userID := users.Add(&model.User{Email: "alice@example.com", Username: "alice", Password: "x"})
+	roles := fakes.NewRoleRepository()
+	roles.SetRole(model.RoleUser, model.PermissionProductRead, model.PermissionProductWrite)
+	tokens := fakes.NewPersonalTokenRepository()
+	signed := func(c *gin.Context) { c.AbortWithStatus(http.StatusTeapot) }
+
+	r := gin.New()
+	r.Use(middlewares.NewAuthMiddleware(fakes.NewTokenRepository(), roles, users, fakes.NewSessionRepository(), tokens, signed))
+	ok := func(c *gin.Context) { c.String(200, "ok") }
+	r.GET("/read", middlewares.RequirePermission(model.PermissionProductRead), ok)
+	r.GET("/write", middlewares.RequirePermission(model.PermissionProductWrite), ok)
+	r.GET("/delete", middlewares.RequirePermission(model.PermissionProductDelete), ok)
+	r.GET("/account", middlewares.RequireUserSession(), ok)
+	return &tokenServer{Engine: r, userID: userID, tokens: tokens}
+}
+
+func (s *tokenServer) newToken(t *testing.T, expiresAt time.Time, scopes ...string) string {
+	plain, err := s.tokens.CreatePersonalToken(&model.PersonalAccessToken{
+		UserID: s.userID, Name: "script", Scopes: scopes, ExpiresAt: expiresAt,
+	})
+	require.NoError(t, err)
+	return plain
+}
+
+func (s *tokenServer) get(path string, token string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestPersonalTokenLimitedToScopes(t *testing.T) {
+	s := newTokenServer(t)
+	token := s.newToken(t, time.Now().Add(time.Hour), model.PermissionProductRead)
+
+	assert.Equal(t, 200, s.get("/read", token))
+	assert.Equal(t, 403, s.get("/write", token), "in the role but not in the scopes")
+}
+
+func TestPersonalTokenLimitedToRole(t *testing.T) {
+	s := newTokenServer(t)
+	token := s.newToken(t, time.Now().Add(time.Hour), model.PermissionProductRead, model.PermissionProductDelete)
+
+	assert.Equal(t, 403, s.get("/delete", token), "in the scopes but no longer in the role")
+}
+
+func TestPersonalTokenRefusedOnSessionRoutes(t *testing.T) {
+	s := newTokenServer(t)
+	token := s.newToken(t, time.Now().Add(time.Hour), model.PermissionProductRead)
+
+	assert.Equal(t, 403, s.get("/account", token))
+}
+
+func TestPersonalTokenExpired(t *testing.T) {
+	s := newTokenServer(t)
+	token := s.newToken(t, time.Now().Add(-time.Minute), model.PermissionProductRead)
+
+	assert.Equal(t, 401, s.get("/read", token))
+}
+
+func TestPersonalTokenUnknown(t *testing.T) {
+	s := newTokenServer(t)
+
+	assert.Equal(t, 401, s.get("/read", model.PersonalTokenPrefix+"unknown"))
+}
@@ -0,0 +1,135 @@
+package middlewares
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/middlewares"
+	"base_go_be/internal/model"
+	"base_go_be/pkg/hmacsign"
+	"base_go_be/pkg/response"
+	"base_go_be/pkg/secretbox"
+	"base_go_be/tests/fakes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const apiSecret = "service-account-secret"
+
+// signedServer serves GET /ping behind the service account middleware, for a key
+// owned by a live user
+func signedServer(t *testing.T) (*gin.Engine, string) {
+	r, keyID, _, _ := ownedSignedServer(t)
+	return r, keyID
+}
+
+// ownedSignedServer is signedServer also returning the users and the key's owner
+func ownedSignedServer(t *testing.T) (*gin.Engine, string, *fakes.UserRepository, uint) {
+	fakes.Setup()
+	gin.SetMode(gin.TestMode)
+
+	users := fakes.NewUserRepository()
+	ownerID := users.Add(&model.User{Email: "owner@example.com", Username: "owner", Password: "x"})
+	accounts := fakes.NewServiceAccountRepository()
+	account := &model.ServiceAccount{Name: "ci", Role: model.RoleUser, OwnerUserID: ownerID, IsActive: true}
+	require.NoError(t, accounts.CreateServiceAccount(account))
+	encrypted, err := secretbox.Encrypt(global.Config.Security.EncryptionKey, apiSecret)
+	require.NoError(t, err)
+	keyID, err := accounts.CreateServiceAccountKey(&model.ServiceAccountKey{ServiceAccountID: account.ID, Secret: encrypted})
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(middlewares.NewServiceAccountMiddleware(accounts, fakes.NewSignedRequestRepository(), fakes.NewRoleRepository(), users))
+	r.GET("/ping", func(c *gin.Context) { c.String(200, "pong") })
+	return r, keyID, users, ownerID
+}
+
+func signedRequest(keyID string, signature string, timestamp string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(hmacsign.HeaderKeyID, keyID)
+	req.Header.Set(hmacsign.HeaderTimestamp, timestamp)
+	req.Header.Set(hmacsign.HeaderSignature, signature)
+	return req
+}
+
+func serve(r *gin.Engine, req *http.Request) (int, int) {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var body response.Response
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	return w.Code, body.Code
+}
+
+func TestSignedRequestAccepted(t *testing.T) {
+	r, keyID := signedServer(t)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := hmacsign.Sign(apiSecret, http.MethodGet, "/ping", timestamp, nil)
+
+	status, _ := serve(r, signedRequest(keyID, signature, timestamp))
+	assert.Equal(t, 200, status)
+}
+
+func TestSignedRequestReplayRejected(t *testing.T) {
+	r, keyID := signedServer(t)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := hmacsign.Sign(apiSecret, http.MethodGet, "/ping", timestamp, nil)
+
+	status, _ := serve(r, signedRequest(keyID, signature, timestamp))
+	require.Equal(t, 200, status)
+	status, code := serve(r, signedRequest(keyID, signature, timestamp))
+	assert.Equal(t, 401, status)
+	assert.Equal(t, response.ErrCodeRequestReplayed, code)
+}
+
+func TestSignedRequestReplayWithOtherCaseRejected(t *testing.T) {
+	r, keyID := signedServer(t)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := hmacsign.Sign(apiSecret, http.MethodGet, "/ping", timestamp, nil)
+	require.NotEqual(t, signature, strings.ToUpper(signature), "signature has no hex letter")
+
+	status, _ := serve(r, signedRequest(keyID, signature, timestamp))
+	require.Equal(t, 200, status)
+
+	// Same signature with the case of its letters changed
+	status, code := serve(r, signedRequest(keyID, strings.ToUpper(signature), timestamp))
+	assert.Equal(t, 401, status)
+	assert.Equal(t, response.ErrCodeRequestReplayed, code)
+}
+
+func TestSignedRequestTamperedRejected(t *testing.T) {
+	r, keyID := signedServer(t)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := hmacsign.Sign(apiSecret, http.MethodGet, "/other", timestamp, nil)
+
+	status, code := serve(r, signedRequest(keyID, signature, timestamp))
+	assert.Equal(t, 401, status)
+	assert.Equal(t, response.ErrCodeInvalidSignature, code)
+}
+
+func TestSignedRequestExpiredRejected(t *testing.T) {
+	r, keyID := signedServer(t)
+	timestamp := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	signature := hmacsign.Sign(apiSecret, http.MethodGet, "/ping", timestamp, nil)
+
+	status, code := serve(r, signedRequest(keyID, signature, timestamp))
+	assert.Equal(t, 401, status)
+	assert.Equal(t, response.ErrCodeRequestExpired, code)
+}
+
+func TestSignedRequestOfDeactivatedOwnerRejected(t *testing.T) {
+	r, keyID, users, ownerID := ownedSignedServer(t)
+	require.NoError(t, users.SetUserActive(ownerID, false))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := hmacsign.Sign(apiSecret, http.MethodGet, "/ping", timestamp, nil)
+
+	status, code := serve(r, signedRequest(keyID, signature, timestamp))
+	assert.Equal(t, 403, status)
+	assert.Equal(t, response.ErrCodeAccountInactive, code)
+}
@@ -0,0 +1,150 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	mockKeyID    = "mock-key"
+	mockClientID = "kado-client"
+)
+
+// mockUser is the account logged in at the mock provider
+type mockUser struct {
+	Subject       string
+	Email         string
+	EmailVerified any
+	Name          string
+}
+
+type pendingCode struct {
+	challenge string
+	nonce     string
+	user      mockUser
+}
+
+// mockIdP is a minimal OpenID Connect provider: discovery, JWKS and a token endpoint
+// checking PKCE. Authorize stands in for the user logging in at its login page
+type mockIdP struct {
+	*httptest.Server
+	key        *rsa.PrivateKey
+	signingKey *rsa.PrivateKey // signs ID tokens, key unless a test swaps it
+	mu         sync.Mutex
+	codes      map[string]pendingCode
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key, signingKey: key, codes: map[string]pendingCode{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/token", idp.token)
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+// Authorize logs the user in for an authorization request and returns the code the
+// provider would redirect back with
+func (idp *mockIdP) Authorize(challenge string, nonce string, user mockUser) string {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	b := make([]byte, 16)
+	rand.Read(b)
+	code := base64.RawURLEncoding.EncodeToString(b)
+	idp.codes[code] = pendingCode{challenge: challenge, nonce: nonce, user: user}
+	return code
+}
+
+func (idp *mockIdP) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]any{
+		"issuer":                                idp.URL,
+		"authorization_endpoint":                idp.URL + "/authorize",
+		"token_endpoint":                        idp.URL + "/token",
+		"jwks_uri":                              idp.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (idp *mockIdP) jwks(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": mockKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+		return
+	}
+
+	idp.mu.Lock()
+	pending, ok := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(verifierHash[:]) != pending.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            idp.URL,
+		"aud":            mockClientID,
+		"sub":            pending.user.Subject,
+		"email":          pending.user.Email,
+		"email_verified": pending.user.EmailVerified,
+		"name":           pending.user.Name,
+		"nonce":          pending.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+	})
+	idToken.Header["kid"] = mockKeyID
+	signed, err := idToken.SignedString(idp.signingKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"access_token": "mock-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
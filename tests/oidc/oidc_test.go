@@ -0,0 +1,138 @@
+package oidc
+
+import (
+	"base_go_be/pkg/oidc"
+	"base_go_be/pkg/setting"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var alice = mockUser{Subject: "alice-sub", Email: "alice@example.com", EmailVerified: true, Name: "Alice"}
+
+func newProvider(idp *mockIdP, clientID string) *oidc.Provider {
+	return oidc.NewProvider(setting.OIDCProviderSetting{
+		Name:         "mock",
+		Issuer:       idp.URL,
+		ClientID:     clientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:3000/auth/oidc/mock/callback",
+	})
+}
+
+// startLogin returns the PKCE challenge and nonce the provider receives
+func startLogin(t *testing.T, provider *oidc.Provider, state string, nonce string, verifier string) url.Values {
+	authURL, err := provider.AuthCodeURL(context.Background(), state, nonce, verifier)
+	require.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	return parsed.Query()
+}
+
+func TestLogin(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newProvider(idp, mockClientID)
+	verifier := oidc.GenerateVerifier()
+
+	query := startLogin(t, provider, "state-1", "nonce-1", verifier)
+	assert.Equal(t, "state-1", query.Get("state"))
+	assert.Equal(t, "nonce-1", query.Get("nonce"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, mockClientID, query.Get("client_id"))
+	assert.Contains(t, query.Get("scope"), "openid")
+
+	code := idp.Authorize(query.Get("code_challenge"), query.Get("nonce"), alice)
+	identity, err := provider.Exchange(context.Background(), code, verifier, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, &oidc.Identity{Subject: "alice-sub", Email: "alice@example.com", EmailVerified: true, Name: "Alice"}, identity)
+}
+
+func TestEmailVerifiedAsString(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newProvider(idp, mockClientID)
+	verifier := oidc.GenerateVerifier()
+
+	user := alice
+	user.EmailVerified = "true"
+	query := startLogin(t, provider, "state", "nonce", verifier)
+	code := idp.Authorize(query.Get("code_challenge"), "nonce", user)
+	identity, err := provider.Exchange(context.Background(), code, verifier, "nonce")
+	require.NoError(t, err)
+	assert.True(t, identity.EmailVerified)
+}
+
+func TestUnverifiedEmail(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newProvider(idp, mockClientID)
+	verifier := oidc.GenerateVerifier()
+
+	user := alice
+	user.EmailVerified = false
+	query := startLogin(t, provider, "state", "nonce", verifier)
+	code := idp.Authorize(query.Get("code_challenge"), "nonce", user)
+	identity, err := provider.Exchange(context.Background(), code, verifier, "nonce")
+	require.NoError(t, err)
+	assert.False(t, identity.EmailVerified)
+}
+
+func TestWrongVerifierRejected(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newProvider(idp, mockClientID)
+
+	query := startLogin(t, provider, "state", "nonce", oidc.GenerateVerifier())
+	code := idp.Authorize(query.Get("code_challenge"), "nonce", alice)
+	_, err := provider.Exchange(context.Background(), code, oidc.GenerateVerifier(), "nonce")
+	assert.Error(t, err)
+}
+
+func TestNonceMismatchRejected(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newProvider(idp, mockClientID)
+	verifier := oidc.GenerateVerifier()
+
+	query := startLogin(t, provider, "state", "nonce", verifier)
+	code := idp.Authorize(query.Get("code_challenge"), "nonce", alice)
+	_, err := provider.Exchange(context.Background(), code, verifier, "another-nonce")
+	assert.ErrorIs(t, err, oidc.ErrNonceMismatch)
+}
+
+func TestWrongAudienceRejected(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newProvider(idp, "another-client")
+	verifier := oidc.GenerateVerifier()
+
+	query := startLogin(t, provider, "state", "nonce", verifier)
+	code := idp.Authorize(query.Get("code_challenge"), "nonce", alice)
+	_, err := provider.Exchange(context.Background(), code, verifier, "nonce")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+}
+
+func TestForgedSignatureRejected(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newProvider(idp, mockClientID)
+	verifier := oidc.GenerateVerifier()
+
+	forger, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idp.signingKey = forger
+
+	query := startLogin(t, provider, "state", "nonce", verifier)
+	code := idp.Authorize(query.Get("code_challenge"), "nonce", alice)
+	_, err = provider.Exchange(context.Background(), code, verifier, "nonce")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+}
+
+func TestProviderRegistry(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newProvider(idp, mockClientID)
+	oidc.SetProviders(provider)
+	t.Cleanup(func() { oidc.SetProviders() })
+
+	assert.Same(t, provider, oidc.GetProvider("mock"))
+	assert.Nil(t, oidc.GetProvider("unknown"))
+}
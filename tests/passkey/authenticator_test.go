@@ -0,0 +1,139 @@
+package passkey
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttestedData = 0x40
+)
+
+// softAuthenticator is a software passkey authenticator holding one ES256 credential
+// with a sign counter, answering like a browser's PublicKeyCredential JSON
+type softAuthenticator struct {
+	origin       string
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	counter      uint32
+	userVerified bool
+}
+
+func newSoftAuthenticator(t *testing.T, origin string) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentialID := make([]byte, 16)
+	rand.Read(credentialID)
+	return &softAuthenticator{origin: origin, key: key, credentialID: credentialID, userVerified: true}
+}
+
+// Create answers navigator.credentials.create() with a "none" attestation
+func (a *softAuthenticator) Create(t *testing.T, options *protocol.CredentialCreation) []byte {
+	a.userHandle = options.Response.User.ID.(protocol.URLEncodedBase64)
+	clientData := a.clientData(t, "webauthn.create", options.Response.Challenge)
+
+	cosePublicKey, err := cbor.Marshal(map[int]any{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: a.key.PublicKey.X.FillBytes(make([]byte, 32)),
+		-3: a.key.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authData := a.authData(options.Response.RelyingParty.ID, flagAttestedData)
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialID)))
+	authData = append(authData, a.credentialID...)
+	authData = append(authData, cosePublicKey...)
+
+	attestationObject, err := cbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a.credential(t, map[string]string{
+		"clientDataJSON":    b64(clientData),
+		"attestationObject": b64(attestationObject),
+	})
+}
+
+// Get answers navigator.credentials.get(), signing with the next counter value
+func (a *softAuthenticator) Get(t *testing.T, options *protocol.CredentialAssertion) []byte {
+	a.counter++
+	clientData := a.clientData(t, "webauthn.get", options.Response.Challenge)
+	authData := a.authData(options.Response.RelyingPartyID, 0)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a.credential(t, map[string]string{
+		"clientDataJSON":    b64(clientData),
+		"authenticatorData": b64(authData),
+		"signature":         b64(signature),
+		"userHandle":        b64(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony string, challenge protocol.URLEncodedBase64) []byte {
+	clientData, err := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": b64(challenge),
+		"origin":    a.origin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clientData
+}
+
+func (a *softAuthenticator) authData(rpID string, flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	flags |= flagUserPresent
+	if a.userVerified {
+		flags |= flagUserVerified
+	}
+	authData := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(authData, a.counter)
+}
+
+func (a *softAuthenticator) credential(t *testing.T, response map[string]string) []byte {
+	body, err := json.Marshal(map[string]any{
+		"id":       b64(a.credentialID),
+		"rawId":    b64(a.credentialID),
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
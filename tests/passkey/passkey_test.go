@@ -0,0 +1,127 @@
+package passkey
+
+import (
+	"base_go_be/pkg/passkey"
+	"base_go_be/pkg/setting"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	origin   = "http://localhost:3000"
+	publicID = "01890a5d-ac96-774b-bcce-b302099a8057"
+)
+
+func newRelyingParty(t *testing.T) *passkey.RelyingParty {
+	rp, err := passkey.New(setting.WebAuthnSetting{
+		RPID:          "localhost",
+		RPDisplayName: "KADO",
+		RPOrigins:     []string{origin},
+	})
+	require.NoError(t, err)
+	return rp
+}
+
+// register runs the registration ceremony and returns the stored credential
+func register(t *testing.T, rp *passkey.RelyingParty, user *passkey.User, authenticator *softAuthenticator) *webauthn.Credential {
+	options, session, err := rp.BeginRegistration(user)
+	require.NoError(t, err)
+	credential, err := rp.FinishRegistration(user, *session, authenticator.Create(t, options))
+	require.NoError(t, err)
+	return credential
+}
+
+// login runs the login ceremony, users are looked up by the handle the passkey holds
+func login(t *testing.T, rp *passkey.RelyingParty, users map[string]*passkey.User, authenticator *softAuthenticator) (*passkey.User, *webauthn.Credential, error) {
+	options, session, err := rp.BeginLogin()
+	require.NoError(t, err)
+	return rp.FinishLogin(*session, authenticator.Get(t, options), func(handle []byte) (*passkey.User, error) {
+		return users[string(handle)], nil
+	})
+}
+
+func newUser() *passkey.User {
+	return &passkey.User{Handle: passkey.UserHandle(publicID), Name: "alice@example.com", DisplayName: "alice"}
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	rp := newRelyingParty(t)
+	authenticator := newSoftAuthenticator(t, origin)
+	user := newUser()
+
+	credential := register(t, rp, user, authenticator)
+	assert.Equal(t, authenticator.credentialID, credential.ID)
+	assert.True(t, credential.Flags.UserVerified)
+	assert.Equal(t, []byte(publicID), authenticator.userHandle)
+
+	user.Credentials = []webauthn.Credential{*credential}
+	loggedIn, used, err := login(t, rp, map[string]*passkey.User{publicID: user}, authenticator)
+	require.NoError(t, err)
+	assert.Same(t, user, loggedIn)
+	assert.Equal(t, uint32(1), used.Authenticator.SignCount)
+}
+
+func TestLoginRejectsWrongOrigin(t *testing.T) {
+	rp := newRelyingParty(t)
+	authenticator := newSoftAuthenticator(t, origin)
+	user := newUser()
+	user.Credentials = []webauthn.Credential{*register(t, rp, user, authenticator)}
+
+	authenticator.origin = "https://evil.example.com"
+	_, _, err := login(t, rp, map[string]*passkey.User{publicID: user}, authenticator)
+	assert.Error(t, err)
+}
+
+func TestLoginRequiresUserVerification(t *testing.T) {
+	rp := newRelyingParty(t)
+	authenticator := newSoftAuthenticator(t, origin)
+	user := newUser()
+	user.Credentials = []webauthn.Credential{*register(t, rp, user, authenticator)}
+
+	authenticator.userVerified = false
+	_, _, err := login(t, rp, map[string]*passkey.User{publicID: user}, authenticator)
+	assert.Error(t, err)
+}
+
+func TestLoginDetectsClonedAuthenticator(t *testing.T) {
+	rp := newRelyingParty(t)
+	authenticator := newSoftAuthenticator(t, origin)
+	user := newUser()
+	credential := register(t, rp, user, authenticator)
+
+	// The server already saw counter 5, the authenticator is now at 1
+	credential.Authenticator.SignCount = 5
+	user.Credentials = []webauthn.Credential{*credential}
+	_, _, err := login(t, rp, map[string]*passkey.User{publicID: user}, authenticator)
+	assert.ErrorIs(t, err, passkey.ErrCloneDetected)
+}
+
+func TestLoginRejectsUnknownUser(t *testing.T) {
+	rp := newRelyingParty(t)
+	authenticator := newSoftAuthenticator(t, origin)
+	user := newUser()
+	register(t, rp, user, authenticator)
+
+	_, _, err := login(t, rp, map[string]*passkey.User{}, authenticator)
+	assert.Error(t, err)
+}
+
+func TestLoginRejectsReplayedChallenge(t *testing.T) {
+	rp := newRelyingParty(t)
+	authenticator := newSoftAuthenticator(t, origin)
+	user := newUser()
+	user.Credentials = []webauthn.Credential{*register(t, rp, user, authenticator)}
+
+	// An assertion for one challenge doesn't complete another login
+	options, _, err := rp.BeginLogin()
+	require.NoError(t, err)
+	_, session, err := rp.BeginLogin()
+	require.NoError(t, err)
+	_, _, err = rp.FinishLogin(*session, authenticator.Get(t, options), func([]byte) (*passkey.User, error) {
+		return user, nil
+	})
+	assert.Error(t, err)
+}
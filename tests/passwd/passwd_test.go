@@ -0,0 +1,92 @@
+package passwd
+
+import (
+	"base_go_be/pkg/passwd"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashVerify(t *testing.T) {
+	hash, err := passwd.Hash("Correct-Horse-9")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=2$"))
+
+	match, rehash, err := passwd.Verify(hash, "Correct-Horse-9")
+	assert.NoError(t, err)
+	assert.True(t, match)
+	assert.False(t, rehash)
+
+	match, _, err = passwd.Verify(hash, "correct-horse-9")
+	assert.NoError(t, err)
+	assert.False(t, match)
+
+	other, _ := passwd.Hash("Correct-Horse-9")
+	assert.NotEqual(t, hash, other, "salt must differ")
+}
+
+func TestVerifyBcryptNeedsRehash(t *testing.T) {
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("Legacy-Pass-1"), bcrypt.MinCost)
+
+	match, rehash, err := passwd.Verify(string(legacy), "Legacy-Pass-1")
+	assert.NoError(t, err)
+	assert.True(t, match)
+	assert.True(t, rehash)
+
+	match, rehash, err = passwd.Verify(string(legacy), "wrong")
+	assert.NoError(t, err)
+	assert.False(t, match)
+	assert.False(t, rehash)
+}
+
+func TestVerifyRehashOnParamsChange(t *testing.T) {
+	hash, _ := passwd.Hash("Correct-Horse-9")
+
+	params := passwd.DefaultParams
+	params.Iterations = 4
+	passwd.SetParams(params)
+	defer passwd.SetParams(passwd.DefaultParams)
+
+	match, rehash, err := passwd.Verify(hash, "Correct-Horse-9")
+	assert.NoError(t, err)
+	assert.True(t, match)
+	assert.True(t, rehash)
+}
+
+func TestVerifyRejectsUnknownHash(t *testing.T) {
+	_, _, err := passwd.Verify("plaintext", "plaintext")
+	assert.ErrorIs(t, err, passwd.ErrUnknownHash)
+}
+
+func rules(violations []passwd.Violation) []string {
+	names := make([]string, 0, len(violations))
+	for _, v := range violations {
+		names = append(names, v.Rule)
+	}
+	return names
+}
+
+func TestPolicyCheck(t *testing.T) {
+	policy := passwd.DefaultPolicy()
+
+	assert.Empty(t, policy.Check("Tr0ub4dor-horse", "alice@example.com", "alice"))
+	assert.ElementsMatch(t, []string{passwd.RuleMinLength, passwd.RuleUppercase, passwd.RuleDigit},
+		rules(policy.Check("short", "", "")))
+	assert.Equal(t, []string{passwd.RuleBreached}, rules(policy.Check("Password123", "", "")))
+	assert.ElementsMatch(t, []string{passwd.RuleContainsEmail, passwd.RuleContainsUsername},
+		rules(policy.Check("Alice-Secret-42", "alice@example.com", "Alice")))
+
+	// Identifiers too short to be meaningful are ignored
+	assert.Empty(t, policy.Check("Secret-Al-Pass-7", "al@example.com", "al"))
+
+	policy.RequireSymbol = true
+	assert.Equal(t, []string{passwd.RuleSymbol}, rules(policy.Check("Tr0ub4dorHorse", "", "")))
+}
+
+func TestPolicyAddBreached(t *testing.T) {
+	policy := passwd.DefaultPolicy()
+	assert.NoError(t, policy.AddBreached(strings.NewReader("# comment\nKado-Office-2024\n")))
+	assert.Equal(t, []string{passwd.RuleBreached}, rules(policy.Check("KADO-office-2024", "", "")))
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/service"
+	"base_go_be/pkg/response"
+	"base_go_be/tests/fakes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const roleSupport = "SUPPORT"
+
+// newSupport adds a support agent allowed to manage users, but with fewer permissions
+// than admins
+func newSupport(t *testing.T, repos *fakes.UserServiceRepos) *service.Actor {
+	t.Helper()
+	repos.Roles.SetRole(roleSupport, model.PermissionUserManage)
+	support := addUser(t, repos, "support", "Correct-Horse-42", roleSupport)
+	return &service.Actor{UserID: support.ID, Role: roleSupport, Permissions: []string{model.PermissionUserManage}}
+}
+
+func TestGetUserDetailIsAudited(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService(model.PermissionUserManage, model.PermissionRoleManage)
+	support := newSupport(t, repos)
+	bob := addUser(t, repos, "bob", "Correct-Horse-42", model.RoleUser)
+
+	result := userService.GetUserDetail(support, bob.PublicID, client)
+	require.NoError(t, result.Error)
+	assert.Equal(t, bob.PublicID, result.Data.(*dto.AdminUserDetailDto).Id)
+	assert.Equal(t, []string{model.AuditActionUserView}, repos.AuditLogs.Actions())
+}
+
+func TestListUserSessionsIsAudited(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService(model.PermissionUserManage, model.PermissionRoleManage)
+	support := newSupport(t, repos)
+	bob := addUser(t, repos, "bob", "Correct-Horse-42", model.RoleUser)
+	login(t, userService, bob)
+
+	result := userService.ListUserSessions(support, bob.PublicID, client)
+	require.NoError(t, result.Error)
+	assert.Len(t, result.Data, 1)
+	assert.Equal(t, []string{model.AuditActionUserSessionsView}, repos.AuditLogs.Actions())
+}
+
+func TestAdminReadsStayWithinRole(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService(model.PermissionUserManage, model.PermissionRoleManage)
+	support := newSupport(t, repos)
+	admin := addUser(t, repos, "admin", "Correct-Horse-42", model.RoleAdmin)
+
+	detail := userService.GetUserDetail(support, admin.PublicID, client)
+	assert.Equal(t, 403, detail.StatusCode)
+	sessions := userService.ListUserSessions(support, admin.PublicID, client)
+	assert.Equal(t, 403, sessions.StatusCode)
+	assert.Empty(t, repos.AuditLogs.Actions())
+}
+
+func TestDeletedUserLogsOutAndFreesEmail(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService(model.PermissionUserManage, model.PermissionRoleManage)
+	support := newSupport(t, repos)
+	bob := addUser(t, repos, "bob", "Correct-Horse-42", model.RoleUser)
+	auth := login(t, userService, bob)
+
+	require.NoError(t, userService.DeleteUser(support, bob.PublicID, client).Error)
+	assert.Equal(t, 401, userService.RefreshToken(auth.RefreshToken, client).StatusCode)
+	assert.Equal(t, response.ErrCodeInvalidLogin, userService.Login(bob.Email, "Correct-Horse-42", client).ErrorCode)
+	assert.Equal(t, 404, userService.GetUserDetail(support, bob.PublicID, client).StatusCode)
+
+	registered := userService.Register(dto.RegisterRequestDto{
+		Username: "bob", Email: bob.Email, Password: "Correct-Horse-42",
+	}, client)
+	assert.NoError(t, registered.Error)
+}
+
+func TestRestoreUser(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService(model.PermissionUserManage, model.PermissionRoleManage)
+	support := newSupport(t, repos)
+	bob := addUser(t, repos, "bob", "Correct-Horse-42", model.RoleUser)
+	require.NoError(t, userService.DeleteUser(support, bob.PublicID, client).Error)
+
+	require.NoError(t, userService.RestoreUser(support, bob.PublicID, client).Error)
+	login(t, userService, bob)
+	assert.Equal(t, []string{model.AuditActionUserDelete, model.AuditActionUserRestore}, repos.AuditLogs.Actions())
+}
+
+func TestRestoreUserRefusedOnceEmailTaken(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService(model.PermissionUserManage, model.PermissionRoleManage)
+	support := newSupport(t, repos)
+	bob := addUser(t, repos, "bob", "Correct-Horse-42", model.RoleUser)
+	require.NoError(t, userService.DeleteUser(support, bob.PublicID, client).Error)
+	repos.Users.Add(&model.User{Username: "bob2", Email: bob.Email})
+
+	result := userService.RestoreUser(support, bob.PublicID, client)
+	assert.Equal(t, 409, result.StatusCode)
+	assert.Equal(t, response.ErrCodeUserHasExists, result.ErrorCode)
+}
+
+func TestDeleteUserStaysWithinRole(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService(model.PermissionUserManage, model.PermissionRoleManage)
+	support := newSupport(t, repos)
+	admin := addUser(t, repos, "admin", "Correct-Horse-42", model.RoleAdmin)
+
+	assert.Equal(t, 403, userService.DeleteUser(support, admin.PublicID, client).StatusCode)
+	assert.NotNil(t, repos.Users.GetUserByID(admin.ID))
+}
+
+func TestAdminActionsNeedUserManage(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+	bob := addUser(t, repos, "bob", "Correct-Horse-42", model.RoleUser)
+	login(t, userService, bob)
+
+	// Same role as the target, but without the permission the router would check
+	actor := &service.Actor{UserID: alice.ID, Role: model.RoleUser, Permissions: []string{model.PermissionProductRead}}
+	assert.Equal(t, 403, userService.DeactivateUser(actor, bob.PublicID, client).StatusCode)
+	assert.Equal(t, 403, userService.DeleteUser(actor, bob.PublicID, client).StatusCode)
+	assert.Equal(t, 403, userService.ForceLogout(actor, bob.PublicID, client).StatusCode)
+	assert.True(t, repos.Users.Get(bob.ID).IsActive)
+	sessions, _ := repos.Sessions.ListUserSessions(bob.ID)
+	assert.Len(t, sessions, 1)
+	assert.Empty(t, repos.AuditLogs.Actions())
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/pkg/mailer"
+	"base_go_be/pkg/response"
+	"base_go_be/tests/fakes"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var linkPattern = regexp.MustCompile(`http://frontend\.test/\S+`)
+
+// linkToken returns the token of the frontend link in the email
+func linkToken(t *testing.T, msg mailer.Message) string {
+	t.Helper()
+	link, err := url.Parse(linkPattern.FindString(msg.Body))
+	require.NoError(t, err)
+	token := link.Query().Get("token")
+	require.NotEmpty(t, token)
+	return token
+}
+
+func TestEmailChangeConfirmedFromNewAddress(t *testing.T) {
+	mail := fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+
+	result := userService.RequestEmailChange(alice.ID, dto.ChangeEmailRequestDto{
+		Email: "alice@new.example.com", Password: "Correct-Horse-42",
+	}, client)
+	require.NoError(t, result.Error)
+	confirmation := mail.To("alice@new.example.com", 2)
+	require.Len(t, confirmation, 1)
+	notice := mail.To(alice.Email, 2)
+	require.Len(t, notice, 1)
+	assert.NotContains(t, notice[0].Body, "alice@new.example.com", "the new address is masked")
+	assert.Equal(t, alice.Email, repos.Users.Get(alice.ID).Email, "unchanged until confirmed")
+
+	token := linkToken(t, confirmation[0])
+	require.NoError(t, userService.ConfirmEmailChange(token).Error)
+	changed := repos.Users.Get(alice.ID)
+	assert.Equal(t, "alice@new.example.com", changed.Email)
+	assert.True(t, changed.IsEmailVerified())
+
+	again := userService.ConfirmEmailChange(token)
+	assert.Equal(t, response.ErrInvalidToken, again.ErrorCode)
+}
+
+func TestEmailChangeRechecksAddressAtConfirmation(t *testing.T) {
+	mail := fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+
+	result := userService.RequestEmailChange(alice.ID, dto.ChangeEmailRequestDto{
+		Email: "taken@example.com", Password: "Correct-Horse-42",
+	}, client)
+	require.NoError(t, result.Error)
+	confirmation := mail.To("taken@example.com", 2)
+	require.Len(t, confirmation, 1)
+
+	// Someone registers the address before the link is opened
+	addUser(t, repos, "taken", "Correct-Horse-42", model.RoleUser)
+	confirmed := userService.ConfirmEmailChange(linkToken(t, confirmation[0]))
+	assert.Equal(t, 409, confirmed.StatusCode)
+	assert.Equal(t, response.ErrCodeUserHasExists, confirmed.ErrorCode)
+	assert.Equal(t, alice.Email, repos.Users.Get(alice.ID).Email)
+}
+
+func TestEmailChangeNeedsPassword(t *testing.T) {
+	mail := fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+
+	result := userService.RequestEmailChange(alice.ID, dto.ChangeEmailRequestDto{
+		Email: "alice@new.example.com", Password: "wrong-password",
+	}, client)
+	assert.Equal(t, response.ErrCodeInvalidLogin, result.ErrorCode)
+	assert.Empty(t, mail.To("alice@new.example.com", 0))
+}
+
+func TestEmailChangeNewRequestVoidsPreviousLink(t *testing.T) {
+	mail := fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+
+	for _, email := range []string{"first@example.com", "second@example.com"} {
+		result := userService.RequestEmailChange(alice.ID, dto.ChangeEmailRequestDto{Email: email, Password: "Correct-Horse-42"}, client)
+		require.NoError(t, result.Error)
+	}
+	first := mail.To("first@example.com", 4)
+	require.Len(t, first, 1)
+
+	assert.Equal(t, response.ErrInvalidToken, userService.ConfirmEmailChange(linkToken(t, first[0])).ErrorCode)
+	assert.Equal(t, alice.Email, repos.Users.Get(alice.ID).Email)
+}
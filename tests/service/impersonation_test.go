@@ -0,0 +1,66 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/service"
+	"base_go_be/pkg/jwt"
+	"base_go_be/tests/fakes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// impersonate has an admin start impersonating bob, returning the token
+func impersonate(t *testing.T, userService service.IUserService, admin *model.User, bob *model.User) string {
+	t.Helper()
+	actor := &service.Actor{UserID: admin.ID, Role: model.RoleAdmin, Permissions: []string{model.PermissionUserImpersonate}}
+	result := userService.ImpersonateUser(actor, bob.PublicID, "ticket 42", client)
+	require.NoError(t, result.Error)
+	return result.Data.(*dto.ImpersonationResponseDto).Token
+}
+
+func TestImpersonationEndedByLogout(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService(model.PermissionUserImpersonate)
+	admin := addUser(t, repos, "admin", "Correct-Horse-42", model.RoleAdmin)
+	bob := addUser(t, repos, "bob", "Correct-Horse-42", model.RoleUser)
+	claims, err := jwt.ValidateToken(impersonate(t, userService, admin, bob), jwt.TokenTypeAccess)
+	require.NoError(t, err)
+
+	actor := &service.Actor{UserID: bob.ID, Role: model.RoleUser, ImpersonatorID: admin.ID}
+	require.NoError(t, userService.Logout(actor, claims, client).Error)
+	assert.Equal(t, []string{model.AuditActionImpersonationStart, model.AuditActionImpersonationEnd}, repos.AuditLogs.Actions())
+
+	// Already ended, so nothing is left to expire
+	open, err := repos.Sessions.ExpiredImpersonations(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, open)
+}
+
+func TestExpiredImpersonationEnded(t *testing.T) {
+	fakes.Setup()
+	global.Config.Auth.ImpersonationTTL = 0
+	userService, repos := fakes.NewUserService(model.PermissionUserImpersonate)
+	admin := addUser(t, repos, "admin", "Correct-Horse-42", model.RoleAdmin)
+	bob := addUser(t, repos, "bob", "Correct-Horse-42", model.RoleUser)
+	impersonate(t, userService, admin, bob)
+
+	ended, err := userService.EndExpiredImpersonations()
+	require.NoError(t, err)
+	assert.Equal(t, 1, ended)
+	entries, _, _ := repos.AuditLogs.GetListAuditLog(dto.AuditLogListRequestDto{})
+	require.Len(t, entries, 2)
+	end := entries[1]
+	assert.Equal(t, model.AuditActionImpersonationEnd, end.Action)
+	assert.Equal(t, admin.ID, end.ActorUserID)
+	assert.Equal(t, bob.ID, *end.TargetUserID)
+	assert.Equal(t, true, end.Details["expired"])
+
+	ended, err = userService.EndExpiredImpersonations()
+	require.NoError(t, err)
+	assert.Zero(t, ended)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/service"
+	"base_go_be/pkg/response"
+	"base_go_be/tests/fakes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failLogins makes the attempts the lockout tolerates, AUTH_LOGIN_MAX_ATTEMPTS being 3
+func failLogins(t *testing.T, userService service.IUserService, email string) {
+	t.Helper()
+	for range 3 {
+		result := userService.Login(email, "wrong-password", client)
+		require.Equal(t, response.ErrCodeInvalidLogin, result.ErrorCode)
+	}
+}
+
+func TestLoginLockedAfterFailures(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+	failLogins(t, userService, alice.Email)
+
+	// Even the right password is refused while locked
+	result := userService.Login(alice.Email, "Correct-Horse-42", client)
+	assert.Equal(t, 423, result.StatusCode)
+	assert.Equal(t, response.ErrCodeAccountLocked, result.ErrorCode)
+}
+
+func TestLoginUnknownEmailLockedAlike(t *testing.T) {
+	fakes.Setup()
+	userService, _ := fakes.NewUserService()
+	failLogins(t, userService, "nobody@example.com")
+
+	result := userService.Login("nobody@example.com", "wrong-password", client)
+	assert.Equal(t, response.ErrCodeAccountLocked, result.ErrorCode)
+}
+
+func TestUnlockLogin(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService(model.PermissionUserManage)
+	admin := addUser(t, repos, "admin", "Correct-Horse-42", model.RoleAdmin)
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+	failLogins(t, userService, alice.Email)
+
+	actor := &service.Actor{UserID: admin.ID, Role: model.RoleAdmin, Permissions: []string{model.PermissionUserManage}}
+	require.NoError(t, userService.UnlockLogin(actor, alice.PublicID, client).Error)
+
+	result := userService.Login(alice.Email, "Correct-Horse-42", client)
+	require.NoError(t, result.Error)
+	assert.IsType(t, &dto.AuthResponseDto{}, result.Data)
+	assert.Equal(t, []string{model.AuditActionUserLoginUnlock}, repos.AuditLogs.Actions())
+}
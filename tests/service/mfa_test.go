@@ -0,0 +1,100 @@
+package service
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/service"
+	"base_go_be/pkg/response"
+	"base_go_be/pkg/totp"
+	"base_go_be/tests/fakes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enableMfa turns 2FA on for the user and returns their recovery codes
+func enableMfa(t *testing.T, userService service.IUserService, user *model.User) []string {
+	t.Helper()
+	setup := userService.SetupMfa(user.ID)
+	require.NoError(t, setup.Error)
+	code, err := totp.GenerateCode(setup.Data.(*dto.MfaSetupResponseDto).Secret, time.Now())
+	require.NoError(t, err)
+	confirmed := userService.ConfirmMfa(user.ID, code)
+	require.NoError(t, confirmed.Error)
+	return confirmed.Data.(*dto.MfaRecoveryCodesResponseDto).RecoveryCodes
+}
+
+// mfaChallenge logs in with the password and returns the challenge token
+func mfaChallenge(t *testing.T, userService service.IUserService, user *model.User) string {
+	t.Helper()
+	result := userService.Login(user.Email, "Correct-Horse-42", client)
+	require.NoError(t, result.Error)
+	challenge, ok := result.Data.(*dto.MfaChallengeResponseDto)
+	require.True(t, ok, "got %T", result.Data)
+	require.True(t, challenge.MfaRequired)
+	return challenge.MfaToken
+}
+
+func TestLoginMfaTokenIsSingleUse(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+	recoveryCodes := enableMfa(t, userService, alice)
+	mfaToken := mfaChallenge(t, userService, alice)
+
+	first := userService.LoginMfa(mfaToken, recoveryCodes[0], client)
+	require.NoError(t, first.Error)
+	assert.IsType(t, &dto.AuthResponseDto{}, first.Data)
+
+	second := userService.LoginMfa(mfaToken, recoveryCodes[1], client)
+	require.Error(t, second.Error)
+	assert.Equal(t, 401, second.StatusCode)
+	assert.Equal(t, response.ErrInvalidToken, second.ErrorCode)
+}
+
+func TestLoginMfaWrongCodeSpendsToken(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+	recoveryCodes := enableMfa(t, userService, alice)
+	mfaToken := mfaChallenge(t, userService, alice)
+
+	wrong := userService.LoginMfa(mfaToken, "wrong-code", client)
+	assert.Equal(t, response.ErrCodeInvalidMfaCode, wrong.ErrorCode)
+
+	retry := userService.LoginMfa(mfaToken, recoveryCodes[0], client)
+	assert.Equal(t, response.ErrInvalidToken, retry.ErrorCode)
+}
+
+func TestLoginMfaConcurrentRedemptions(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+	recoveryCodes := enableMfa(t, userService, alice)
+	mfaToken := mfaChallenge(t, userService, alice)
+
+	// Each request has a valid code of its own, only the token is shared
+	var wg sync.WaitGroup
+	results := make([]*response.ServiceResult, 4)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = userService.LoginMfa(mfaToken, recoveryCodes[i], client)
+		}()
+	}
+	wg.Wait()
+
+	loggedIn := 0
+	for _, result := range results {
+		if result.Error == nil {
+			loggedIn++
+		}
+	}
+	assert.Equal(t, 1, loggedIn)
+	sessions, _ := repos.Sessions.ListUserSessions(alice.ID)
+	assert.Len(t, sessions, 1)
+}
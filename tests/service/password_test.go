@@ -0,0 +1,162 @@
+package service
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/service"
+	"base_go_be/pkg/passwd"
+	"base_go_be/pkg/response"
+	"base_go_be/tests/fakes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addUser stores an active, verified user with the password given
+func addUser(t *testing.T, repos *fakes.UserServiceRepos, username string, password string, role string) *model.User {
+	t.Helper()
+	hash, err := passwd.Hash(password)
+	require.NoError(t, err)
+	verifiedAt := time.Now()
+	id := repos.Users.Add(&model.User{
+		Username: username, Email: username + "@example.com", Password: hash, Role: role, EmailVerifiedAt: &verifiedAt,
+	})
+	return repos.Users.Get(id)
+}
+
+func TestUpdateUserRefusesOwnPassword(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+
+	result := userService.UpdateUser(&service.Actor{UserID: alice.ID, Role: model.RoleUser}, alice.PublicID,
+		dto.UserUpdateRequestDto{Password: "Battery-Staple-77"})
+	require.Error(t, result.Error)
+	assert.Equal(t, 403, result.StatusCode)
+	assert.Equal(t, response.ErrCodeUseChangePassword, result.ErrorCode)
+	assert.Equal(t, alice.Password, repos.Users.Get(alice.ID).Password)
+}
+
+func TestUpdateUserSetsPasswordOfOthers(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	admin := addUser(t, repos, "admin", "Correct-Horse-42", model.RoleAdmin)
+	bob := addUser(t, repos, "bob", "Correct-Horse-42", model.RoleUser)
+
+	actor := &service.Actor{UserID: admin.ID, Role: model.RoleAdmin, Permissions: []string{model.PermissionUserUpdate}}
+	result := userService.UpdateUser(actor, bob.PublicID, dto.UserUpdateRequestDto{Password: "Battery-Staple-77"})
+	require.NoError(t, result.Error)
+	match, _, err := passwd.Verify(repos.Users.Get(bob.ID).Password, "Battery-Staple-77")
+	require.NoError(t, err)
+	assert.True(t, match)
+}
+
+func TestRegisterRefusesWeakPassword(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+
+	result := userService.Register(dto.RegisterRequestDto{
+		Username: "alice", Email: "alice@example.com", Password: "alice",
+	}, client)
+	require.Error(t, result.Error)
+	assert.Equal(t, 422, result.StatusCode)
+	assert.Equal(t, response.ErrCodeWeakPassword, result.ErrorCode)
+
+	var rules []string
+	for _, violation := range result.Data.(*dto.PasswordPolicyErrorDto).Violations {
+		rules = append(rules, violation.Rule)
+	}
+	assert.Subset(t, rules, []string{passwd.RuleMinLength, passwd.RuleUppercase, passwd.RuleDigit, passwd.RuleContainsUsername})
+	assert.Nil(t, repos.Users.GetUserByEmail("alice@example.com"))
+}
+
+func TestChangePasswordLogsOutOtherSessions(t *testing.T) {
+	mail := fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+	current := login(t, userService, alice)
+	other := login(t, userService, alice)
+
+	result := userService.ChangePassword(alice.ID, sessionID(t, current), dto.ChangePasswordRequestDto{
+		CurrentPassword: "Correct-Horse-42", NewPassword: "Battery-Staple-77",
+	}, client)
+	require.NoError(t, result.Error)
+
+	sessions, _ := repos.Sessions.ListUserSessions(alice.ID)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, sessionID(t, current), sessions[0].ID)
+	assert.Equal(t, 401, userService.RefreshToken(other.RefreshToken, client).StatusCode)
+	assert.NoError(t, userService.RefreshToken(current.RefreshToken, client).Error)
+
+	match, _, err := passwd.Verify(repos.Users.Get(alice.ID).Password, "Battery-Staple-77")
+	require.NoError(t, err)
+	assert.True(t, match)
+	notice := mail.To(alice.Email, 1)
+	require.Len(t, notice, 1)
+	assert.Contains(t, notice[0].Body, client.IP)
+}
+
+func TestChangePasswordChecksCurrentPassword(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+	current := login(t, userService, alice)
+
+	result := userService.ChangePassword(alice.ID, sessionID(t, current), dto.ChangePasswordRequestDto{
+		CurrentPassword: "wrong-password", NewPassword: "Battery-Staple-77",
+	}, client)
+	assert.Equal(t, 401, result.StatusCode)
+	assert.Equal(t, response.ErrCodeInvalidLogin, result.ErrorCode)
+	assert.Equal(t, alice.Password, repos.Users.Get(alice.ID).Password)
+}
+
+func TestChangePasswordAppliesPolicy(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+	current := login(t, userService, alice)
+
+	result := userService.ChangePassword(alice.ID, sessionID(t, current), dto.ChangePasswordRequestDto{
+		CurrentPassword: "Correct-Horse-42", NewPassword: "password",
+	}, client)
+	assert.Equal(t, 422, result.StatusCode)
+	assert.Equal(t, response.ErrCodeWeakPassword, result.ErrorCode)
+}
+
+func TestForgotPasswordThrottledPerEmail(t *testing.T) {
+	mail := fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+
+	for range 3 {
+		require.NoError(t, userService.ForgotPassword(alice.Email, client).Error)
+	}
+	result := userService.ForgotPassword(strings.ToUpper(alice.Email), client)
+	assert.Equal(t, 429, result.StatusCode)
+	assert.Equal(t, response.ErrCodeTooManyRequests, result.ErrorCode)
+	assert.Len(t, mail.To(alice.Email, 3), 3)
+
+	// Unknown addresses are throttled alike
+	for range 3 {
+		userService.ForgotPassword("nobody@example.com", client)
+	}
+	assert.Equal(t, 429, userService.ForgotPassword("nobody@example.com", client).StatusCode)
+}
+
+func TestForgotPasswordThrottledPerIP(t *testing.T) {
+	fakes.Setup()
+	userService, _ := fakes.NewUserService()
+
+	for i := range 20 {
+		require.NoError(t, userService.ForgotPassword(fmt.Sprintf("user%d@example.com", i), client).Error)
+	}
+	assert.Equal(t, 429, userService.ForgotPassword("user20@example.com", client).StatusCode)
+
+	other := client
+	other.IP = "198.51.100.9"
+	assert.NoError(t, userService.ForgotPassword("user20@example.com", other).Error)
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/service"
+	"base_go_be/pkg/response"
+	"base_go_be/tests/fakes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var productReader = &service.Actor{UserID: 1, Role: model.RoleUser, Permissions: []string{model.PermissionProductRead}}
+
+func TestCreatePersonalTokenWithinRole(t *testing.T) {
+	fakes.Setup()
+	tokens := fakes.NewPersonalTokenRepository()
+	tokenService := service.NewPersonalTokenService(tokens)
+
+	result := tokenService.CreatePersonalToken(productReader, dto.PersonalTokenRequestDto{
+		Name: "script", Scopes: []string{model.PermissionProductRead, model.PermissionProductRead}, ExpiresInDays: 30,
+	})
+	require.NoError(t, result.Error)
+	created := result.Data.(*dto.PersonalTokenCreatedResponseDto)
+	assert.True(t, strings.HasPrefix(created.Token, model.PersonalTokenPrefix))
+
+	stored := tokens.GetPersonalTokenByToken(created.Token)
+	require.NotNil(t, stored)
+	assert.Equal(t, []string{model.PermissionProductRead}, stored.Scopes)
+}
+
+func TestCreatePersonalTokenRefusesScopeBeyondRole(t *testing.T) {
+	fakes.Setup()
+	tokens := fakes.NewPersonalTokenRepository()
+	tokenService := service.NewPersonalTokenService(tokens)
+
+	result := tokenService.CreatePersonalToken(productReader, dto.PersonalTokenRequestDto{
+		Name: "script", Scopes: []string{model.PermissionProductRead, model.PermissionProductDelete}, ExpiresInDays: 30,
+	})
+	assert.Equal(t, 422, result.StatusCode)
+	assert.Equal(t, response.ErrCodeInvalidScope, result.ErrorCode)
+	stored, _ := tokens.GetListPersonalToken(productReader.UserID)
+	assert.Empty(t, stored)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"base_go_be/internal/model"
+	"base_go_be/tests/fakes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const retention = 30 * 24 * time.Hour
+
+func deletedUser(repos *fakes.UserServiceRepos, username string, deletedAt time.Time) uint {
+	id := repos.Users.Add(&model.User{Username: username, Email: username + "@example.com"})
+	repos.Users.SetDeletedAt(id, deletedAt)
+	return id
+}
+
+func TestPurgeDeletedUsersKeepsRecentlyDeleted(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	old := deletedUser(repos, "old", time.Now().Add(-retention-time.Hour))
+	recent := deletedUser(repos, "recent", time.Now().Add(-time.Hour))
+
+	purged, err := userService.PurgeDeletedUsers(retention)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.Nil(t, repos.Users.Get(old))
+	assert.NotNil(t, repos.Users.Get(recent))
+}
+
+func TestPurgeDeletedUsersContinuesAfterFailure(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	first := deletedUser(repos, "first", time.Now().Add(-retention-2*time.Hour))
+	failing := deletedUser(repos, "failing", time.Now().Add(-retention-2*time.Hour))
+	last := deletedUser(repos, "last", time.Now().Add(-retention-2*time.Hour))
+	repos.Users.PurgeErrors[failing] = errors.New("foreign key violation")
+
+	purged, err := userService.PurgeDeletedUsers(retention)
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	assert.Nil(t, repos.Users.Get(first))
+	assert.NotNil(t, repos.Users.Get(failing), "kept for the next run")
+	assert.Nil(t, repos.Users.Get(last))
+}
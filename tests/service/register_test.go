@@ -0,0 +1,99 @@
+package service
+
+import (
+	"base_go_be/global"
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/service"
+	"base_go_be/pkg/jwt"
+	"base_go_be/pkg/response"
+	"base_go_be/tests/fakes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var client = dto.ClientInfoDto{IP: "203.0.113.7", UserAgent: "Mozilla/5.0 (Windows NT 10.0) Chrome/120.0"}
+
+func TestRegisterLogsIn(t *testing.T) {
+	mail := fakes.Setup()
+	userService, repos := fakes.NewUserService()
+
+	result := userService.Register(dto.RegisterRequestDto{
+		Username: "alice", Email: "alice@example.com", Password: "Correct-Horse-42",
+	}, client)
+	require.NoError(t, result.Error)
+
+	auth, ok := result.Data.(*dto.AuthResponseDto)
+	require.True(t, ok, "got %T", result.Data)
+	user := repos.Users.GetUserByEmail("alice@example.com")
+	require.NotNil(t, user)
+	assert.Equal(t, user.PublicID, auth.User.Id)
+	assert.True(t, model.IsPublicID(auth.User.Id))
+	assert.Equal(t, model.RoleUser, auth.User.Role)
+
+	claims, err := jwt.ValidateToken(auth.Token, jwt.TokenTypeAccess)
+	require.NoError(t, err)
+	assert.Equal(t, user.PublicID, claims.UserID, "tokens name the user by public ID")
+	assert.Equal(t, user.PublicID, claims.Subject)
+	sessions, _ := repos.Sessions.ListUserSessions(user.ID)
+	assert.Len(t, sessions, 1)
+
+	verification := mail.To("alice@example.com", 1)
+	require.Len(t, verification, 1)
+	assert.Contains(t, verification[0].Body, "http://frontend.test/verify-email?token=")
+}
+
+func TestRegisterWaitsForVerification(t *testing.T) {
+	fakes.Setup()
+	global.Config.Auth.RequireEmailVerification = true
+	userService, repos := fakes.NewUserService()
+
+	result := userService.Register(dto.RegisterRequestDto{
+		Username: "bob", Email: "bob@example.com", Password: "Correct-Horse-42",
+	}, client)
+	require.NoError(t, result.Error)
+	assert.IsType(t, &dto.MessageResponseDto{}, result.Data)
+
+	user := repos.Users.GetUserByEmail("bob@example.com")
+	require.NotNil(t, user)
+	sessions, _ := repos.Sessions.ListUserSessions(user.ID)
+	assert.Empty(t, sessions)
+}
+
+func TestRegisterRefusesTakenEmail(t *testing.T) {
+	fakes.Setup()
+	userService, _ := fakes.NewUserService()
+	register := dto.RegisterRequestDto{Username: "carol", Email: "carol@example.com", Password: "Correct-Horse-42"}
+	require.NoError(t, userService.Register(register, client).Error)
+
+	register.Username = "carol2"
+	result := userService.Register(register, client)
+	assert.Equal(t, 409, result.StatusCode)
+	assert.Equal(t, response.ErrCodeUserHasExists, result.ErrorCode)
+}
+
+func TestRegisterCantChooseAdmin(t *testing.T) {
+	fakes.Setup()
+	userService, _ := fakes.NewUserService()
+
+	result := userService.Register(dto.RegisterRequestDto{
+		Username: "mallory", Email: "mallory@example.com", Password: "Correct-Horse-42", Role: model.RoleAdmin,
+	}, client)
+	assert.Equal(t, 403, result.StatusCode)
+}
+
+func TestCreateUserReturnsPublicID(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService(model.PermissionUserCreate)
+	admin := &service.Actor{UserID: 1, Role: model.RoleAdmin, Permissions: []string{model.PermissionUserCreate}}
+
+	result := userService.CreateUser(admin, "dave@example.com", "dave", "Correct-Horse-42", model.RoleUser)
+	require.NoError(t, result.Error)
+	publicID, ok := result.Data.(string)
+	require.True(t, ok, "got %T", result.Data)
+	assert.False(t, strings.ContainsAny(publicID, "@"))
+	assert.Equal(t, publicID, repos.Users.GetUserByEmail("dave@example.com").PublicID)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"base_go_be/internal/dto"
+	"base_go_be/internal/model"
+	"base_go_be/internal/repo"
+	"base_go_be/internal/service"
+	"base_go_be/pkg/jwt"
+	"base_go_be/pkg/response"
+	"base_go_be/tests/fakes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// login logs the user in with their password, they must not have 2FA
+func login(t *testing.T, userService service.IUserService, user *model.User) *dto.AuthResponseDto {
+	t.Helper()
+	result := userService.Login(user.Email, "Correct-Horse-42", client)
+	require.NoError(t, result.Error)
+	auth, ok := result.Data.(*dto.AuthResponseDto)
+	require.True(t, ok, "got %T", result.Data)
+	return auth
+}
+
+func sessionID(t *testing.T, auth *dto.AuthResponseDto) string {
+	t.Helper()
+	claims, err := jwt.ValidateToken(auth.Token, jwt.TokenTypeAccess)
+	require.NoError(t, err)
+	return claims.SessionID
+}
+
+func TestRefreshDoesNotReviveEndedSession(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+	auth := login(t, userService, alice)
+
+	// The session is revoked from another device while the refresh is under way
+	repos.Sessions.BeforeTouch = func(session *repo.Session) {
+		require.NoError(t, repos.Sessions.DeleteSession(session.UserID, session.ID))
+	}
+	userService.RefreshToken(auth.RefreshToken, client)
+
+	session, err := repos.Sessions.GetSession(sessionID(t, auth))
+	require.NoError(t, err)
+	assert.Nil(t, session)
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+	auth := login(t, userService, alice)
+
+	result := userService.RefreshToken(auth.RefreshToken, client)
+	require.NoError(t, result.Error)
+	refreshed := result.Data.(*dto.AuthResponseDto)
+	assert.NotEqual(t, auth.RefreshToken, refreshed.RefreshToken)
+	assert.Equal(t, sessionID(t, auth), sessionID(t, refreshed), "same session")
+
+	again := userService.RefreshToken(refreshed.RefreshToken, client)
+	assert.NoError(t, again.Error)
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	fakes.Setup()
+	userService, repos := fakes.NewUserService()
+	alice := addUser(t, repos, "alice", "Correct-Horse-42", model.RoleUser)
+	auth := login(t, userService, alice)
+	refreshed := userService.RefreshToken(auth.RefreshToken, client).Data.(*dto.AuthResponseDto)
+
+	// The old token showing up again means one of the two holders stole it
+	reused := userService.RefreshToken(auth.RefreshToken, client)
+	assert.Equal(t, 401, reused.StatusCode)
+	assert.Equal(t, response.ErrCodeTokenReused, reused.ErrorCode)
+	assert.True(t, repos.Tokens.FamilyRevoked(sessionID(t, auth)))
+
+	// so the legitimate holder has to log in again too
+	legit := userService.RefreshToken(refreshed.RefreshToken, client)
+	assert.Equal(t, 401, legit.StatusCode)
+}
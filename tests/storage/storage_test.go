@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"base_go_be/pkg/setting"
+	"base_go_be/pkg/storage"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalPutAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewLocalStorage(dir, "https://cdn.example.com/files/")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("avatars/a.png", strings.NewReader("data"), "image/png"))
+	content, err := os.ReadFile(filepath.Join(dir, "avatars", "a.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(content))
+	assert.Equal(t, "https://cdn.example.com/files/avatars/a.png", store.URL("avatars/a.png"))
+
+	require.NoError(t, store.Delete("avatars/a.png"))
+	_, err = os.Stat(filepath.Join(dir, "avatars", "a.png"))
+	assert.True(t, os.IsNotExist(err))
+	// Deleting again is fine
+	assert.NoError(t, store.Delete("avatars/a.png"))
+}
+
+func TestLocalDefaultURL(t *testing.T) {
+	store, err := storage.NewStorage(setting.StorageSetting{LocalDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.Equal(t, storage.LocalRoute+"/avatars/a.png", store.URL("avatars/a.png"))
+}
+
+func TestLocalRejectsInvalidKeys(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir(), "")
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../a.png", "avatars/../../a.png", "/etc/passwd", "avatars//a.png", ".env"} {
+		assert.ErrorIs(t, store.Put(key, strings.NewReader("data"), "image/png"), storage.ErrInvalidKey, key)
+		assert.ErrorIs(t, store.Delete(key), storage.ErrInvalidKey, key)
+	}
+}
+
+func TestUnknownDriver(t *testing.T) {
+	_, err := storage.NewStorage(setting.StorageSetting{Driver: "ftp"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package totp
+
+import (
+	"base_go_be/pkg/totp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Base32 of the RFC 6238 SHA1 test secret "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateCodeRFC6238(t *testing.T) {
+	// Last 6 digits of the RFC 6238 appendix B vectors
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		code, err := totp.GenerateCode(rfcSecret, time.Unix(unix, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, want, code, "time %d", unix)
+	}
+}
+
+func TestValidateAcceptsSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	previous, _ := totp.GenerateCode(rfcSecret, now.Add(-totp.Period*time.Second))
+
+	step, ok := totp.Validate(rfcSecret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Step(now)-1, step)
+
+	old, _ := totp.GenerateCode(rfcSecret, now.Add(-3*totp.Period*time.Second))
+	_, ok = totp.Validate(rfcSecret, old, now)
+	assert.False(t, ok)
+}
+
+func TestValidateRejectsMalformed(t *testing.T) {
+	now := time.Now()
+	_, ok := totp.Validate(rfcSecret, "12345", now)
+	assert.False(t, ok)
+	_, ok = totp.Validate("not base32!", "123456", now)
+	assert.False(t, ok)
+}